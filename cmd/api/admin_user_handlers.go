@@ -0,0 +1,398 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/google/uuid"
+)
+
+const maxSuspensionReasonLength = 500
+
+type UserRolePayload struct {
+	Role string `json:"role"`
+}
+
+type SuspendUserPayload struct {
+	Reason string `json:"reason"`
+	// Until ends the suspension. Without it, the user is banned until the ban is lifted.
+	Until *time.Time `json:"until"`
+}
+
+// GetAdminUsers godoc
+//
+//	@Summary		List users
+//	@Description	Lists a page of all users, active or not, newest first. Requires the users:manage permission.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			limit	query		int		false	"Users per page (1-100)"	default(20)
+//	@Param			offset	query		int		false	"Users to skip"	default(0)
+//	@Param			role	query		string	false	"Name of the role"
+//	@Param			active	query		bool	false	"Only active or only inactive users"
+//	@Param			since	query		string	false	"Only users created at or after this RFC 3339 timestamp"
+//	@Param			until	query		string	false	"Only users created before this RFC 3339 timestamp"
+//	@Success		200		{object}	[]UserWithSuspension
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/users [get]
+func (app *application) getAdminUsersHandler(w http.ResponseWriter, r *http.Request) {
+	uq, err := parseUserQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	users, err := app.store.Users.GetAllUsers(r.Context(), uq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	resp := make([]UserWithSuspension, 0, len(users))
+	for _, user := range users {
+		resp = append(resp, withSuspension(user))
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, resp); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// SetUserRole godoc
+//
+//	@Summary		Change the role of a user
+//	@Description	Gives the user another role, e.g. to promote them to admin. Admins cannot change their own role,
+//	@Description	the role of users whose role outranks theirs or grant a role with rights they do not have themselves.
+//	@Description	Requires the users:manage permission.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		string			true	"User ID"
+//	@Param			payload	body		UserRolePayload	true	"Role"
+//	@Success		200		{object}	UserWithSuspension
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/users/{userID}/role [put]
+func (app *application) setUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	user := getTargetUserFromCtx(r)
+	ctx := r.Context()
+
+	var payload UserRolePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if user.ID == getUserFromCtx(r).ID {
+		app.badRequestResponse(w, r, errors.New("you cannot change your own role"))
+		return
+	}
+
+	role, err := app.store.Roles.GetByName(ctx, payload.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.badRequestResponse(w, r, fmt.Errorf("unknown role %q", payload.Role))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	allowed, err := app.mayGrantRole(ctx, getUserFromCtx(r), role)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !allowed {
+		app.forbiddenResponse(w, r, fmt.Errorf("you cannot grant the role %q, it has rights that you do not have", role.Name))
+		return
+	}
+
+	if err := app.store.Users.SetRole(ctx, user.ID, role.ID); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.writeAdminUser(w, r, user.ID)
+}
+
+// ActivateUserAccount godoc
+//
+//	@Summary		Activate an account
+//	@Description	Activates the account without an invitation token. Requires the users:manage permission.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			userID	path		string	true	"User ID"
+//	@Success		200		{object}	UserWithSuspension
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/users/{userID}/activate [post]
+func (app *application) activateUserAccountHandler(w http.ResponseWriter, r *http.Request) {
+	app.setUserActive(w, r, true)
+}
+
+// DeactivateUserAccount godoc
+//
+//	@Summary		Deactivate an account
+//	@Description	Deactivates the account and revokes its refresh tokens, so the user cannot log in anymore and their sessions end
+//	@Description	when their access tokens expire. Admins cannot deactivate their own account. Requires the users:manage permission.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			userID	path		string	true	"User ID"
+//	@Success		200		{object}	UserWithSuspension
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/users/{userID}/deactivate [post]
+func (app *application) deactivateUserAccountHandler(w http.ResponseWriter, r *http.Request) {
+	app.setUserActive(w, r, false)
+}
+
+func (app *application) setUserActive(w http.ResponseWriter, r *http.Request, active bool) {
+	user := getTargetUserFromCtx(r)
+
+	if !active && user.ID == getUserFromCtx(r).ID {
+		app.badRequestResponse(w, r, errors.New("you cannot deactivate your own account"))
+		return
+	}
+
+	if err := app.store.Users.SetActive(r.Context(), user.ID, active); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.writeAdminUser(w, r, user.ID)
+}
+
+// ResendInvitation godoc
+//
+//	@Summary		Resend the invitation of a user
+//	@Description	Replaces the pending invitations of an account that is not active and emails a new activation link.
+//	@Description	Requires the users:manage permission.
+//	@Tags			Admin
+//	@Param			userID	path	string	true	"User ID"
+//	@Success		204
+//	@Failure		400	{object}	error	"Bad Request"
+//	@Failure		403	{object}	error	"Forbidden"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		409	{object}	error	"Conflict"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/users/{userID}/invitation [post]
+func (app *application) resendInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	user := getTargetUserFromCtx(r)
+
+	if user.IsActive {
+		app.conflictResponse(w, r, fmt.Errorf("user %s is already active", user.ID))
+		return
+	}
+
+	token := uuid.New().String()
+	if err := app.store.Users.Reinvite(r.Context(), user.ID, hashToken(token), app.config.mail.exp); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.sendInvitation(user, token); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SuspendUser godoc
+//
+//	@Summary		Suspend or ban a user
+//	@Description	Suspends the user until the given time, or bans them without it. Suspended users cannot log in or use their tokens,
+//	@Description	and their refresh tokens are revoked. Their content stays. A suspension replaces the current one of the user.
+//	@Description	Admins cannot suspend themselves. Requires the users:manage permission.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		string				true	"User ID"
+//	@Param			payload	body		SuspendUserPayload	true	"Suspension"
+//	@Success		201		{object}	store.Suspension
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/users/{userID}/suspension [post]
+func (app *application) suspendUserHandler(w http.ResponseWriter, r *http.Request) {
+	user := getTargetUserFromCtx(r)
+	admin := getUserFromCtx(r)
+
+	var payload SuspendUserPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	payload.Reason = strings.TrimSpace(payload.Reason)
+	switch {
+	case user.ID == admin.ID:
+		app.badRequestResponse(w, r, errors.New("you cannot suspend yourself"))
+		return
+	case payload.Reason == "":
+		app.badRequestResponse(w, r, errors.New("a reason is required"))
+		return
+	case len(payload.Reason) > maxSuspensionReasonLength:
+		app.badRequestResponse(w, r, fmt.Errorf("the reason must not be longer than %d characters", maxSuspensionReasonLength))
+		return
+	case payload.Until != nil && !payload.Until.After(time.Now()):
+		app.badRequestResponse(w, r, errors.New("until must be in the future"))
+		return
+	}
+
+	suspension := &store.Suspension{
+		UserID:      user.ID,
+		SuspendedBy: &admin.ID,
+		Reason:      payload.Reason,
+		Until:       payload.Until,
+	}
+	if err := app.store.Suspensions.Suspend(r.Context(), suspension); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, suspension); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// LiftSuspension godoc
+//
+//	@Summary		Lift the suspension of a user
+//	@Description	Ends the suspension or ban of the user right away. Requires the users:manage permission.
+//	@Tags			Admin
+//	@Param			userID	path	string	true	"User ID"
+//	@Success		204
+//	@Failure		400	{object}	error	"Bad Request"
+//	@Failure		403	{object}	error	"Forbidden"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/users/{userID}/suspension [delete]
+func (app *application) liftSuspensionHandler(w http.ResponseWriter, r *http.Request) {
+	user := getTargetUserFromCtx(r)
+
+	if err := app.store.Suspensions.Lift(r.Context(), user.ID, getUserFromCtx(r).ID); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, fmt.Errorf("user %s is not suspended", user.ID))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSuspensions godoc
+//
+//	@Summary		List the suspensions of a user
+//	@Description	Lists all suspensions and bans of the user with who imposed and lifted them, most recent first.
+//	@Description	Requires the users:manage permission.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			userID	path		string	true	"User ID"
+//	@Success		200		{object}	[]store.Suspension
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/users/{userID}/suspensions [get]
+func (app *application) getSuspensionsHandler(w http.ResponseWriter, r *http.Request) {
+	suspensions, err := app.store.Suspensions.GetByUser(r.Context(), getTargetUserFromCtx(r).ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, suspensions); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// ResetTwoFactor godoc
+//
+//	@Summary		Reset the two-factor authentication of a user
+//	@Description	Disables two-factor authentication for a user who lost their authenticator app and recovery codes.
+//	@Description	If their role requires it, they have to enable it again before they can use the API. Admins cannot reset their own.
+//	@Description	Requires the users:manage permission.
+//	@Tags			Admin
+//	@Param			userID	path	string	true	"User ID"
+//	@Success		204
+//	@Failure		400	{object}	error	"Bad Request"
+//	@Failure		403	{object}	error	"Forbidden"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/users/{userID}/2fa [delete]
+func (app *application) resetTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user := getTargetUserFromCtx(r)
+
+	if user.ID == getUserFromCtx(r).ID {
+		app.badRequestResponse(w, r, errors.New("you cannot reset your own two-factor authentication"))
+		return
+	}
+
+	if err := app.store.TwoFactor.Disable(r.Context(), user.ID); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, fmt.Errorf("user %s has not enabled two-factor authentication", user.ID))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeAdminUser responds with the user as it is stored now.
+func (app *application) writeAdminUser(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	user, err := app.store.Users.GetUserByID(r.Context(), id)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(user.Version))
+	if err := app.jsonResponse(w, http.StatusOK, withSuspension(user)); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/mailer"
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestAdminUserHandlers(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	user := &store.User{ID: uuid.New(), Username: "user", Role: store.Role{ID: 1, Name: "user", Level: 1}, IsActive: true}
+	pending := &store.User{ID: uuid.New(), Username: "pending", Email: "pending@example.com", Role: store.Role{ID: 1, Name: "user", Level: 1}}
+	admin := &store.User{ID: uuid.New(), Username: "admin", Role: store.Role{ID: 3, Name: "admin", Level: 3}, IsActive: true}
+	app.store.Users = &store.MockUserStore{Users: []*store.User{user, pending, admin}}
+
+	token := func(user *store.User) string {
+		t.Helper()
+
+		token, err := app.authenticator.GenerateToken(jwt.MapClaims{
+			"sub": user.ID.String(),
+			"jti": uuid.NewString(),
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		principal      *store.User
+		target         string
+		body           string
+		expectedStatus int
+		check          func(t *testing.T, body []byte)
+	}{
+		{name: "list as a user", method: http.MethodGet, principal: user, target: "/admin/users", expectedStatus: http.StatusForbidden},
+		{
+			name: "list inactive users", method: http.MethodGet, principal: admin, target: "/admin/users?active=false", expectedStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp struct {
+					Data []store.User `json:"data"`
+				}
+				if err := json.Unmarshal(body, &resp); err != nil {
+					t.Fatal(err)
+				}
+				if len(resp.Data) != 1 || resp.Data[0].ID != pending.ID {
+					t.Errorf("expected only the pending user, got %+v", resp.Data)
+				}
+			},
+		},
+		{name: "list with an invalid filter", method: http.MethodGet, principal: admin, target: "/admin/users?active=maybe", expectedStatus: http.StatusBadRequest},
+		{name: "promote as a user", method: http.MethodPut, principal: user, target: "/admin/users/" + user.ID.String() + "/role", body: `{"role":"admin"}`, expectedStatus: http.StatusForbidden},
+		{
+			name: "promote", method: http.MethodPut, principal: admin, target: "/admin/users/" + user.ID.String() + "/role", body: `{"role":"admin"}`, expectedStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp struct {
+					Data store.User `json:"data"`
+				}
+				if err := json.Unmarshal(body, &resp); err != nil {
+					t.Fatal(err)
+				}
+				if resp.Data.Role.ID != 3 {
+					t.Errorf("expected role 3, got %+v", resp.Data.Role)
+				}
+			},
+		},
+		{name: "unknown role", method: http.MethodPut, principal: admin, target: "/admin/users/" + user.ID.String() + "/role", body: `{"role":"root"}`, expectedStatus: http.StatusBadRequest},
+		{name: "change own role", method: http.MethodPut, principal: admin, target: "/admin/users/" + admin.ID.String() + "/role", body: `{"role":"user"}`, expectedStatus: http.StatusBadRequest},
+		{name: "deactivate own account", method: http.MethodPost, principal: admin, target: "/admin/users/" + admin.ID.String() + "/deactivate", expectedStatus: http.StatusBadRequest},
+		{name: "resend the invitation", method: http.MethodPost, principal: admin, target: "/admin/users/" + pending.ID.String() + "/invitation", expectedStatus: http.StatusNoContent},
+		{name: "activate", method: http.MethodPost, principal: admin, target: "/admin/users/" + pending.ID.String() + "/activate", expectedStatus: http.StatusOK},
+		{name: "resend the invitation of an active user", method: http.MethodPost, principal: admin, target: "/admin/users/" + pending.ID.String() + "/invitation", expectedStatus: http.StatusConflict},
+		{
+			name: "deactivate", method: http.MethodPost, principal: admin, target: "/admin/users/" + pending.ID.String() + "/deactivate", expectedStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp struct {
+					Data store.User `json:"data"`
+				}
+				if err := json.Unmarshal(body, &resp); err != nil {
+					t.Fatal(err)
+				}
+				if resp.Data.IsActive {
+					t.Error("expected an inactive user")
+				}
+			},
+		},
+		// role and activation are only changed under /admin/users, never with the profile
+		{name: "demote with the profile", method: http.MethodPatch, principal: admin, target: "/users/" + user.ID.String(), body: `{"role":"user"}`, expectedStatus: http.StatusBadRequest},
+		{name: "activate with the profile", method: http.MethodPatch, principal: admin, target: "/users/" + pending.ID.String(), body: `{"is_active":true}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token(tt.principal))
+			req.Header.Set("If-Match", "*")
+
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+			if tt.check != nil {
+				tt.check(t, rr.Body.Bytes())
+			}
+		})
+	}
+
+	if mails := len(app.mailer.(*mailer.MemoryMailer).Messages()); mails != 1 {
+		t.Errorf("expected 1 invitation, got %d", mails)
+	}
+	if user.Role.ID != 3 || pending.IsActive {
+		t.Errorf("expected the profile updates to leave the role and activation, got role %d and active %v", user.Role.ID, pending.IsActive)
+	}
+}
+
+func TestSuspensions(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	user := &store.User{ID: uuid.New(), Username: "user", Role: store.Role{ID: 1, Name: "user", Level: 1}, IsActive: true}
+	if err := user.Password.Set("secret"); err != nil {
+		t.Fatal(err)
+	}
+	admin := &store.User{ID: uuid.New(), Username: "admin", Role: store.Role{ID: 3, Name: "admin", Level: 3}, IsActive: true}
+	app.store.Users = &store.MockUserStore{Users: []*store.User{user, admin}}
+	suspensions := &store.MockSuspensionStore{Users: []*store.User{user, admin}}
+	app.store.Suspensions = suspensions
+
+	token := func(user *store.User) string {
+		t.Helper()
+
+		token, err := app.authenticator.GenerateToken(jwt.MapClaims{
+			"sub": user.ID.String(),
+			"jti": uuid.NewString(),
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	until := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	suspension := "/admin/users/" + user.ID.String() + "/suspension"
+
+	tests := []struct {
+		name           string
+		method         string
+		principal      *store.User
+		target         string
+		body           string
+		expectedStatus int
+	}{
+		{name: "suspend as a user", method: http.MethodPost, principal: user, target: "/admin/users/" + admin.ID.String() + "/suspension", body: `{"reason":"spam"}`, expectedStatus: http.StatusForbidden},
+		{name: "suspend without a reason", method: http.MethodPost, principal: admin, target: suspension, body: `{"reason":" "}`, expectedStatus: http.StatusBadRequest},
+		{name: "suspend until the past", method: http.MethodPost, principal: admin, target: suspension, body: `{"reason":"spam","until":"` + past + `"}`, expectedStatus: http.StatusBadRequest},
+		{name: "suspend yourself", method: http.MethodPost, principal: admin, target: "/admin/users/" + admin.ID.String() + "/suspension", body: `{"reason":"spam"}`, expectedStatus: http.StatusBadRequest},
+		{name: "lift a suspension that does not exist", method: http.MethodDelete, principal: admin, target: suspension, expectedStatus: http.StatusNotFound},
+		{name: "suspend", method: http.MethodPost, principal: admin, target: suspension, body: `{"reason":"spam","until":"` + until + `"}`, expectedStatus: http.StatusCreated},
+		{name: "suspended user is rejected", method: http.MethodGet, principal: user, target: "/users", expectedStatus: http.StatusForbidden},
+		{name: "suspended user cannot log in", method: http.MethodPost, target: "/authentication/token", body: `{"username":"user","password":"secret"}`, expectedStatus: http.StatusForbidden},
+		{name: "lift", method: http.MethodDelete, principal: admin, target: suspension, expectedStatus: http.StatusNoContent},
+		{name: "user is accepted again", method: http.MethodGet, principal: user, target: "/users", expectedStatus: http.StatusOK},
+		{name: "ban", method: http.MethodPost, principal: admin, target: suspension, body: `{"reason":"abuse"}`, expectedStatus: http.StatusCreated},
+		{name: "banned user is rejected", method: http.MethodGet, principal: user, target: "/users", expectedStatus: http.StatusForbidden},
+		{name: "history", method: http.MethodGet, principal: admin, target: "/admin/users/" + user.ID.String() + "/suspensions", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.principal != nil {
+				req.Header.Set("Authorization", "Bearer "+token(tt.principal))
+			}
+
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+		})
+	}
+
+	history, err := suspensions.GetByUser(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Reason != "abuse" || history[0].Until != nil || history[1].LiftedBy == nil || *history[1].LiftedBy != admin.ID {
+		t.Errorf("expected a ban after a lifted suspension, got %+v", history)
+	}
+	if *history[0].SuspendedBy != admin.ID {
+		t.Errorf("expected the ban by %s, got %s", admin.ID, history[0].SuspendedBy)
+	}
+}
+
+func TestCreateHandlers_Suspended(t *testing.T) {
+	app := newTestApplication(t)
+
+	reason := "spam"
+	user := &store.User{ID: uuid.New(), SuspensionReason: &reason}
+
+	tests := []struct {
+		name    string
+		body    string
+		handler http.HandlerFunc
+	}{
+		{name: "post", body: `{"title":"Hello","text":"World","tags":["go"]}`, handler: app.CreatePostsHandler},
+		{name: "comment", body: `{"content":"Hello"}`, handler: app.CreateCommentsHandler},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("postID", "1")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, userCTx, user)
+
+			rr := executeRequest(req.WithContext(ctx), tt.handler)
+
+			checkResponseCode(t, http.StatusForbidden, rr.Code)
+			if !strings.Contains(rr.Body.String(), "banned: spam") {
+				t.Errorf("expected the reason of the ban, got %s", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminUserHandlers_Rank(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	app.store.Roles = &store.MockRoleStore{Roles: []*store.Role{
+		{ID: 1, Name: "user", Level: 1},
+		{ID: 2, Name: "moderator", Level: 2},
+		{ID: 3, Name: "admin", Level: 3},
+		{ID: 4, Name: "manager", Level: 2, Permissions: []string{store.PermUsersManage, store.PermCommentsModerate}},
+		{ID: 5, Name: "role-manager", Level: 1, Permissions: []string{store.PermRolesManage}},
+	}}
+
+	user := &store.User{ID: uuid.New(), Username: "user", Role: store.Role{ID: 1, Name: "user", Level: 1}, IsActive: true}
+	manager := &store.User{ID: uuid.New(), Username: "manager", Role: store.Role{ID: 4, Name: "manager", Level: 2}, IsActive: true}
+	admin := &store.User{ID: uuid.New(), Username: "admin", Role: store.Role{ID: 3, Name: "admin", Level: 3}, IsActive: true}
+	app.store.Users = &store.MockUserStore{Users: []*store.User{user, manager, admin}}
+	app.store.Suspensions = &store.MockSuspensionStore{Users: []*store.User{user, manager, admin}}
+
+	token, err := app.authenticator.GenerateToken(jwt.MapClaims{
+		"sub": manager.ID.String(),
+		"jti": uuid.NewString(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		expectedStatus int
+	}{
+		{name: "grant a higher role", method: http.MethodPut, target: "/admin/users/" + user.ID.String() + "/role", body: `{"role":"admin"}`, expectedStatus: http.StatusForbidden},
+		{name: "grant a role with a permission the manager lacks", method: http.MethodPut, target: "/admin/users/" + user.ID.String() + "/role", body: `{"role":"role-manager"}`, expectedStatus: http.StatusForbidden},
+		{name: "demote a higher user", method: http.MethodPut, target: "/admin/users/" + admin.ID.String() + "/role", body: `{"role":"user"}`, expectedStatus: http.StatusForbidden},
+		{name: "deactivate a higher user", method: http.MethodPost, target: "/admin/users/" + admin.ID.String() + "/deactivate", expectedStatus: http.StatusForbidden},
+		{name: "suspend a higher user", method: http.MethodPost, target: "/admin/users/" + admin.ID.String() + "/suspension", body: `{"reason":"spam"}`, expectedStatus: http.StatusForbidden},
+		{name: "reset the two-factor authentication of a higher user", method: http.MethodDelete, target: "/admin/users/" + admin.ID.String() + "/2fa", expectedStatus: http.StatusForbidden},
+		{name: "grant a role within the manager's rights", method: http.MethodPut, target: "/admin/users/" + user.ID.String() + "/role", body: `{"role":"moderator"}`, expectedStatus: http.StatusOK},
+		{name: "suspend a lower user", method: http.MethodPost, target: "/admin/users/" + user.ID.String() + "/suspension", body: `{"reason":"spam"}`, expectedStatus: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+		})
+	}
+
+	if admin.Role.ID != 3 || !admin.IsActive {
+		t.Errorf("expected the admin to be left alone, got role %d and active %v", admin.Role.ID, admin.IsActive)
+	}
+}
@@ -7,7 +7,11 @@ import (
 
 	"github.com/ITine-Tech/blog/docs"
 	"github.com/ITine-Tech/blog/internal/auth"
+	"github.com/ITine-Tech/blog/internal/mailer"
+	"github.com/ITine-Tech/blog/internal/media"
+	"github.com/ITine-Tech/blog/internal/spam"
 	store2 "github.com/ITine-Tech/blog/internal/store"
+	"github.com/ITine-Tech/blog/internal/totp"
 	httpSwagger "github.com/swaggo/http-swagger"
 
 	"github.com/go-chi/chi/middleware"
@@ -18,19 +22,30 @@ type application struct {
 	config        config
 	store         store2.Storage
 	authenticator auth.Authenticator
+	mailer        mailer.Mailer
+	spam          *spam.Filter
+	blobs         media.BlobStore
+	totp          *totp.Cipher
 }
 
 type config struct {
-	addr   string
-	db     dbConfig
-	apiURL string
-	mail   mailConfig
-	auth   authConfig
+	addr        string
+	db          dbConfig
+	apiURL      string
+	frontendURL string
+	mail        mailConfig
+	auth        authConfig
+	scheduler   schedulerConfig
+	moderation  moderationConfig
+	syndication syndicationConfig
+	media       mediaConfig
+	reactions   reactionsConfig
 }
 
 type authConfig struct {
-	basic basicConfig
-	token tokenConfig
+	basic     basicConfig
+	token     tokenConfig
+	twoFactor twoFactorConfig
 }
 
 type basicConfig struct {
@@ -39,10 +54,50 @@ type basicConfig struct {
 }
 
 type tokenConfig struct {
-	secret   string
-	expiry   time.Duration
-	issuer   string
-	audience string
+	secret        string
+	expiry        time.Duration
+	refreshExpiry time.Duration
+	issuer        string
+	audience      string
+}
+
+type twoFactorConfig struct {
+	// issuer names the blog in authenticator apps.
+	issuer string
+	// challengeExpiry is how long users have to enter their code after their password.
+	challengeExpiry time.Duration
+}
+
+type schedulerConfig struct {
+	interval time.Duration
+}
+
+type moderationConfig struct {
+	// policy is the comment moderation policy of posts that do not set their own.
+	policy string
+}
+
+type syndicationConfig struct {
+	// title names the blog in its RSS, Atom and JSON feeds.
+	title string
+	// limit is the number of posts in a feed.
+	limit int
+}
+
+type mediaConfig struct {
+	// dir is where uploaded files are kept.
+	dir string
+	// maxSize is the size of the largest file that can be uploaded, in bytes.
+	maxSize int64
+	// quota is the number of bytes each user can upload, thumbnails included. 0 means no limit.
+	quota int64
+	// thumbnailSize is the width and height in pixels thumbnails fit into.
+	thumbnailSize int
+}
+
+type reactionsConfig struct {
+	// kinds are the reactions users can react to posts with.
+	kinds []string
 }
 
 type dbConfig struct {
@@ -53,7 +108,19 @@ type dbConfig struct {
 }
 
 type mailConfig struct {
-	exp time.Duration
+	exp              time.Duration
+	passwordResetExp time.Duration
+	fromEmail        string
+	backend          string
+	dir              string
+	smtp             smtpConfig
+}
+
+type smtpConfig struct {
+	host     string
+	port     int
+	username string
+	password string
 }
 
 // mount sets up the HTTP router and middleware for the application.
@@ -78,8 +145,55 @@ func (app *application) mount() http.Handler {
 	r.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("http://localhost:3000/swagger/doc.json")))
 
-	r.Get("/feed", app.getAllPostsHandler)
-	r.Get("/feed/{postID}", app.getPostByIDHandler)
+	r.Group(func(r chi.Router) {
+		r.Use(app.optionalAuthTokenMiddleware)
+		r.Get("/feed", app.getAllPostsHandler)
+		r.Get("/feed/{postID}", app.getPostByIDHandler)
+		r.Get("/feed/by-slug/{slug}", app.getPostBySlugHandler)
+	})
+	r.With(app.AuthTokenMiddleware).Get("/timeline", app.getTimelineHandler)
+	r.Get("/search", app.searchHandler)
+
+	r.Get("/feed.rss", app.siteFeedHandler)
+	r.Get("/feed.atom", app.siteFeedHandler)
+	r.Get("/feed.json", app.siteFeedHandler)
+
+	r.Route("/authors/{username}", func(r chi.Router) {
+		r.Get("/feed.rss", app.authorFeedHandler)
+		r.Get("/feed.atom", app.authorFeedHandler)
+		r.Get("/feed.json", app.authorFeedHandler)
+	})
+
+	r.Route("/tags", func(r chi.Router) {
+		r.Get("/", app.getTagsHandler)
+		r.Route("/{slug}", func(r chi.Router) {
+			r.Use(app.tagContextMiddleware)
+			r.With(app.optionalAuthTokenMiddleware).Get("/posts", app.getTagPostsHandler)
+			r.Get("/feed.rss", app.tagFeedHandler)
+			r.Get("/feed.atom", app.tagFeedHandler)
+			r.Get("/feed.json", app.tagFeedHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(app.AuthTokenMiddleware)
+				r.Post("/follow", app.followTagHandler)
+				r.Delete("/follow", app.unfollowTagHandler)
+			})
+			r.Group(func(r chi.Router) {
+				r.Use(app.AuthTokenMiddleware)
+				r.Use(app.RequirePermission(store2.PermTagsManage))
+				r.Patch("/", app.renameTagHandler)
+				r.Post("/merge", app.mergeTagHandler)
+			})
+		})
+	})
+
+	r.Route("/media", func(r chi.Router) {
+		r.With(app.AuthTokenMiddleware).Post("/", app.uploadMediaHandler)
+		r.Route("/{mediaID}", func(r chi.Router) {
+			r.Use(app.mediaContextMiddleware)
+			r.Get("/", app.getMediaHandler)
+			r.Get("/thumbnail", app.getMediaThumbnailHandler)
+		})
+	})
 
 	r.Route("/posts", func(r chi.Router) {
 		r.Use(app.AuthTokenMiddleware)
@@ -87,26 +201,115 @@ func (app *application) mount() http.Handler {
 		r.Post("/comments/{postID}", app.CreateCommentsHandler)
 		r.Route("/{postID}", func(r chi.Router) {
 			r.Use(app.PostsContextMiddleware)
-			r.Patch("/", app.checkPostOwnership("admin", app.updatePostHandler))
-			r.Delete("/", app.checkPostOwnership("admin", app.DeletePostHandler))
+			r.Patch("/", app.checkPostOwnership(store2.PermPostsUpdateAny, app.updatePostHandler))
+			r.Delete("/", app.checkPostOwnership(store2.PermPostsDeleteAny, app.DeletePostHandler))
+
+			r.Put("/bookmark", app.bookmarkPostHandler)
+			r.Delete("/bookmark", app.removeBookmarkHandler)
+
+			r.Route("/reactions/{kind}", func(r chi.Router) {
+				r.Put("/", app.addReactionHandler)
+				r.Delete("/", app.removeReactionHandler)
+			})
+
+			r.Route("/comments/{commentID}", func(r chi.Router) {
+				r.Use(app.commentsContextMiddleware)
+				r.Patch("/", app.checkCommentOwnership(store2.PermCommentsUpdateAny, app.updateCommentHandler))
+				r.Delete("/", app.checkCommentOwnership(store2.PermCommentsDeleteAny, app.deleteCommentHandler))
+			})
+
+			r.Route("/revisions", func(r chi.Router) {
+				r.Use(app.postVisibilityMiddleware)
+				r.Get("/", app.checkPostOwnership(store2.PermPostsUpdateAny, app.getPostRevisionsHandler))
+				r.Get("/diff", app.checkPostOwnership(store2.PermPostsUpdateAny, app.diffPostRevisionsHandler))
+				r.Get("/{version}", app.checkPostOwnership(store2.PermPostsUpdateAny, app.getPostRevisionHandler))
+				r.Post("/{version}/restore", app.checkPostOwnership(store2.PermPostsUpdateAny, app.restorePostRevisionHandler))
+			})
 		})
 	})
 
 	r.Route("/authentication", func(r chi.Router) {
 		r.Post("/user", app.registerUserHandler)
 		r.Post("/token", app.createTokenHandler)
+		r.Post("/2fa", app.verifyTwoFactorHandler)
+		r.Post("/refresh", app.refreshTokenHandler)
+		r.With(app.AuthTokenMiddleware).Post("/logout", app.logoutHandler)
+		r.Post("/password/forgot", app.forgotPasswordHandler)
+		r.Post("/password/reset", app.resetPasswordHandler)
 	})
 
 	r.Put("/users/activate/{token}", app.activateUserHandler)
 
+	r.Route("/me", func(r chi.Router) {
+		r.With(app.AuthTokenMiddleware).Get("/bookmarks", app.getBookmarksHandler)
+		r.Route("/2fa", func(r chi.Router) {
+			r.Use(app.enrolmentAuthTokenMiddleware)
+			r.Post("/", app.startTwoFactorHandler)
+			r.Post("/confirm", app.confirmTwoFactorHandler)
+			r.Delete("/", app.disableTwoFactorHandler)
+			r.Post("/recovery-codes", app.regenerateRecoveryCodesHandler)
+		})
+	})
+
 	r.Route("/users", func(r chi.Router) {
 		r.Use(app.AuthTokenMiddleware)
 		r.Get("/", app.getUsersHandler)
 		r.Route("/{userID}", func(r chi.Router) {
 			r.Use(app.userContextMiddleware)
 			r.Get("/", app.getUserByIDHandler)
-			r.Patch("/", app.updateUserHandler)
-			r.Delete("/", app.deleteUserHandler)
+			r.Patch("/", app.checkUserOwnership(store2.PermUsersManage, app.updateUserHandler))
+			r.Delete("/", app.checkUserOwnership(store2.PermUsersManage, app.deleteUserHandler))
+			r.Post("/follow", app.followUserHandler)
+			r.Delete("/follow", app.unfollowUserHandler)
+			r.Get("/followers", app.getFollowersHandler)
+			r.Get("/following", app.getFollowingHandler)
+			r.Get("/following/tags", app.getFollowedTagsHandler)
+		})
+	})
+
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(app.AuthTokenMiddleware)
+		r.Route("/comments", func(r chi.Router) {
+			r.Use(app.RequirePermission(store2.PermCommentsModerate))
+			r.Get("/", app.getModerationQueueHandler)
+			r.Post("/approve", app.approveCommentsHandler)
+			r.Post("/reject", app.rejectCommentsHandler)
+		})
+		r.Route("/spam", func(r chi.Router) {
+			r.Use(app.RequirePermission(store2.PermCommentsModerate))
+			r.Get("/", app.getSpamReportsHandler)
+			r.Route("/{reportID}", func(r chi.Router) {
+				r.Use(app.spamReportContextMiddleware)
+				r.Post("/release", app.releaseSpamHandler)
+				r.Post("/dismiss", app.dismissSpamHandler)
+			})
+		})
+		r.Route("/roles", func(r chi.Router) {
+			r.Use(app.RequirePermission(store2.PermRolesManage))
+			r.Get("/", app.getRolesHandler)
+			r.Post("/", app.createRoleHandler)
+			r.Route("/{roleID}", func(r chi.Router) {
+				r.Use(app.roleContextMiddleware)
+				r.Get("/", app.getRoleHandler)
+				r.Patch("/", app.updateRoleHandler)
+				r.Put("/permissions", app.setRolePermissionsHandler)
+			})
+		})
+		r.With(app.RequirePermission(store2.PermRolesManage)).Get("/permissions", app.getPermissionsHandler)
+		r.Route("/users", func(r chi.Router) {
+			r.Use(app.RequirePermission(store2.PermUsersManage))
+			r.Get("/", app.getAdminUsersHandler)
+			r.Route("/{userID}", func(r chi.Router) {
+				r.Use(app.userContextMiddleware)
+				r.Put("/role", app.checkUserRank(app.setUserRoleHandler))
+				r.Post("/activate", app.checkUserRank(app.activateUserAccountHandler))
+				r.Post("/deactivate", app.checkUserRank(app.deactivateUserAccountHandler))
+				r.Post("/invitation", app.checkUserRank(app.resendInvitationHandler))
+				r.Post("/suspension", app.checkUserRank(app.suspendUserHandler))
+				r.Delete("/suspension", app.checkUserRank(app.liftSuspensionHandler))
+				r.Get("/suspensions", app.getSuspensionsHandler)
+				r.Delete("/2fa", app.checkUserRank(app.resetTwoFactorHandler))
+			})
 		})
 	})
 
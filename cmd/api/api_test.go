@@ -17,10 +17,73 @@ func Test_application_routes(t *testing.T) {
 		{name: "Health route", route: "/healthcheck", expectedMethod: "GET"},
 		{name: "Get feed", route: "/feed", expectedMethod: "GET"},
 		{name: "Get post by ID", route: "/feed/{postID}", expectedMethod: "GET"},
+		{name: "Get post by slug", route: "/feed/by-slug/{slug}", expectedMethod: "GET"},
 		{name: "Activates user", route: "/users/activate/{token}", expectedMethod: "PUT"},
 		{name: "Authenticate user", route: "/authentication/user", expectedMethod: "POST"},
 		{name: "Authentication token", route: "/authentication/token", expectedMethod: "POST"},
+		{name: "Two-factor login", route: "/authentication/2fa", expectedMethod: "POST"},
 		{name: "Activation of user accounts", route: "/users/activate/{token}", expectedMethod: "PUT"},
+		{name: "Refresh token", route: "/authentication/refresh", expectedMethod: "POST"},
+		{name: "Logout", route: "/authentication/logout", expectedMethod: "POST"},
+		{name: "Forgot password", route: "/authentication/password/forgot", expectedMethod: "POST"},
+		{name: "Reset password", route: "/authentication/password/reset", expectedMethod: "POST"},
+		{name: "Search", route: "/search", expectedMethod: "GET"},
+		{name: "Post revisions", route: "/posts/{postID}/revisions/", expectedMethod: "GET"},
+		{name: "Post revision", route: "/posts/{postID}/revisions/{version}", expectedMethod: "GET"},
+		{name: "Post revision diff", route: "/posts/{postID}/revisions/diff", expectedMethod: "GET"},
+		{name: "Edit comment", route: "/posts/{postID}/comments/{commentID}/", expectedMethod: "PATCH"},
+		{name: "Delete comment", route: "/posts/{postID}/comments/{commentID}/", expectedMethod: "DELETE"},
+		{name: "Add reaction", route: "/posts/{postID}/reactions/{kind}/", expectedMethod: "PUT"},
+		{name: "Remove reaction", route: "/posts/{postID}/reactions/{kind}/", expectedMethod: "DELETE"},
+		{name: "Moderation queue", route: "/admin/comments/", expectedMethod: "GET"},
+		{name: "Approve comments", route: "/admin/comments/approve", expectedMethod: "POST"},
+		{name: "Reject comments", route: "/admin/comments/reject", expectedMethod: "POST"},
+		{name: "Spam reports", route: "/admin/spam/", expectedMethod: "GET"},
+		{name: "Release spam", route: "/admin/spam/{reportID}/release", expectedMethod: "POST"},
+		{name: "Dismiss spam", route: "/admin/spam/{reportID}/dismiss", expectedMethod: "POST"},
+		{name: "List roles", route: "/admin/roles/", expectedMethod: "GET"},
+		{name: "Create role", route: "/admin/roles/", expectedMethod: "POST"},
+		{name: "Get role", route: "/admin/roles/{roleID}/", expectedMethod: "GET"},
+		{name: "Update role", route: "/admin/roles/{roleID}/", expectedMethod: "PATCH"},
+		{name: "Set role permissions", route: "/admin/roles/{roleID}/permissions", expectedMethod: "PUT"},
+		{name: "List permissions", route: "/admin/permissions", expectedMethod: "GET"},
+		{name: "Admin list users", route: "/admin/users/", expectedMethod: "GET"},
+		{name: "Set user role", route: "/admin/users/{userID}/role", expectedMethod: "PUT"},
+		{name: "Activate user", route: "/admin/users/{userID}/activate", expectedMethod: "POST"},
+		{name: "Deactivate user", route: "/admin/users/{userID}/deactivate", expectedMethod: "POST"},
+		{name: "Resend invitation", route: "/admin/users/{userID}/invitation", expectedMethod: "POST"},
+		{name: "Suspend user", route: "/admin/users/{userID}/suspension", expectedMethod: "POST"},
+		{name: "Lift suspension", route: "/admin/users/{userID}/suspension", expectedMethod: "DELETE"},
+		{name: "List suspensions", route: "/admin/users/{userID}/suspensions", expectedMethod: "GET"},
+		{name: "Reset two-factor authentication", route: "/admin/users/{userID}/2fa", expectedMethod: "DELETE"},
+		{name: "RSS feed", route: "/feed.rss", expectedMethod: "GET"},
+		{name: "Atom feed", route: "/feed.atom", expectedMethod: "GET"},
+		{name: "JSON feed", route: "/feed.json", expectedMethod: "GET"},
+		{name: "Tag feed", route: "/tags/{slug}/feed.atom", expectedMethod: "GET"},
+		{name: "Author feed", route: "/authors/{username}/feed.rss", expectedMethod: "GET"},
+		{name: "Tags", route: "/tags/", expectedMethod: "GET"},
+		{name: "Tag posts", route: "/tags/{slug}/posts", expectedMethod: "GET"},
+		{name: "Rename tag", route: "/tags/{slug}/", expectedMethod: "PATCH"},
+		{name: "Merge tags", route: "/tags/{slug}/merge", expectedMethod: "POST"},
+		{name: "Upload media", route: "/media/", expectedMethod: "POST"},
+		{name: "Media", route: "/media/{mediaID}/", expectedMethod: "GET"},
+		{name: "Media thumbnail", route: "/media/{mediaID}/thumbnail", expectedMethod: "GET"},
+		{name: "Timeline", route: "/timeline", expectedMethod: "GET"},
+		{name: "Follow user", route: "/users/{userID}/follow", expectedMethod: "POST"},
+		{name: "Unfollow user", route: "/users/{userID}/follow", expectedMethod: "DELETE"},
+		{name: "Followers", route: "/users/{userID}/followers", expectedMethod: "GET"},
+		{name: "Following", route: "/users/{userID}/following", expectedMethod: "GET"},
+		{name: "Followed tags", route: "/users/{userID}/following/tags", expectedMethod: "GET"},
+		{name: "Follow tag", route: "/tags/{slug}/follow", expectedMethod: "POST"},
+		{name: "Unfollow tag", route: "/tags/{slug}/follow", expectedMethod: "DELETE"},
+		{name: "Bookmark post", route: "/posts/{postID}/bookmark", expectedMethod: "PUT"},
+		{name: "Remove bookmark", route: "/posts/{postID}/bookmark", expectedMethod: "DELETE"},
+		{name: "Reading list", route: "/me/bookmarks", expectedMethod: "GET"},
+		{name: "Start two-factor enrolment", route: "/me/2fa/", expectedMethod: "POST"},
+		{name: "Confirm two-factor enrolment", route: "/me/2fa/confirm", expectedMethod: "POST"},
+		{name: "Disable two-factor authentication", route: "/me/2fa/", expectedMethod: "DELETE"},
+		{name: "Regenerate recovery codes", route: "/me/2fa/recovery-codes", expectedMethod: "POST"},
+		{name: "Restore post revision", route: "/posts/{postID}/revisions/{version}/restore", expectedMethod: "POST"},
 	}
 
 	var app application
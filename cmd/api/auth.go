@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 
+	"github.com/ITine-Tech/blog/internal/mailer"
+	"github.com/ITine-Tech/blog/internal/spam"
 	"github.com/ITine-Tech/blog/internal/store"
 )
 
@@ -19,32 +24,44 @@ type RegisterUserPayload struct {
 	Password string `json:"password" validate:"required,min=5,max=50"`
 }
 
-type UserWithToken struct {
-	*store.User
-	Token string `json:"token"`
-}
+type authKey string
+
+const claimsCtx authKey = "claims"
 
 type CreateUserTokenPayload struct {
 	Username string `json:"username" validate:"required,max=100"`
 	Password string `json:"password" validate:"required,min=5,max=50"`
 }
 
+type RefreshTokenPayload struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
 // registerUserHandler godoc
 //
 // @Summary	Register a user
-// @Description Register a user
+// @Description Register a user. Registrations that look like spam are rejected, or held without sending the activation email until a moderator releases them.
 // @Tags Authentication
 // @Accept	json
 // @Produce	json
 // @Param	payload body	RegisterUserPayload true "userPayload"
-// @Success 201		{object} UserWithToken	"User registered"
+// @Success 201		{object} store.User	"User registered, activation link sent by email"
+// @Success 202		{object} store.User	"User registered, held for review"
 // @Failure	400		{object} error	"Bad Request"
+// @Failure	422		{object} error	"Rejected as spam"
 // @Failure 500		{object} error	"Internal Server Error"
 // @Router	/authentication/user [post]
 func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Request) {
 	var userPayload RegisterUserPayload
 	if err := readJSON(w, r, &userPayload); err != nil {
 		app.badRequestResponse(w, r, err)
+		return
 	}
 
 	if userPayload.Username == "" || userPayload.Email == "" || userPayload.Password == "" {
@@ -52,6 +69,23 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	ctx := r.Context()
+
+	result := app.spam.Check(spam.Submission{
+		Username: userPayload.Username,
+		Email:    userPayload.Email,
+	})
+	report := store.SpamReport{
+		Kind:    store.SpamKindRegistration,
+		Content: fmt.Sprintf("%s <%s>", userPayload.Username, userPayload.Email),
+	}
+
+	if result.Verdict == spam.VerdictReject {
+		app.reportSpam(ctx, report, result)
+		app.unprocessableEntityResponse(w, r, errSpam)
+		return
+	}
+
 	user := &store.User{
 		Username: userPayload.Username,
 		Email:    userPayload.Email,
@@ -65,14 +99,9 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	ctx := r.Context()
-
 	newToken := uuid.New().String()
 
-	hash := sha256.Sum256([]byte(newToken))
-	hashToken := hex.EncodeToString(hash[:])
-
-	err := app.store.Users.CreateAndInvite(ctx, user, hashToken, app.config.mail.exp)
+	err := app.store.Users.CreateAndInvite(ctx, user, hashToken(newToken), app.config.mail.exp)
 	if err != nil {
 		switch err {
 		case store.ErrDuplicateUsername:
@@ -85,29 +114,61 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	//send e-mail to user with activation link
-	//The struct here is used to send payload so token can be used in Bruno
-	userWithToken := UserWithToken{
-		User:  user,
-		Token: newToken,
+	if result.Verdict == spam.VerdictHold {
+		// the activation email is sent when a moderator releases the registration
+		report.UserID = &user.ID
+		app.reportSpam(ctx, report, result)
+
+		if err := app.jsonResponse(w, http.StatusAccepted, user); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.sendInvitation(user, newToken); err != nil {
+		log.Printf("error sending invitation email: %s", err)
+
+		// rollback user creation if the email could not be sent
+		if err := app.store.Users.DeleteUser(ctx, user.ID); err != nil {
+			log.Printf("error deleting user: %s", err)
+		}
+
+		app.internalServerError(w, r, err)
+		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusCreated, userWithToken); err != nil {
+	if err := app.jsonResponse(w, http.StatusCreated, user); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
 
+// sendInvitation emails the activation link for token to the user.
+func (app *application) sendInvitation(user *store.User, token string) error {
+	vars := struct {
+		Username      string
+		ActivationURL string
+	}{
+		Username:      user.Username,
+		ActivationURL: fmt.Sprintf("%s/confirm/%s", app.config.frontendURL, token),
+	}
+
+	return app.mailer.Send(mailer.UserInvitationTemplate, user.Username, user.Email, vars)
+}
+
 // createTokenHandler godoc
 //
 // @Summary	creates a token
-// @Description creates a token for a user
+// @Description creates a short-lived access token and a refresh token for a user. Suspended and banned users get a 403 with the reason.
+// @Description Users with two-factor authentication get a challenge token instead, to exchange for the tokens with a code at /authentication/2fa.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param payload body	CreateUserTokenPayload true "User credentials"
-// @Success 201 {object} string "Token"
+// @Success 201 {object} TokenPair "Tokens"
+// @Success 202 {object} TwoFactorChallenge "Code required"
 // @Failure 400 {object}	error
 // @Failure 401 {object}	error
+// @Failure 403 {object}	error "Suspended"
 // @Failure 500 {object}	error "Internal Server Error"
 // @Router /authentication/token [post]
 func (app *application) createTokenHandler(w http.ResponseWriter, r *http.Request) {
@@ -138,21 +199,250 @@ func (app *application) createTokenHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	claims := jwt.MapClaims{
-		"sub": user.ID, 
-		"exp": time.Now().Add(app.config.auth.token.expiry).Unix(),
-		"iat": time.Now().Unix(), 
-		"nbf": time.Now().Unix(),
-		"iss": app.config.auth.token.issuer,
-		"aud": app.config.auth.token.audience,
+	if app.refuseSuspended(w, r, user) {
+		return
+	}
+
+	if user.TwoFactorEnabled {
+		app.writeTwoFactorChallenge(w, r, user.ID)
+		return
+	}
+
+	tokens, err := app.issueTokenPair(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, tokens); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// refreshTokenHandler godoc
+//
+// @Summary	refreshes a token
+// @Description Exchanges a refresh token for a new access and refresh token. Every refresh token can only be used once; reusing one revokes all tokens of its session. Deactivated users get a 401, suspended and banned users a 403 with the reason.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param payload body	RefreshTokenPayload true "Refresh token"
+// @Success 201 {object} TokenPair "Tokens"
+// @Failure 400 {object}	error
+// @Failure 401 {object}	error
+// @Failure 403 {object}	error "Suspended"
+// @Failure 500 {object}	error "Internal Server Error"
+// @Router /authentication/refresh [post]
+func (app *application) refreshTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var payload RefreshTokenPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if payload.RefreshToken == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("refresh_token is required"))
+		return
+	}
+
+	ctx := r.Context()
+	currentHash := hashToken(payload.RefreshToken)
+
+	current, err := app.store.Tokens.GetRefreshToken(ctx, currentHash)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.unauthorizedResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	// a revoked token is being reused, so its session ends before the user is looked at,
+	// also for users whose refresh is refused anyway
+	if current.RevokedAt != nil {
+		if err := app.store.Tokens.RevokeRefreshTokenFamily(ctx, currentHash, current.UserID); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		app.unauthorizedResponse(w, r, store.ErrTokenReused)
+		return
+	}
+
+	user, err := app.store.Users.GetUserByID(ctx, current.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.unauthorizedResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
 	}
-	token, err := app.authenticator.GenerateToken(claims)
+
+	if !user.IsActive {
+		app.unauthorizedResponse(w, r, fmt.Errorf("user %s is not active", user.ID))
+		return
+	}
+
+	if app.refuseSuspended(w, r, user) {
+		return
+	}
+
+	refreshToken := uuid.New().String()
+	next := &store.RefreshToken{
+		TokenHash: hashToken(refreshToken),
+		Expiry:    time.Now().Add(app.config.auth.token.refreshExpiry),
+	}
+
+	if err := app.store.Tokens.RotateRefreshToken(ctx, currentHash, next); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound), errors.Is(err, store.ErrTokenReused):
+			app.unauthorizedResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	accessToken, err := app.generateAccessToken(next.UserID)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusCreated, token); err != nil {
+	tokens := TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(app.config.auth.token.expiry.Seconds()),
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, tokens); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// logoutHandler godoc
+//
+// @Summary	logs a user out
+// @Description Revokes the current access token and every refresh token of the session. Logging out of a session that has already ended succeeds.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param payload body	RefreshTokenPayload true "Refresh token"
+// @Success 204 {string}	string "Logged out"
+// @Failure 400 {object}	error
+// @Failure 401 {object}	error
+// @Failure 500 {object}	error "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /authentication/logout [post]
+func (app *application) logoutHandler(w http.ResponseWriter, r *http.Request) {
+	var payload RefreshTokenPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if payload.RefreshToken == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("refresh_token is required"))
+		return
+	}
+
+	ctx := r.Context()
+	user := getUserFromCtx(r)
+	claims := getClaimsFromCtx(r)
+
+	jti, err := jtiFromClaims(claims)
+	if err != nil {
+		app.unauthorizedResponse(w, r, err)
+		return
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		app.unauthorizedResponse(w, r, fmt.Errorf("token has no expiry"))
+		return
+	}
+
+	if err := app.store.Tokens.RevokeAccessToken(ctx, jti, exp.Time); err != nil {
 		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.Tokens.RevokeRefreshTokenFamily(ctx, hashToken(payload.RefreshToken), user.ID); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.unauthorizedResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// issueTokenPair creates a new session for the user: a fresh refresh token family
+// and a short-lived access token.
+func (app *application) issueTokenPair(ctx context.Context, userID uuid.UUID) (*TokenPair, error) {
+	refreshToken := uuid.New().String()
+
+	err := app.store.Tokens.CreateRefreshToken(ctx, &store.RefreshToken{
+		TokenHash: hashToken(refreshToken),
+		UserID:    userID,
+		FamilyID:  uuid.New(),
+		Expiry:    time.Now().Add(app.config.auth.token.refreshExpiry),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := app.generateAccessToken(userID)
+	if err != nil {
+		return nil, err
 	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(app.config.auth.token.expiry.Seconds()),
+	}, nil
+}
+
+func (app *application) generateAccessToken(userID uuid.UUID) (string, error) {
+	now := time.Now()
+
+	claims := jwt.MapClaims{
+		"sub": userID,
+		"jti": uuid.New().String(),
+		"exp": now.Add(app.config.auth.token.expiry).Unix(),
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"iss": app.config.auth.token.issuer,
+		"aud": app.config.auth.token.audience,
+	}
+
+	return app.authenticator.GenerateToken(claims)
+}
+
+func jtiFromClaims(claims jwt.MapClaims) (uuid.UUID, error) {
+	jti, ok := claims["jti"].(string)
+	if !ok {
+		return uuid.Nil, errors.New("token has no jti claim")
+	}
+
+	return uuid.Parse(jti)
+}
+
+// hashToken returns the hex encoded SHA-256 hash under which a token is stored.
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func getClaimsFromCtx(r *http.Request) jwt.MapClaims {
+	claims, _ := r.Context().Value(claimsCtx).(jwt.MapClaims)
+	return claims
 }
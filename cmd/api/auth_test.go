@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/google/uuid"
+)
+
+func TestRefreshTokenHandler(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	reason := "spam"
+	active := &store.User{ID: uuid.New(), Username: "active", IsActive: true}
+	inactive := &store.User{ID: uuid.New(), Username: "inactive"}
+	suspended := &store.User{ID: uuid.New(), Username: "suspended", IsActive: true, SuspensionReason: &reason}
+	app.store.Users = &store.MockUserStore{Users: []*store.User{active, inactive, suspended}}
+
+	tokens := map[string]*store.RefreshToken{}
+	for _, user := range []*store.User{active, inactive, suspended} {
+		tokens[hashToken(user.Username)] = &store.RefreshToken{UserID: user.ID, FamilyID: uuid.New()}
+	}
+
+	// a stolen token of the suspended user that has already been rotated, and the token that replaced it
+	rotated := time.Now().Add(-time.Minute)
+	family := uuid.New()
+	tokens[hashToken("rotated")] = &store.RefreshToken{UserID: suspended.ID, FamilyID: family, RevokedAt: &rotated}
+	tokens[hashToken("successor")] = &store.RefreshToken{UserID: suspended.ID, FamilyID: family}
+	app.store.Tokens = &store.MockTokenStore{RefreshTokens: tokens}
+
+	tests := []struct {
+		name           string
+		refreshToken   string
+		expectedStatus int
+	}{
+		{name: "active user", refreshToken: "active", expectedStatus: http.StatusCreated},
+		{name: "deactivated user", refreshToken: "inactive", expectedStatus: http.StatusUnauthorized},
+		{name: "suspended user", refreshToken: "suspended", expectedStatus: http.StatusForbidden},
+		{name: "unknown token", refreshToken: "unknown", expectedStatus: http.StatusUnauthorized},
+		{name: "reused token of a suspended user", refreshToken: "rotated", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/authentication/refresh", strings.NewReader(`{"refresh_token":"`+tt.refreshToken+`"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+		})
+	}
+
+	if tokens[hashToken("successor")].RevokedAt == nil {
+		t.Error("expected the reuse to revoke the whole family")
+	}
+}
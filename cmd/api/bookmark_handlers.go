@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/ITine-Tech/blog/internal/store"
+)
+
+// maxBookmarkNoteLength is the maximum number of characters of a note on a bookmark.
+const maxBookmarkNoteLength = 1000
+
+type BookmarkPayload struct {
+	// Note is a private note on the bookmark. Leaving it out keeps the note of an existing bookmark.
+	Note *string `json:"note"`
+}
+
+// BookmarkPost godoc
+//
+//	@Summary		Bookmark a post
+//	@Description	Adds the post to the reading list of the authenticated user, with an optional private note.
+//	@Description	Bookmarking a post again updates the note, or keeps it if the body has no note.
+//	@Tags			Posts
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID	path		int				true	"Post ID"	regexp(^[0-9]+$)
+//	@Param			payload	body		BookmarkPayload	false	"Note"
+//	@Success		200		{object}	store.Bookmark
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		401		{object}	error	"Unauthorized"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/bookmark [put]
+func (app *application) bookmarkPostHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	user := getUserFromCtx(r)
+	ctx := r.Context()
+
+	// the body is optional, an empty one bookmarks the post without touching the note
+	var payload BookmarkPayload
+	if err := readJSON(w, r, &payload); err != nil && !errors.Is(err, io.EOF) {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if payload.Note != nil && utf8.RuneCountInString(*payload.Note) > maxBookmarkNoteLength {
+		app.badRequestResponse(w, r, fmt.Errorf("note must not be longer than %d characters", maxBookmarkNoteLength))
+		return
+	}
+
+	visible, err := app.canViewPost(ctx, user, post)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !visible {
+		app.notFoundResponse(w, r, fmt.Errorf("post %d is %s", post.ID, post.Status))
+		return
+	}
+
+	bookmark, err := app.store.Bookmarks.Save(ctx, user.ID, post.ID, payload.Note)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, bookmark); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// RemoveBookmark godoc
+//
+//	@Summary		Remove a bookmark
+//	@Description	Removes the post from the reading list of the authenticated user. Removing a bookmark that does not exist has no effect.
+//	@Tags			Posts
+//	@Param			postID	path	int	true	"Post ID"	regexp(^[0-9]+$)
+//	@Success		204
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/bookmark [delete]
+func (app *application) removeBookmarkHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	user := getUserFromCtx(r)
+
+	if err := app.store.Bookmarks.Remove(r.Context(), user.ID, post.ID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetBookmarks godoc
+//
+//	@Summary		Get the reading list
+//	@Description	Lists the posts the authenticated user bookmarked with their notes, most recently bookmarked first.
+//	@Description	Posts that are no longer published are left out, unless the user wrote them.
+//	@Tags			Users
+//	@Produce		json
+//	@Param			limit	query		int	false	"Bookmarks per page (1-100)"	default(20)
+//	@Param			offset	query		int	false	"Bookmarks to skip"	default(0)
+//	@Success		200		{object}	[]store.Bookmark
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		401		{object}	error	"Unauthorized"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/me/bookmarks [get]
+func (app *application) getBookmarksHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromCtx(r)
+
+	bq, err := parseBookmarkQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	bookmarks, err := app.store.Bookmarks.GetByUser(r.Context(), user.ID, bq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, bookmarks); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// parseBookmarkQuery reads the limit and offset of a page of the reading list.
+func parseBookmarkQuery(r *http.Request) (store.BookmarkQuery, error) {
+	qs := r.URL.Query()
+
+	bq := store.BookmarkQuery{
+		Limit: store.DefaultBookmarkLimit,
+	}
+
+	if limit := qs.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > store.MaxBookmarkLimit {
+			return bq, fmt.Errorf("limit must be a number between 1 and %d", store.MaxBookmarkLimit)
+		}
+		bq.Limit = l
+	}
+
+	if offset := qs.Get("offset"); offset != "" {
+		o, err := strconv.Atoi(offset)
+		if err != nil || o < 0 {
+			return bq, fmt.Errorf("offset must be a positive number")
+		}
+		bq.Offset = o
+	}
+
+	return bq, nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func TestBookmarkHandlers(t *testing.T) {
+	app := newTestApplication(t)
+	bookmarks := app.store.Bookmarks.(*store.MockBookmarkStore)
+
+	published := &store.Post{ID: 1, UserID: uuid.New(), Status: store.PostStatusPublished}
+	draft := &store.Post{ID: 2, UserID: uuid.New(), Status: store.PostStatusDraft}
+	user := &store.User{ID: uuid.New(), Role: store.Role{Name: "user", Level: 1}}
+
+	empty, note := "", "read on the train"
+
+	tests := []struct {
+		name           string
+		method         string
+		post           *store.Post
+		body           string
+		expectedStatus int
+		expectedNote   *string
+	}{
+		{name: "bookmark", method: http.MethodPut, post: published, expectedStatus: http.StatusOK, expectedNote: &empty},
+		{name: "add a note", method: http.MethodPut, post: published, body: `{"note": "read on the train"}`, expectedStatus: http.StatusOK, expectedNote: &note},
+		{name: "bookmark again keeps the note", method: http.MethodPut, post: published, expectedStatus: http.StatusOK, expectedNote: &note},
+		{name: "note too long", method: http.MethodPut, post: published, body: `{"note": "` + strings.Repeat("a", 1001) + `"}`, expectedStatus: http.StatusBadRequest, expectedNote: &note},
+		{name: "unknown field", method: http.MethodPut, post: published, body: `{"comment": "x"}`, expectedStatus: http.StatusBadRequest, expectedNote: &note},
+		{name: "draft of another user", method: http.MethodPut, post: draft, expectedStatus: http.StatusNotFound, expectedNote: &note},
+		{name: "remove", method: http.MethodDelete, post: published, expectedStatus: http.StatusNoContent},
+		{name: "remove again", method: http.MethodDelete, post: published, expectedStatus: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/posts/1/bookmark", strings.NewReader(tt.body))
+			ctx := context.WithValue(req.Context(), userCTx, user)
+			ctx = context.WithValue(ctx, postCtx, tt.post)
+
+			handler := app.bookmarkPostHandler
+			if tt.method == http.MethodDelete {
+				handler = app.removeBookmarkHandler
+			}
+			rr := executeRequest(req.WithContext(ctx), http.HandlerFunc(handler))
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+			if _, ok := bookmarks.Notes[user.ID][draft.ID]; ok {
+				t.Errorf("expected the draft not to be bookmarked")
+			}
+
+			note, ok := bookmarks.Notes[user.ID][published.ID]
+			switch {
+			case tt.expectedNote == nil && ok:
+				t.Errorf("expected no bookmark, got one with note %q", note)
+			case tt.expectedNote != nil && (!ok || note != *tt.expectedNote):
+				t.Errorf("expected a bookmark with note %q, got %q (bookmarked: %v)", *tt.expectedNote, note, ok)
+			}
+		})
+	}
+}
+
+func TestGetBookmarksHandler(t *testing.T) {
+	app := newTestApplication(t)
+
+	user := &store.User{ID: uuid.New()}
+	for id := int64(1); id <= 3; id++ {
+		app.store.Bookmarks.Save(context.Background(), user.ID, id, nil)
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{name: "all", expectedStatus: http.StatusOK, expectedCount: 3},
+		{name: "page", query: "?limit=2&offset=1", expectedStatus: http.StatusOK, expectedCount: 2},
+		{name: "invalid limit", query: "?limit=0", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me/bookmarks"+tt.query, nil)
+			ctx := context.WithValue(req.Context(), userCTx, user)
+
+			rr := executeRequest(req.WithContext(ctx), http.HandlerFunc(app.getBookmarksHandler))
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data []store.Bookmark `json:"data"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Data) != tt.expectedCount {
+				t.Errorf("expected %d bookmarks, got %d", tt.expectedCount, len(resp.Data))
+			}
+			for _, b := range resp.Data {
+				if b.Post == nil || b.Post.Bookmarked == nil || !*b.Post.Bookmarked {
+					t.Errorf("expected bookmarked post %d, got %+v", b.PostID, b.Post)
+				}
+			}
+		})
+	}
+}
+
+func TestGetPostHandler_Bookmarked(t *testing.T) {
+	app := newTestApplication(t)
+	app.store.Posts = &store.MockPostStore{
+		Posts: []*store.Post{{ID: 1, Slug: "hello-world", Status: store.PostStatusPublished}},
+	}
+
+	reader := uuid.New()
+	app.store.Bookmarks.Save(context.Background(), reader, 1, nil)
+
+	tests := []struct {
+		name     string
+		user     *store.User
+		expected string
+	}{
+		{name: "anonymous"},
+		{name: "bookmarked", user: &store.User{ID: reader}, expected: "true"},
+		{name: "not bookmarked", user: &store.User{ID: uuid.New()}, expected: "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/feed/by-slug/hello-world", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("slug", "hello-world")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			if tt.user != nil {
+				ctx = context.WithValue(ctx, userCTx, tt.user)
+			}
+
+			rr := executeRequest(req.WithContext(ctx), http.HandlerFunc(app.getPostBySlugHandler))
+			checkResponseCode(t, http.StatusOK, rr.Code)
+
+			var resp struct {
+				Data map[string]json.RawMessage `json:"data"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if got := string(resp.Data["bookmarked"]); got != tt.expected {
+				t.Errorf("expected bookmarked %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
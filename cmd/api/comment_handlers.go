@@ -1,28 +1,40 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 
+	"github.com/ITine-Tech/blog/internal/spam"
 	"github.com/ITine-Tech/blog/internal/store"
 	"github.com/go-chi/chi/v5"
 )
 
 type CreateComment struct {
 	Content string `json:"content"`
+	// ParentID is the comment this comment replies to. It has to belong to the same post.
+	ParentID *int `json:"parent_id"`
 }
 
 // CreateCommentsHandler godoc
 //
 //	@Summary		Create a comment
-//	@Description	Creates a new comment
+//	@Description	Creates a new comment. Set parent_id to reply to another comment of the same post.
+//	@Description	Depending on the moderation policy, the comment is pending until a moderator approves it.
+//	@Description	Comments that look like spam are held for review or rejected.
 //	@Tags			Comments
 //	@Accept			json
 //	@Produce		json
 //	@Param postID path int true "Post ID" regexp(^[0-9]+$)
 //	@Param			payload body		CreateComment true	"commentsPayload"#
 //	@Success		200		{object}	store.Comment
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Suspended"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		422		{object}	error	"Rejected as spam"
 //	@Failure		500		{object}	error	"Internal Server Error"
 //	@Security		ApiKeyAuth
 //	@Router			/posts/comments/{postID} [post]
@@ -47,17 +59,65 @@ func (app *application) CreateCommentsHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	user := getUserFromCtx(r)
+	if app.refuseSuspended(w, r, user) {
+		return
+	}
+
+	ctx := r.Context()
+
+	status, err := app.commentStatus(ctx, user, postID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
 
 	comment := &store.Comment{
-		Content: commentsPayload.Content,
-		UserID:  user.ID,
-		PostID:  int(postID),
+		Content:  commentsPayload.Content,
+		UserID:   user.ID,
+		PostID:   int(postID),
+		ParentID: commentsPayload.ParentID,
+		Status:   status,
 	}
 
-	ctx := r.Context()
+	result, err := app.checkCommentSpam(ctx, user, comment)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	switch result.Verdict {
+	case spam.VerdictHold:
+		comment.Status = store.CommentStatusPending
+	case spam.VerdictReject:
+		comment.Status = store.CommentStatusRejected
+	}
 
 	if err := app.store.Comments.CreateComment(ctx, comment); err != nil {
-		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		switch {
+		case errors.Is(err, store.ErrInvalidParent):
+			app.badRequestResponse(w, r, err)
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	app.reportSpam(ctx, store.SpamReport{
+		Kind:      store.SpamKindComment,
+		CommentID: &comment.ID,
+		UserID:    &user.ID,
+		Content:   comment.Content,
+	}, result)
+
+	if result.Verdict == spam.VerdictReject {
+		app.unprocessableEntityResponse(w, r, errSpam)
 		return
 	}
 
@@ -65,3 +125,272 @@ func (app *application) CreateCommentsHandler(w http.ResponseWriter, r *http.Req
 		app.badRequestResponse(w, r, err)
 	}
 }
+
+// commentStatus decides whether a new comment of user on the post is approved right away or held for moderation.
+// The post's moderation policy takes precedence over the global one. Moderators are never held.
+func (app *application) commentStatus(ctx context.Context, user *store.User, postID int64) (string, error) {
+	isModerator, err := app.hasPermission(ctx, user, store.PermCommentsModerate)
+	if err != nil {
+		return "", err
+	}
+	if isModerator {
+		return store.CommentStatusApproved, nil
+	}
+
+	post, err := app.store.Posts.GetPostByID(ctx, postID)
+	if err != nil {
+		return "", err
+	}
+
+	policy := app.config.moderation.policy
+	if post.Moderation != nil {
+		policy = *post.Moderation
+	}
+
+	hasApproved := false
+	if policy == store.ModerationFirstTime {
+		hasApproved, err = app.store.Comments.HasApprovedComment(ctx, user.ID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return store.ModeratedStatus(policy, hasApproved), nil
+}
+
+// parseCommentTreeQuery reads which part of the comment thread to return with a post.
+//
+// Supported query parameters:
+// - comments_depth: number of reply levels to load (1-10, default 3)
+// - comments_limit: comments per level and parent (1-100, default 20)
+// - comments_offset: top-level comments to skip
+// - comments_parent: load the replies of this comment instead of the top-level comments
+func parseCommentTreeQuery(r *http.Request) (store.CommentTreeQuery, error) {
+	qs := r.URL.Query()
+
+	cq := store.CommentTreeQuery{
+		MaxDepth: store.DefaultCommentDepth,
+		Limit:    store.DefaultCommentLimit,
+	}
+
+	params := []struct {
+		name   string
+		target *int
+		min    int
+		max    int
+	}{
+		{"comments_depth", &cq.MaxDepth, 1, store.MaxCommentDepth},
+		{"comments_limit", &cq.Limit, 1, store.MaxCommentLimit},
+		{"comments_offset", &cq.Offset, 0, math.MaxInt32},
+	}
+
+	for _, param := range params {
+		value := qs.Get(param.name)
+		if value == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil || n < param.min || n > param.max {
+			return cq, fmt.Errorf("%s must be a number between %d and %d", param.name, param.min, param.max)
+		}
+		*param.target = n
+	}
+
+	if parent := qs.Get("comments_parent"); parent != "" {
+		id, err := strconv.Atoi(parent)
+		if err != nil {
+			return cq, fmt.Errorf("comments_parent must be a comment ID")
+		}
+		cq.ParentID = &id
+	}
+
+	return cq, nil
+}
+
+type commentKey string
+
+const commentCtx commentKey = "comment"
+
+type UpdateCommentPayload struct {
+	Content string `json:"content"`
+}
+
+// UpdateComment godoc
+//
+//	@Summary		Edit a comment
+//	@Description	Changes the content of a comment. Only the author and admins can edit a comment.
+//	@Description	The new content goes through the moderation policy and the spam filter like a new comment, so an approved comment may be held again.
+//	@Tags			Comments
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID		path		int	true	"Post ID"		regexp(^[0-9]+$)
+//	@Param			commentID	path		int	true	"Comment ID"	regexp(^[0-9]+$)
+//	@Param			payload		body		UpdateCommentPayload	true	"payload"
+//	@Success		200			{object}	store.Comment
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		403			{object}	error	"Forbidden"
+//	@Failure		404			{object}	error	"Not found"
+//	@Failure		422			{object}	error	"Rejected as spam"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/comments/{commentID} [patch]
+func (app *application) updateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	comment := getCommentFromCtx(r)
+
+	var payload UpdateCommentPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if payload.Content == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("some text is required"))
+		return
+	}
+
+	ctx := r.Context()
+
+	// the comment is moderated and scored as its author's, also when someone else edits it
+	author := getUserFromCtx(r)
+	if comment.UserID != author.ID {
+		var err error
+		author, err = app.store.Users.GetUserByID(ctx, comment.UserID)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				app.notFoundResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+	}
+
+	status, err := app.commentStatus(ctx, author, int64(comment.PostID))
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	// an edit can hold an approved comment again, but never approves a held or rejected one
+	if comment.Status == store.CommentStatusApproved {
+		comment.Status = status
+	}
+
+	comment.Content = payload.Content
+
+	result, err := app.checkCommentSpam(ctx, author, comment)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	switch result.Verdict {
+	case spam.VerdictHold:
+		if comment.Status == store.CommentStatusApproved {
+			comment.Status = store.CommentStatusPending
+		}
+	case spam.VerdictReject:
+		comment.Status = store.CommentStatusRejected
+	}
+
+	if err := app.store.Comments.UpdateComment(ctx, comment); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.reportSpam(ctx, store.SpamReport{
+		Kind:      store.SpamKindComment,
+		CommentID: &comment.ID,
+		UserID:    &author.ID,
+		Content:   comment.Content,
+	}, result)
+
+	if result.Verdict == spam.VerdictReject {
+		app.unprocessableEntityResponse(w, r, errSpam)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, comment); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DeleteComment godoc
+//
+//	@Summary		Delete a comment
+//	@Description	Deletes a comment. Comments with replies are kept as tombstones without content. Only the author and admins can delete a comment.
+//	@Tags			Comments
+//	@Param			postID		path	int	true	"Post ID"		regexp(^[0-9]+$)
+//	@Param			commentID	path	int	true	"Comment ID"	regexp(^[0-9]+$)
+//	@Success		204
+//	@Failure		403	{object}	error	"Forbidden"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/comments/{commentID} [delete]
+func (app *application) deleteCommentHandler(w http.ResponseWriter, r *http.Request) {
+	comment := getCommentFromCtx(r)
+
+	if err := app.store.Comments.DeleteComment(r.Context(), comment.ID); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// commentsContextMiddleware loads the comment of the URL into the context.
+// The comment has to belong to the post in the context and must not be deleted.
+func (app *application) commentsContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		commentID, err := strconv.Atoi(chi.URLParam(r, "commentID"))
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+
+		comment, err := app.store.Comments.GetByID(ctx, commentID)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				app.notFoundResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+
+		post := getPostFromCtx(r)
+		if int64(comment.PostID) != post.ID || comment.Deleted {
+			app.notFoundResponse(w, r, fmt.Errorf("comment %d not found on post %d", comment.ID, post.ID))
+			return
+		}
+
+		ctx = context.WithValue(ctx, commentCtx, comment)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getCommentFromCtx(r *http.Request) *store.Comment {
+	comment, _ := r.Context().Value(commentCtx).(*store.Comment)
+	return comment
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/spam"
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func Test_parseCommentTreeQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    store.CommentTreeQuery
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  store.CommentTreeQuery{MaxDepth: store.DefaultCommentDepth, Limit: store.DefaultCommentLimit},
+		},
+		{
+			name:  "sub thread page",
+			query: "comments_depth=2&comments_limit=5&comments_offset=10&comments_parent=7",
+			want:  store.CommentTreeQuery{MaxDepth: 2, Limit: 5, Offset: 10},
+		},
+		{name: "depth too deep", query: "comments_depth=11", wantErr: true},
+		{name: "limit zero", query: "comments_limit=0", wantErr: true},
+		{name: "negative offset", query: "comments_offset=-1", wantErr: true},
+		{name: "invalid parent", query: "comments_parent=first", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/feed/1?"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cq, err := parseCommentTreeQuery(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCommentTreeQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if cq.MaxDepth != tt.want.MaxDepth || cq.Limit != tt.want.Limit || cq.Offset != tt.want.Offset {
+				t.Errorf("parseCommentTreeQuery() = %+v, want %+v", cq, tt.want)
+			}
+			if (cq.ParentID == nil) != (tt.name == "defaults") {
+				t.Errorf("unexpected parent %v", cq.ParentID)
+			}
+		})
+	}
+}
+
+func TestUpdateCommentHandler_Moderation(t *testing.T) {
+	blocklist, err := spam.ParseBlocklist(strings.NewReader("domain casino.example\nword casino"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := store.ModerationAll
+
+	tests := []struct {
+		name           string
+		moderation     *string
+		byAdmin        bool
+		status         string
+		content        string
+		expectedStatus int
+		expectedState  string
+		expectedReport string
+	}{
+		{name: "regular edit", status: store.CommentStatusApproved, content: "I changed my mind", expectedStatus: http.StatusOK, expectedState: store.CommentStatusApproved},
+		{name: "edited into spam", status: store.CommentStatusApproved, content: "play casino games", expectedStatus: http.StatusOK, expectedState: store.CommentStatusPending, expectedReport: spam.VerdictHold},
+		{name: "edited into rejected spam", status: store.CommentStatusApproved, content: "play casino games at https://casino.example", expectedStatus: http.StatusUnprocessableEntity, expectedState: store.CommentStatusRejected, expectedReport: spam.VerdictReject},
+		{name: "edit under a moderated post", moderation: &all, status: store.CommentStatusApproved, content: "I changed my mind", expectedStatus: http.StatusOK, expectedState: store.CommentStatusPending},
+		{name: "edit does not approve", status: store.CommentStatusPending, content: "I changed my mind", expectedStatus: http.StatusOK, expectedState: store.CommentStatusPending},
+		{name: "admin edit is moderated as the author's", moderation: &all, byAdmin: true, status: store.CommentStatusApproved, content: "I changed my mind", expectedStatus: http.StatusOK, expectedState: store.CommentStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			app.spam = spam.New(spam.DefaultConfig(), blocklist)
+			mux := app.mount()
+
+			user := &store.User{ID: uuid.New(), Username: "user", Role: store.Role{ID: 1, Name: "user", Level: 1}, IsActive: true}
+			admin := &store.User{ID: uuid.New(), Username: "admin", Role: store.Role{ID: 3, Name: "admin", Level: 3}, IsActive: true}
+			app.store.Users = &store.MockUserStore{Users: []*store.User{user, admin}}
+			app.store.Posts = &store.MockPostStore{Posts: []*store.Post{{ID: 1, UserID: uuid.New(), Status: store.PostStatusPublished, Moderation: tt.moderation}}}
+			comments := &store.MockCommentStore{Comments: []*store.Comment{{ID: 1, PostID: 1, UserID: user.ID, Content: "first", Status: tt.status}}}
+			app.store.Comments = comments
+
+			editor := user
+			if tt.byAdmin {
+				editor = admin
+			}
+
+			token, err := app.authenticator.GenerateToken(jwt.MapClaims{
+				"sub": editor.ID.String(),
+				"jti": uuid.NewString(),
+				"exp": time.Now().Add(time.Hour).Unix(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest(http.MethodPatch, "/posts/1/comments/1", strings.NewReader(`{"content":"`+tt.content+`"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+			if state := comments.Comments[0].Status; state != tt.expectedState {
+				t.Errorf("expected the comment to be %s, got %s", tt.expectedState, state)
+			}
+
+			reports := app.store.Spam.(*store.MockSpamStore).Reports
+			switch {
+			case tt.expectedReport == "" && len(reports) > 0:
+				t.Errorf("expected no spam report, got %+v", reports)
+			case tt.expectedReport != "" && (len(reports) != 1 || reports[0].Verdict != tt.expectedReport):
+				t.Errorf("expected a %s report, got %+v", tt.expectedReport, reports)
+			}
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"net/http"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/store"
 )
 
 func (app *application) internalServerError(w http.ResponseWriter, r *http.Request, err error) {
@@ -35,3 +39,48 @@ func (app *application) forbiddenResponse(w http.ResponseWriter, r *http.Request
 	log.Printf("forbidden error: %s path: %s error: %s ", r.Method, r.URL.Path, err)
 	writeJSONError(w, http.StatusForbidden, "forbidden")
 }
+
+func (app *application) conflictResponse(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("conflict error: %s path: %s error: %s ", r.Method, r.URL.Path, err)
+	writeJSONError(w, http.StatusConflict, err.Error())
+}
+
+func (app *application) preconditionFailedResponse(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("precondition failed error: %s path: %s error: %s ", r.Method, r.URL.Path, err)
+	writeJSONError(w, http.StatusPreconditionFailed, "the resource has been changed, fetch it again and retry")
+}
+
+func (app *application) preconditionRequiredResponse(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("precondition required error: %s path: %s error: %s ", r.Method, r.URL.Path, err)
+	writeJSONError(w, http.StatusPreconditionRequired, "the If-Match header is required")
+}
+
+func (app *application) unprocessableEntityResponse(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("unprocessable entity error: %s path: %s error: %s ", r.Method, r.URL.Path, err)
+	writeJSONError(w, http.StatusUnprocessableEntity, err.Error())
+}
+
+func (app *application) payloadTooLargeResponse(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("payload too large error: %s path: %s error: %s ", r.Method, r.URL.Path, err)
+	writeJSONError(w, http.StatusRequestEntityTooLarge, err.Error())
+}
+
+func (app *application) unsupportedMediaTypeResponse(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("unsupported media type error: %s path: %s error: %s ", r.Method, r.URL.Path, err)
+	writeJSONError(w, http.StatusUnsupportedMediaType, err.Error())
+}
+
+func (app *application) suspendedResponse(w http.ResponseWriter, r *http.Request, user *store.User) {
+	log.Printf("suspended user error: %s path: %s user: %s ", r.Method, r.URL.Path, user.ID)
+
+	message := "your account is banned: " + *user.SuspensionReason
+	if user.SuspendedUntil != nil {
+		message = fmt.Sprintf("your account is suspended until %s: %s", user.SuspendedUntil.Format(time.RFC3339), *user.SuspensionReason)
+	}
+	writeJSONError(w, http.StatusForbidden, message)
+}
+
+func (app *application) twoFactorRequiredResponse(w http.ResponseWriter, r *http.Request, user *store.User) {
+	log.Printf("two-factor authentication required error: %s path: %s user: %s ", r.Method, r.URL.Path, user.ID)
+	writeJSONError(w, http.StatusForbidden, "your role requires two-factor authentication, enable it at /me/2fa first")
+}
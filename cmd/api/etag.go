@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// etag returns the entity tag of a resource at the given version.
+func etag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// checkIfMatch makes sure the client edits the version it has seen.
+// The If-Match header is required; it has to contain the current ETag or "*".
+// If the precondition does not hold, the error response is written and false is returned.
+func (app *application) checkIfMatch(w http.ResponseWriter, r *http.Request, version int) bool {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		app.preconditionRequiredResponse(w, r, errors.New("missing If-Match header"))
+		return false
+	}
+
+	current := etag(version)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+
+	w.Header().Set("ETag", current)
+	app.preconditionFailedResponse(w, r, fmt.Errorf("If-Match %s does not match the current version %s", ifMatch, current))
+	return false
+}
+
+// notModified reports whether the client's copy with the given ETag and modification time is still current.
+// If-None-Match takes precedence over If-Modified-Since, as HTTP demands.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || lastModified.IsZero() {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(ims)
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/google/uuid"
+)
+
+// FollowUser godoc
+//
+//	@Summary		Follow a user
+//	@Description	The authenticated user follows the user. Their posts then show up on the timeline. Following a user twice has no effect.
+//	@Tags			Users
+//	@Param			userID	path	string	true	"User ID"
+//	@Success		204
+//	@Failure		400	{object}	error	"Bad Request"
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/follow [post]
+func (app *application) followUserHandler(w http.ResponseWriter, r *http.Request) {
+	app.setFollowUser(w, r, true)
+}
+
+// UnfollowUser godoc
+//
+//	@Summary		Unfollow a user
+//	@Description	The authenticated user stops following the user. Unfollowing a user that is not followed has no effect.
+//	@Tags			Users
+//	@Param			userID	path	string	true	"User ID"
+//	@Success		204
+//	@Failure		400	{object}	error	"Bad Request"
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/follow [delete]
+func (app *application) unfollowUserHandler(w http.ResponseWriter, r *http.Request) {
+	app.setFollowUser(w, r, false)
+}
+
+func (app *application) setFollowUser(w http.ResponseWriter, r *http.Request, follow bool) {
+	user := getUserFromCtx(r)
+	followee := getTargetUserFromCtx(r)
+	ctx := r.Context()
+
+	var err error
+	if follow {
+		err = app.store.Follows.FollowUser(ctx, user.ID, followee.ID)
+	} else {
+		err = app.store.Follows.UnfollowUser(ctx, user.ID, followee.ID)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrFollowSelf):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetFollowers godoc
+//
+//	@Summary		List the followers of a user
+//	@Description	Lists the users following the user, most recent first.
+//	@Tags			Users
+//	@Produce		json
+//	@Param			userID	path		string	true	"User ID"
+//	@Param			limit	query		int		false	"Users per page (1-100)"	default(20)
+//	@Param			offset	query		int		false	"Users to skip"	default(0)
+//	@Success		200		{object}	[]store.Follow
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/followers [get]
+func (app *application) getFollowersHandler(w http.ResponseWriter, r *http.Request) {
+	app.writeFollows(w, r, app.store.Follows.GetFollowers)
+}
+
+// GetFollowing godoc
+//
+//	@Summary		List the users a user follows
+//	@Description	Lists the users the user follows, most recent first.
+//	@Tags			Users
+//	@Produce		json
+//	@Param			userID	path		string	true	"User ID"
+//	@Param			limit	query		int		false	"Users per page (1-100)"	default(20)
+//	@Param			offset	query		int		false	"Users to skip"	default(0)
+//	@Success		200		{object}	[]store.Follow
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/following [get]
+func (app *application) getFollowingHandler(w http.ResponseWriter, r *http.Request) {
+	app.writeFollows(w, r, app.store.Follows.GetFollowing)
+}
+
+func (app *application) writeFollows(w http.ResponseWriter, r *http.Request, list func(ctx context.Context, userID uuid.UUID, fq store.FollowQuery) ([]store.Follow, error)) {
+	user := getTargetUserFromCtx(r)
+
+	fq, err := parseFollowQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	follows, err := list(r.Context(), user.ID, fq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, follows); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetFollowedTags godoc
+//
+//	@Summary		List the tags a user follows
+//	@Description	Lists the tags the user follows, ordered by name.
+//	@Tags			Users
+//	@Produce		json
+//	@Param			userID	path		string	true	"User ID"
+//	@Success		200		{object}	[]store.Tag
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{userID}/following/tags [get]
+func (app *application) getFollowedTagsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getTargetUserFromCtx(r)
+
+	tags, err := app.store.Follows.GetFollowedTags(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, tags); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// FollowTag godoc
+//
+//	@Summary		Follow a tag
+//	@Description	The authenticated user follows the tag. Its posts then show up on the timeline. Following a tag twice has no effect.
+//	@Tags			Tags
+//	@Param			slug	path	string	true	"Tag slug"
+//	@Success		204
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/tags/{slug}/follow [post]
+func (app *application) followTagHandler(w http.ResponseWriter, r *http.Request) {
+	app.setFollowTag(w, r, true)
+}
+
+// UnfollowTag godoc
+//
+//	@Summary		Unfollow a tag
+//	@Description	The authenticated user stops following the tag. Unfollowing a tag that is not followed has no effect.
+//	@Tags			Tags
+//	@Param			slug	path	string	true	"Tag slug"
+//	@Success		204
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/tags/{slug}/follow [delete]
+func (app *application) unfollowTagHandler(w http.ResponseWriter, r *http.Request) {
+	app.setFollowTag(w, r, false)
+}
+
+func (app *application) setFollowTag(w http.ResponseWriter, r *http.Request, follow bool) {
+	tag := getTagFromCtx(r)
+	user := getUserFromCtx(r)
+
+	var err error
+	if follow {
+		err = app.store.Follows.FollowTag(r.Context(), user.ID, tag.ID)
+	} else {
+		err = app.store.Follows.UnfollowTag(r.Context(), user.ID, tag.ID)
+	}
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTimeline godoc
+//
+//	@Summary		Get the home timeline
+//	@Description	Get a page of the posts by the users and with the tags the authenticated user follows.
+//	@Description	Pages work like the feed: use next_cursor of the response as cursor to fetch the next page.
+//	@Tags			Feed
+//	@Produce		json
+//	@Param			limit		query		int		false	"Posts per page (1-100)"	default(20)
+//	@Param			cursor		query		string	false	"Cursor of the next page"
+//	@Param			sort		query		string	false	"Sort by publish time"	Enums(asc, desc)	default(desc)
+//	@Param			tags		query		string	false	"Comma separated list of tag names or slugs"
+//	@Param			tag_match	query		string	false	"Match any or all tags"	Enums(any, all)	default(any)
+//	@Param			author		query		string	false	"User ID or username of the author"
+//	@Param			since		query		string	false	"Only posts published at or after this RFC 3339 timestamp"
+//	@Param			until		query		string	false	"Only posts published before this RFC 3339 timestamp"
+//	@Success		200		{object}	[]store.Post
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		401		{object}	error	"Unauthorized"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/timeline [get]
+func (app *application) getTimelineHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromCtx(r)
+
+	fq, err := parseFeedQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	fq.FollowedBy = &user.ID
+	// the timeline is for reading, drafts of followed authors stay out of it even for admins
+	if fq.Status == "" {
+		fq.Status = store.PostStatusPublished
+	}
+
+	app.writeFeed(w, r, fq)
+}
+
+// parseFollowQuery reads the limit and offset of a list of followers or followed users.
+func parseFollowQuery(r *http.Request) (store.FollowQuery, error) {
+	qs := r.URL.Query()
+
+	fq := store.FollowQuery{
+		Limit: store.DefaultFollowLimit,
+	}
+
+	if limit := qs.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > store.MaxFollowLimit {
+			return fq, fmt.Errorf("limit must be a number between 1 and %d", store.MaxFollowLimit)
+		}
+		fq.Limit = l
+	}
+
+	if offset := qs.Get("offset"); offset != "" {
+		o, err := strconv.Atoi(offset)
+		if err != nil || o < 0 {
+			return fq, fmt.Errorf("offset must be a positive number")
+		}
+		fq.Offset = o
+	}
+
+	return fq, nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/google/uuid"
+)
+
+func TestFollowUserHandlers(t *testing.T) {
+	app := newTestApplication(t)
+	follows := app.store.Follows.(*store.MockFollowStore)
+
+	user := &store.User{ID: uuid.New()}
+	author := &store.User{ID: uuid.New()}
+
+	tests := []struct {
+		name              string
+		method            string
+		target            *store.User
+		expectedStatus    int
+		expectedFollowing int
+	}{
+		{name: "follow", method: http.MethodPost, target: author, expectedStatus: http.StatusNoContent, expectedFollowing: 1},
+		{name: "follow again", method: http.MethodPost, target: author, expectedStatus: http.StatusNoContent, expectedFollowing: 1},
+		{name: "follow yourself", method: http.MethodPost, target: user, expectedStatus: http.StatusBadRequest, expectedFollowing: 1},
+		{name: "unfollow", method: http.MethodDelete, target: author, expectedStatus: http.StatusNoContent},
+		{name: "unfollow again", method: http.MethodDelete, target: author, expectedStatus: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/users/"+tt.target.ID.String()+"/follow", nil)
+			ctx := context.WithValue(req.Context(), userCTx, user)
+			ctx = context.WithValue(ctx, targetUserCtx, tt.target)
+
+			handler := app.followUserHandler
+			if tt.method == http.MethodDelete {
+				handler = app.unfollowUserHandler
+			}
+			rr := executeRequest(req.WithContext(ctx), http.HandlerFunc(handler))
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+			if following := len(follows.Users[user.ID]); following != tt.expectedFollowing {
+				t.Errorf("expected to follow %d users, got %d", tt.expectedFollowing, following)
+			}
+		})
+	}
+}
+
+func TestFollowTagHandlers(t *testing.T) {
+	app := newTestApplication(t)
+	follows := app.store.Follows.(*store.MockFollowStore)
+
+	user := &store.User{ID: uuid.New()}
+	tag := &store.Tag{ID: 7, Name: "Go", Slug: "go"}
+
+	for _, tt := range []struct {
+		handler   http.HandlerFunc
+		following int
+	}{
+		{handler: app.followTagHandler, following: 1},
+		{handler: app.followTagHandler, following: 1},
+		{handler: app.unfollowTagHandler, following: 0},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/tags/go/follow", nil)
+		ctx := context.WithValue(req.Context(), userCTx, user)
+		ctx = context.WithValue(ctx, tagCtx, tag)
+
+		rr := executeRequest(req.WithContext(ctx), tt.handler)
+
+		checkResponseCode(t, http.StatusNoContent, rr.Code)
+		if following := len(follows.Tags[user.ID]); following != tt.following {
+			t.Errorf("expected to follow %d tags, got %d", tt.following, following)
+		}
+	}
+}
+
+func TestGetFollowersHandler(t *testing.T) {
+	app := newTestApplication(t)
+
+	author := &store.User{ID: uuid.New()}
+	for range 3 {
+		app.store.Follows.FollowUser(context.Background(), uuid.New(), author.ID)
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{name: "all", expectedStatus: http.StatusOK, expectedCount: 3},
+		{name: "page", query: "?limit=2&offset=2", expectedStatus: http.StatusOK, expectedCount: 1},
+		{name: "limit too large", query: "?limit=101", expectedStatus: http.StatusBadRequest},
+		{name: "negative offset", query: "?offset=-1", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users/"+author.ID.String()+"/followers"+tt.query, nil)
+			ctx := context.WithValue(req.Context(), targetUserCtx, author)
+
+			rr := executeRequest(req.WithContext(ctx), http.HandlerFunc(app.getFollowersHandler))
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data []store.Follow `json:"data"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Data) != tt.expectedCount {
+				t.Errorf("expected %d followers, got %d", tt.expectedCount, len(resp.Data))
+			}
+		})
+	}
+}
+
+func TestGetUserByIDHandler_FollowCounts(t *testing.T) {
+	app := newTestApplication(t)
+
+	user := &store.User{ID: uuid.New()}
+	app.store.Follows.FollowUser(context.Background(), uuid.New(), user.ID)
+	app.store.Follows.FollowUser(context.Background(), uuid.New(), user.ID)
+	app.store.Follows.FollowUser(context.Background(), user.ID, uuid.New())
+
+	req := httptest.NewRequest(http.MethodGet, "/users/"+user.ID.String(), nil)
+	ctx := context.WithValue(req.Context(), targetUserCtx, user)
+	ctx = context.WithValue(ctx, userCTx, &store.User{ID: uuid.New()})
+
+	rr := executeRequest(req.WithContext(ctx), http.HandlerFunc(app.getUserByIDHandler))
+	checkResponseCode(t, http.StatusOK, rr.Code)
+
+	var resp struct {
+		Data store.User `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.Follows == nil || *resp.Data.Follows != (store.FollowCounts{Followers: 2, Following: 1}) {
+		t.Errorf("unexpected follow counts %+v", resp.Data.Follows)
+	}
+}
+
+func TestGetTimelineHandler(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	t.Run("should not allow unauthenticated requests", func(t *testing.T) {
+		rr := executeRequest(httptest.NewRequest(http.MethodGet, "/timeline", nil), mux)
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("should reject invalid pagination", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/timeline?limit=0", nil)
+		ctx := context.WithValue(req.Context(), userCTx, &store.User{ID: uuid.New()})
+
+		rr := executeRequest(req.WithContext(ctx), http.HandlerFunc(app.getTimelineHandler))
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should return a page of posts", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/timeline", nil)
+		ctx := context.WithValue(req.Context(), userCTx, &store.User{ID: uuid.New()})
+
+		rr := executeRequest(req.WithContext(ctx), http.HandlerFunc(app.getTimelineHandler))
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+}
@@ -37,3 +37,13 @@ func (app *application) jsonResponse(w http.ResponseWriter, status int, data any
 	}
 	return writeJSON(w, status, &envelope{Data: data})
 }
+
+// paginatedJSONResponse writes data in the same envelope as jsonResponse, together with the
+// cursor of the next page. next_cursor is omitted on the last page.
+func (app *application) paginatedJSONResponse(w http.ResponseWriter, status int, data any, nextCursor string) error {
+	type envelope struct {
+		Data       any    `json:"data"`
+		NextCursor string `json:"next_cursor,omitempty"`
+	}
+	return writeJSON(w, status, &envelope{Data: data, NextCursor: nextCursor})
+}
@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	_ "github.com/ITine-Tech/blog/docs"
 	"github.com/ITine-Tech/blog/internal/auth"
 	"github.com/ITine-Tech/blog/internal/db"
+	"github.com/ITine-Tech/blog/internal/mailer"
+	"github.com/ITine-Tech/blog/internal/media"
+	"github.com/ITine-Tech/blog/internal/spam"
 	"github.com/ITine-Tech/blog/internal/store"
 
 	"github.com/joho/godotenv"
@@ -35,9 +40,15 @@ func main() {
 	if err != nil {
 		log.Println("Warning: .env file not found or could not be loaded")
 	}
+	smtpPort, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if err != nil {
+		smtpPort = 587
+	}
+
 	cfg := config{
-		addr:   os.Getenv("LOCALHOST_ADDR"),
-		apiURL: os.Getenv("API_URL"),
+		addr:        os.Getenv("LOCALHOST_ADDR"),
+		apiURL:      os.Getenv("API_URL"),
+		frontendURL: os.Getenv("FRONTEND_URL"),
 		db: dbConfig{
 			addr:         os.Getenv("DB_CONN_STRING"),
 			maxOpenConns: 30,
@@ -45,7 +56,17 @@ func main() {
 			maxIdleTime:  "15m",
 		},
 		mail: mailConfig{
-			exp: time.Hour * 24 * 3,
+			exp:              time.Hour * 24 * 3,
+			passwordResetExp: time.Hour,
+			fromEmail:        os.Getenv("MAIL_FROM"),
+			backend:          os.Getenv("MAIL_BACKEND"),
+			dir:              os.Getenv("MAIL_DIR"),
+			smtp: smtpConfig{
+				host:     os.Getenv("SMTP_HOST"),
+				port:     smtpPort,
+				username: os.Getenv("SMTP_USERNAME"),
+				password: os.Getenv("SMTP_PASSWORD"),
+			},
 		},
 		auth: authConfig{
 			basic: basicConfig{
@@ -53,11 +74,80 @@ func main() {
 				pass:     os.Getenv("ADMIN_PASSWORD"),
 			},
 			token: tokenConfig{
-				secret: os.Getenv("TOKEN_SECRET"),
-				expiry: time.Hour * 24 * 3,
-				issuer: os.Getenv("TOKEN_ISSUER"),
+				secret:        os.Getenv("TOKEN_SECRET"),
+				expiry:        time.Minute * 15,
+				refreshExpiry: time.Hour * 24 * 14,
+				issuer:        os.Getenv("TOKEN_ISSUER"),
+			},
+			twoFactor: twoFactorConfig{
+				issuer:          os.Getenv("TOTP_ISSUER"),
+				challengeExpiry: time.Minute * 5,
 			},
 		},
+		scheduler: schedulerConfig{
+			interval: time.Minute,
+		},
+		moderation: moderationConfig{
+			policy: os.Getenv("COMMENT_MODERATION"),
+		},
+		syndication: syndicationConfig{
+			title: os.Getenv("FEED_TITLE"),
+			limit: store.DefaultFeedLimit,
+		},
+		media: mediaConfig{
+			dir:           os.Getenv("MEDIA_DIR"),
+			maxSize:       10 << 20,
+			quota:         100 << 20,
+			thumbnailSize: 320,
+		},
+	}
+
+	if cfg.syndication.title == "" {
+		cfg.syndication.title = "Blog"
+	}
+	if limit := os.Getenv("FEED_ITEM_LIMIT"); limit != "" {
+		cfg.syndication.limit, err = strconv.Atoi(limit)
+		if err != nil || cfg.syndication.limit < 1 || cfg.syndication.limit > store.MaxFeedLimit {
+			log.Panicf("FEED_ITEM_LIMIT must be a number between 1 and %d", store.MaxFeedLimit)
+		}
+	}
+
+	if cfg.auth.twoFactor.issuer == "" {
+		cfg.auth.twoFactor.issuer = cfg.syndication.title
+	}
+	totpCipher, err := newTOTPCipher(os.Getenv("TOTP_ENCRYPTION_KEY"))
+	if err != nil {
+		log.Panic(err)
+	}
+
+	cfg.reactions.kinds, err = parseReactionKinds(os.Getenv("REACTIONS"))
+	if err != nil {
+		log.Panic(err)
+	}
+
+	if cfg.media.dir == "" {
+		cfg.media.dir = "./tmp/media"
+	}
+	if size := os.Getenv("MEDIA_MAX_SIZE_MB"); size != "" {
+		mb, err := strconv.Atoi(size)
+		if err != nil || mb < 1 {
+			log.Panic("MEDIA_MAX_SIZE_MB must be a positive number")
+		}
+		cfg.media.maxSize = int64(mb) << 20
+	}
+	if quota := os.Getenv("MEDIA_QUOTA_MB"); quota != "" {
+		mb, err := strconv.Atoi(quota)
+		if err != nil || mb < 0 {
+			log.Panic("MEDIA_QUOTA_MB must be 0 or a positive number")
+		}
+		cfg.media.quota = int64(mb) << 20
+	}
+
+	if cfg.moderation.policy == "" {
+		cfg.moderation.policy = store.ModerationOpen
+	}
+	if !store.ValidModeration(cfg.moderation.policy) {
+		log.Panic(store.ErrInvalidModeration)
 	}
 
 	db, err := db.NewDB(
@@ -78,12 +168,47 @@ func main() {
 	tokenHost := os.Getenv("TOKEN_AUDIENCE")
 	JWTAuthenticator := auth.NewJWTAuthenticator(cfg.auth.token.secret, tokenHost, cfg.auth.token.issuer)
 
+	var mail mailer.Mailer
+	switch cfg.mail.backend {
+	case "smtp":
+		mail = mailer.NewSMTPMailer(cfg.mail.smtp.host, cfg.mail.smtp.port, cfg.mail.smtp.username, cfg.mail.smtp.password, cfg.mail.fromEmail)
+	default:
+		dir := cfg.mail.dir
+		if dir == "" {
+			dir = "./tmp/mail"
+		}
+		mail, err = mailer.NewFileMailer(dir, cfg.mail.fromEmail)
+		if err != nil {
+			log.Panic(err)
+		}
+	}
+
+	var blocklist *spam.Blocklist
+	if path := os.Getenv("SPAM_BLOCKLIST"); path != "" {
+		blocklist, err = spam.LoadBlocklist(path)
+		if err != nil {
+			log.Panic(err)
+		}
+	}
+
+	blobs, err := media.NewLocalBlobStore(cfg.media.dir)
+	if err != nil {
+		log.Panic(err)
+	}
+
 	app := &application{
 		config:        cfg,
 		store:         myStore,
 		authenticator: JWTAuthenticator,
+		mailer:        mail,
+		spam:          spam.New(spam.DefaultConfig(), blocklist),
+		blobs:         blobs,
+		totp:          totpCipher,
 	}
 
+	go app.publishScheduledPosts(context.Background(), cfg.scheduler.interval)
+	go app.renderStaleHTML(context.Background())
+
 	mux := app.mount()
 	log.Fatal(app.run(mux))
 }
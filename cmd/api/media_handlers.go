@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ITine-Tech/blog/internal/media"
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+type mediaKey string
+
+const mediaCtx mediaKey = "media"
+
+// multipartOverhead leaves room for the boundary and the headers around the uploaded file.
+const multipartOverhead = 64 << 10
+
+// maxFilenameLength is the longest file name that is kept, in bytes.
+const maxFilenameLength = 255
+
+var errUploadTooLarge = errors.New("the file is too large")
+
+// UploadMedia godoc
+//
+//	@Summary		Upload an image
+//	@Description	Uploads a JPEG, PNG or GIF image as multipart/form-data in the field "file". The type is detected from the content.
+//	@Description	Metadata like EXIF is removed and a thumbnail is created. Uploads count towards the user's storage quota.
+//	@Tags			Media
+//	@Accept			mpfd
+//	@Produce		json
+//	@Param			file	formData	file	true	"The image"
+//	@Success		201		{object}	store.MediaFile
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		401		{object}	error	"Unauthorized"
+//	@Failure		413		{object}	error	"File too large or quota exceeded"
+//	@Failure		415		{object}	error	"Not a JPEG, PNG or GIF image"
+//	@Failure		422		{object}	error	"The image cannot be decoded"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/media [post]
+func (app *application) uploadMediaHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromCtx(r)
+	maxSize := app.config.media.maxSize
+
+	// uploads are read here instead of with readJSON and its limit of 1MB
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+multipartOverhead)
+
+	data, filename, err := readUpload(r, "file", maxSize)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.Is(err, errUploadTooLarge), errors.As(err, &maxBytesErr):
+			app.payloadTooLargeResponse(w, r, fmt.Errorf("files can be at most %d bytes", maxSize))
+		default:
+			app.badRequestResponse(w, r, err)
+		}
+		return
+	}
+
+	img, err := media.Process(data, app.config.media.thumbnailSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, media.ErrUnsupportedType):
+			app.unsupportedMediaTypeResponse(w, r, err)
+		case errors.Is(err, media.ErrTooManyPixels):
+			app.payloadTooLargeResponse(w, r, err)
+		case errors.Is(err, media.ErrInvalidImage):
+			app.unprocessableEntityResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	file := &store.MediaFile{
+		UserID:            user.ID,
+		Filename:          filename,
+		MIMEType:          img.MIMEType,
+		Size:              int64(len(img.Data)),
+		Checksum:          media.Key(img.Data),
+		Width:             img.Width,
+		Height:            img.Height,
+		ThumbnailMIMEType: img.ThumbnailMIMEType,
+		ThumbnailSize:     int64(len(img.Thumbnail)),
+		ThumbnailChecksum: media.Key(img.Thumbnail),
+	}
+
+	ctx := r.Context()
+
+	// the record is created before the files are written, so that the quota also limits the disk space
+	if err := app.store.Media.Create(ctx, file, app.config.media.quota); err != nil {
+		switch {
+		case errors.Is(err, store.ErrQuotaExceeded):
+			app.payloadTooLargeResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.storeBlobs(ctx, img.Data, img.Thumbnail); err != nil {
+		if err := app.store.Media.Delete(ctx, file.ID); err != nil {
+			log.Printf("error removing media %d without files: %s", file.ID, err)
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, file); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// readUpload reads the file in field of a multipart request. Other fields are skipped.
+func readUpload(r *http.Request, field string, maxSize int64) ([]byte, string, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, "", err
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, "", fmt.Errorf("the field %q is missing", field)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		if part.FormName() != field {
+			part.Close()
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(part, maxSize+1))
+		if err != nil {
+			return nil, "", err
+		}
+		if int64(len(data)) > maxSize {
+			return nil, "", errUploadTooLarge
+		}
+		if len(data) == 0 {
+			return nil, "", errors.New("the file is empty")
+		}
+
+		return data, cleanFilename(part.FileName()), nil
+	}
+}
+
+// cleanFilename keeps the name of an uploaded file for display. It is never used as a path.
+func cleanFilename(name string) string {
+	name = strings.ToValidUTF8(strings.TrimSpace(name), "")
+	for len(name) > maxFilenameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+func (app *application) storeBlobs(ctx context.Context, blobs ...[]byte) error {
+	for _, data := range blobs {
+		if _, err := app.blobs.Put(ctx, bytes.NewReader(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetMedia godoc
+//
+//	@Summary		Download an image
+//	@Description	Serves an uploaded image. Supports range requests and conditional requests with If-None-Match and If-Modified-Since.
+//	@Description	The content of an image never changes, so it can be cached for good.
+//	@Tags			Media
+//	@Produce		jpeg
+//	@Produce		png
+//	@Produce		gif
+//	@Param			mediaID	path	int	true	"Media ID"
+//	@Success		200
+//	@Success		206	"Partial Content"
+//	@Success		304	"Not Modified"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		416	"Range Not Satisfiable"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Router			/media/{mediaID} [get]
+func (app *application) getMediaHandler(w http.ResponseWriter, r *http.Request) {
+	file := getMediaFromCtx(r)
+	app.serveBlob(w, r, file.Checksum, file.MIMEType, file.CreatedAt)
+}
+
+// GetMediaThumbnail godoc
+//
+//	@Summary		Download the thumbnail of an image
+//	@Description	Serves the thumbnail of an uploaded image, a JPEG for JPEGs and a PNG otherwise. Supports the same headers as the image itself.
+//	@Tags			Media
+//	@Produce		jpeg
+//	@Produce		png
+//	@Param			mediaID	path	int	true	"Media ID"
+//	@Success		200
+//	@Success		206	"Partial Content"
+//	@Success		304	"Not Modified"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Router			/media/{mediaID}/thumbnail [get]
+func (app *application) getMediaThumbnailHandler(w http.ResponseWriter, r *http.Request) {
+	file := getMediaFromCtx(r)
+	app.serveBlob(w, r, file.ThumbnailChecksum, file.ThumbnailMIMEType, file.CreatedAt)
+}
+
+// serveBlob writes a file of the blob store. http.ServeContent answers range and conditional
+// requests; the ETag is the checksum, as the content of a key never changes.
+func (app *application) serveBlob(w http.ResponseWriter, r *http.Request, key, contentType string, modified time.Time) {
+	blob, err := app.blobs.Open(r.Context(), key)
+	if err != nil {
+		switch {
+		case errors.Is(err, media.ErrBlobNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	defer blob.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+key+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'")
+
+	http.ServeContent(w, r, "", modified, blob)
+}
+
+func (app *application) mediaContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaID, err := strconv.ParseInt(chi.URLParam(r, "mediaID"), 10, 64)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+
+		file, err := app.store.Media.GetByID(ctx, mediaID)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				app.notFoundResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+
+		ctx = context.WithValue(ctx, mediaCtx, file)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getMediaFromCtx(r *http.Request) *store.MediaFile {
+	file, _ := r.Context().Value(mediaCtx).(*store.MediaFile)
+	return file
+}
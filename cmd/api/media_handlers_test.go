@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/google/uuid"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func newUploadRequest(t *testing.T, field, filename string, data []byte) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile(field, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/media", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func uploadMedia(t *testing.T, app *application, user *store.User, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+
+	ctx := context.WithValue(req.Context(), userCTx, user)
+	return executeRequest(req.WithContext(ctx), http.HandlerFunc(app.uploadMediaHandler))
+}
+
+func TestUploadMediaHandler(t *testing.T) {
+	user := &store.User{ID: uuid.New()}
+
+	tests := []struct {
+		name           string
+		req            func(t *testing.T) *http.Request
+		quotaUsed      int64
+		expectedStatus int
+	}{
+		{
+			name:           "png",
+			req:            func(t *testing.T) *http.Request { return newUploadRequest(t, "file", "gopher.png", testPNG(t, 200, 100)) },
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "not an image",
+			req:            func(t *testing.T) *http.Request { return newUploadRequest(t, "file", "gopher.png", []byte("<script>alert(1)</script>")) },
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "missing file",
+			req:            func(t *testing.T) *http.Request { return newUploadRequest(t, "image", "gopher.png", testPNG(t, 10, 10)) },
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "too large",
+			req:            func(t *testing.T) *http.Request { return newUploadRequest(t, "file", "big.png", make([]byte, 2<<20)) },
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "quota exceeded",
+			req:            func(t *testing.T) *http.Request { return newUploadRequest(t, "file", "gopher.png", testPNG(t, 10, 10)) },
+			quotaUsed:      2 << 20,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "not multipart",
+			req: func(t *testing.T) *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/media", bytes.NewReader(testPNG(t, 10, 10)))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			files := app.store.Media.(*store.MockMediaStore)
+			if tt.quotaUsed > 0 {
+				files.Files = append(files.Files, &store.MediaFile{ID: 1, UserID: user.ID, Size: tt.quotaUsed})
+			}
+
+			rr := uploadMedia(t, app, user, tt.req(t))
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var resp struct {
+				Data store.MediaFile `json:"data"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Data.MIMEType != "image/png" || resp.Data.Width != 200 || resp.Data.Filename != "gopher.png" || resp.Data.UserID != user.ID {
+				t.Errorf("unexpected media %+v", resp.Data)
+			}
+		})
+	}
+}
+
+func TestGetMediaHandler(t *testing.T) {
+	app := newTestApplication(t)
+
+	// the middleware copies files to the connection with io.ReaderFrom, which a ResponseRecorder lacks
+	server := httptest.NewServer(app.mount())
+	defer server.Close()
+
+	rr := uploadMedia(t, app, &store.User{ID: uuid.New()}, newUploadRequest(t, "file", "gopher.png", testPNG(t, 200, 100)))
+	checkResponseCode(t, http.StatusCreated, rr.Code)
+
+	file := app.store.Media.(*store.MockMediaStore).Files[0]
+	target := "/media/" + strconv.FormatInt(file.ID, 10)
+	etag := `"` + file.Checksum + `"`
+
+	tests := []struct {
+		name           string
+		target         string
+		header         http.Header
+		expectedStatus int
+		expectedLength int64
+	}{
+		{name: "image", target: target, expectedStatus: http.StatusOK, expectedLength: file.Size},
+		{name: "thumbnail", target: target + "/thumbnail", expectedStatus: http.StatusOK, expectedLength: file.ThumbnailSize},
+		{name: "range", target: target, header: http.Header{"Range": {"bytes=0-9"}}, expectedStatus: http.StatusPartialContent, expectedLength: 10},
+		{name: "unsatisfiable range", target: target, header: http.Header{"Range": {"bytes=100000-"}}, expectedStatus: http.StatusRequestedRangeNotSatisfiable},
+		{name: "not modified", target: target, header: http.Header{"If-None-Match": {etag}}, expectedStatus: http.StatusNotModified},
+		{name: "missing", target: "/media/99", expectedStatus: http.StatusNotFound},
+		{name: "invalid id", target: "/media/abc", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, server.URL+tt.target, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+
+			resp, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			checkResponseCode(t, tt.expectedStatus, resp.StatusCode)
+			if tt.expectedLength > 0 && int64(len(body)) != tt.expectedLength {
+				t.Errorf("expected %d bytes, got %d", tt.expectedLength, len(body))
+			}
+			if resp.StatusCode == http.StatusOK {
+				if resp.Header.Get("Content-Type") != "image/png" || resp.Header.Get("Cache-Control") == "" || resp.Header.Get("Accept-Ranges") != "bytes" {
+					t.Errorf("unexpected headers %v", resp.Header)
+				}
+			}
+		})
+	}
+}
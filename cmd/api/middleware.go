@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/ITine-Tech/blog/internal/store"
 	"github.com/golang-jwt/jwt/v5"
@@ -48,6 +51,16 @@ func (app *application) basicAuthMiddleware() func(http.Handler) http.Handler {
 }
 
 func (app *application) AuthTokenMiddleware(next http.Handler) http.Handler {
+	return app.authTokenMiddleware(next, false)
+}
+
+// enrolmentAuthTokenMiddleware authenticates the request like AuthTokenMiddleware, but also lets users through
+// whose role requires two-factor authentication they have not enabled yet, so that they can enable it.
+func (app *application) enrolmentAuthTokenMiddleware(next http.Handler) http.Handler {
+	return app.authTokenMiddleware(next, true)
+}
+
+func (app *application) authTokenMiddleware(next http.Handler, allowUnenrolled bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
@@ -69,42 +82,157 @@ func (app *application) AuthTokenMiddleware(next http.Handler) http.Handler {
 		}
 
 		claims, _ := jwtToken.Claims.(jwt.MapClaims)
-		
+
+		// two-factor challenges and other special purpose tokens are no access tokens
+		if _, ok := claims["typ"]; ok {
+			app.unauthorizedResponse(w, r, errors.New("not an access token"))
+			return
+		}
+
 		userID, err := uuid.Parse(claims["sub"].(string))
 		if err != nil {
 			app.unauthorizedResponse(w, r, err)
 			return
 		}
 
+		jti, err := jtiFromClaims(claims)
+		if err != nil {
+			app.unauthorizedResponse(w, r, err)
+			return
+		}
+
 		ctx := r.Context()
+
+		revoked, err := app.store.Tokens.IsAccessTokenRevoked(ctx, jti)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if revoked {
+			app.unauthorizedResponse(w, r, errors.New("token has been revoked"))
+			return
+		}
+
 		user, err := app.store.Users.GetUserByID(ctx, userID)
 		if err != nil {
 			app.unauthorizedResponse(w, r, err)
+			return
+		}
+
+		if issuedBeforePasswordChange(claims, user) {
+			app.unauthorizedResponse(w, r, errors.New("token was issued before the password was changed"))
+			return
+		}
+
+		if app.refuseSuspended(w, r, user) {
+			return
+		}
+
+		if !allowUnenrolled && user.Role.RequiresTwoFactor && !user.TwoFactorEnabled {
+			app.twoFactorRequiredResponse(w, r, user)
+			return
 		}
 
 		ctx = context.WithValue(ctx, userCTx, user)
+		ctx = context.WithValue(ctx, claimsCtx, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-func (app *application) checkPostOwnership(requiredRole string, next http.HandlerFunc) http.HandlerFunc {
+// refuseSuspended responds with the suspension of the user and returns true if the user is suspended or banned.
+func (app *application) refuseSuspended(w http.ResponseWriter, r *http.Request, user *store.User) bool {
+	if !user.Suspended(time.Now()) {
+		return false
+	}
+
+	app.suspendedResponse(w, r, user)
+	return true
+}
+
+// optionalAuthTokenMiddleware authenticates the request like AuthTokenMiddleware when it carries
+// an Authorization header, and lets anonymous requests through without a user in the context.
+func (app *application) optionalAuthTokenMiddleware(next http.Handler) http.Handler {
+	authenticated := app.AuthTokenMiddleware(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		authenticated.ServeHTTP(w, r)
+	})
+}
+
+// issuedBeforePasswordChange tells whether the token was issued before the user's password was changed.
+// Tokens only carry whole seconds, so those issued in the second of the change are still accepted.
+func issuedBeforePasswordChange(claims jwt.MapClaims, user *store.User) bool {
+	if user.PasswordChangedAt == nil {
+		return false
+	}
+
+	iat, err := claims.GetIssuedAt()
+	if err != nil || iat == nil {
+		return true
+	}
+
+	return iat.Before(user.PasswordChangedAt.Truncate(time.Second))
+}
+
+func (app *application) checkPostOwnership(permission string, next http.HandlerFunc) http.HandlerFunc {
+	return app.checkOwnership(permission, func(r *http.Request) uuid.UUID {
+		return getPostFromCtx(r).UserID
+	}, next)
+}
+
+// checkUserOwnership lets users through to their own profile, and users with the permission to every profile.
+func (app *application) checkUserOwnership(permission string, next http.HandlerFunc) http.HandlerFunc {
+	return app.checkOwnership(permission, func(r *http.Request) uuid.UUID {
+		return getTargetUserFromCtx(r).ID
+	}, next)
+}
+
+// checkUserRank only lets users through to users whose role does not outrank their own,
+// so that managing users cannot be turned against those with more rights.
+func (app *application) checkUserRank(next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := getUserFromCtx(r)
+		target := getTargetUserFromCtx(r)
+
+		if target.Role.Level > user.Role.Level {
+			app.forbiddenResponse(w, r, fmt.Errorf("the role of user %s outranks yours", target.ID))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (app *application) checkCommentOwnership(permission string, next http.HandlerFunc) http.HandlerFunc {
+	return app.checkOwnership(permission, func(r *http.Request) uuid.UUID {
+		return getCommentFromCtx(r).UserID
+	}, next)
+}
+
+// checkOwnership lets the request through if the user owns the resource, or if the user's role
+// has the permission. owner returns the ID of the user owning the resource in the request context.
+func (app *application) checkOwnership(permission string, owner func(*http.Request) uuid.UUID, next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user := getUserFromCtx(r)
-		post := getPostFromCtx(r)
 
-		if post.UserID == user.ID {
+		if owner(r) == user.ID {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		allowedRole, err := app.checkRolePrecedence(r.Context(), user, requiredRole)
+		allowed, err := app.hasPermission(r.Context(), user, permission)
 		if err != nil {
 			app.internalServerError(w, r, err)
 			return
 		}
 
-		if !allowedRole {
-			app.forbiddenResponse(w, r, err)
+		if !allowed {
+			app.forbiddenResponse(w, r, fmt.Errorf("user %s is neither the owner nor allowed to %s", user.ID, permission))
 			return
 		}
 
@@ -113,6 +241,59 @@ func (app *application) checkPostOwnership(requiredRole string, next http.Handle
 
 }
 
+// RequirePermission only lets users through whose role has the permission.
+func (app *application) RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := getUserFromCtx(r)
+
+			allowed, err := app.hasPermission(r.Context(), user, permission)
+			if err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+
+			if !allowed {
+				app.forbiddenResponse(w, r, fmt.Errorf("user %s is not allowed to %s", user.ID, permission))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// permissionFallbackRoles maps each permission to the role that granted it before roles had permissions.
+var permissionFallbackRoles = map[string]string{
+	store.PermPostsReadAny:      "admin",
+	store.PermPostsUpdateAny:    "admin",
+	store.PermPostsDeleteAny:    "admin",
+	store.PermCommentsUpdateAny: "admin",
+	store.PermCommentsDeleteAny: "admin",
+	store.PermCommentsModerate:  "moderator",
+	store.PermTagsManage:        "admin",
+	store.PermUsersManage:       "admin",
+	store.PermRolesManage:       "admin",
+}
+
+// hasPermission tells whether the role of the user grants the permission. Roles without any permissions
+// fall back to the level precedence: their users may do what the role in permissionFallbackRoles may do.
+func (app *application) hasPermission(ctx context.Context, user *store.User, permission string) (bool, error) {
+	permissions, err := app.store.Roles.GetPermissions(ctx, user.Role.ID)
+	if err != nil {
+		return false, err
+	}
+	if len(permissions) > 0 {
+		return slices.Contains(permissions, permission), nil
+	}
+
+	fallback, ok := permissionFallbackRoles[permission]
+	if !ok {
+		return false, nil
+	}
+	return app.checkRolePrecedence(ctx, user, fallback)
+}
+
 func (app *application) checkRolePrecedence(ctx context.Context, user *store.User, roleName string) (bool, error) {
 	role, err := app.store.Roles.GetByName(ctx, roleName)
 	if err != nil {
@@ -121,3 +302,34 @@ func (app *application) checkRolePrecedence(ctx context.Context, user *store.Use
 
 	return user.Role.Level >= role.Level, nil
 }
+
+// mayGrantRole tells whether the user may give the role to others: it must not outrank the user's role,
+// and the user must already have every permission the role grants, including those of the level fallback.
+func (app *application) mayGrantRole(ctx context.Context, user *store.User, role *store.Role) (bool, error) {
+	if role.Level > user.Role.Level {
+		return false, nil
+	}
+
+	permissions, err := app.store.Roles.GetAllPermissions(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	holder := &store.User{Role: *role}
+	for _, permission := range permissions {
+		grants, err := app.hasPermission(ctx, holder, permission.Name)
+		if err != nil {
+			return false, err
+		}
+		if !grants {
+			continue
+		}
+
+		allowed, err := app.hasPermission(ctx, user, permission.Name)
+		if err != nil || !allowed {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"net/http"
@@ -8,7 +9,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ITine-Tech/blog/internal/store"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 func Test_basicAuthMiddleware(t *testing.T) {
@@ -213,3 +216,133 @@ func Test_AuthTokenMiddleware_ExpiredToken(t *testing.T) {
 	}
 
 }
+
+func Test_AuthTokenMiddleware_MissingJTI(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	token, err := app.authenticator.GenerateToken(jwt.MapClaims{
+		"sub": "65ea315e-ca1c-4af8-956b-57ed94378e94",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/users", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	rr := executeRequest(req, mux)
+
+	checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+}
+
+func Test_issuedBeforePasswordChange(t *testing.T) {
+	changed := time.Date(2026, 1, 1, 12, 0, 30, 600_000_000, time.UTC)
+
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		want     bool
+	}{
+		{name: "issued the second before", issuedAt: changed.Add(-time.Second), want: true},
+		{name: "issued in the same second", issuedAt: changed.Add(100 * time.Millisecond), want: false},
+		{name: "issued the second after", issuedAt: changed.Add(time.Second), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwt.MapClaims{"iat": float64(tt.issuedAt.Unix())}
+			user := &store.User{PasswordChangedAt: &changed}
+
+			if got := issuedBeforePasswordChange(claims, user); got != tt.want {
+				t.Errorf("issuedBeforePasswordChange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkCommentOwnership(t *testing.T) {
+	app := newTestApplication(t)
+
+	author := uuid.New()
+	next := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	tests := []struct {
+		name           string
+		user           *store.User
+		expectedStatus int
+	}{
+		{
+			name:           "author",
+			user:           &store.User{ID: author, Role: store.Role{Name: "user", Level: 1}},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "admin",
+			user:           &store.User{ID: uuid.New(), Role: store.Role{Name: "admin", Level: 3}},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "other user",
+			user:           &store.User{ID: uuid.New(), Role: store.Role{Name: "user", Level: 1}},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/posts/1/comments/1", nil)
+			ctx := context.WithValue(req.Context(), userCTx, tt.user)
+			ctx = context.WithValue(ctx, commentCtx, &store.Comment{ID: 1, PostID: 1, UserID: author})
+
+			rr := executeRequest(req.WithContext(ctx), app.checkCommentOwnership(store.PermCommentsDeleteAny, next))
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	app := newTestApplication(t)
+	app.store.Roles = &store.MockRoleStore{Roles: []*store.Role{
+		{ID: 1, Name: "user", Level: 1},
+		{ID: 2, Name: "moderator", Level: 2},
+		{ID: 3, Name: "admin", Level: 3, Permissions: []string{store.PermCommentsModerate}},
+		{ID: 4, Name: "editor", Level: 1, Permissions: []string{store.PermTagsManage}},
+	}}
+
+	next := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	tests := []struct {
+		name           string
+		role           store.Role
+		permission     string
+		expectedStatus int
+	}{
+		{name: "granted by the role", role: store.Role{ID: 4, Name: "editor", Level: 1}, permission: store.PermTagsManage, expectedStatus: http.StatusNoContent},
+		{name: "not granted by the role", role: store.Role{ID: 4, Name: "editor", Level: 1}, permission: store.PermCommentsModerate, expectedStatus: http.StatusForbidden},
+		{name: "permissions take precedence over the level", role: store.Role{ID: 3, Name: "admin", Level: 3}, permission: store.PermTagsManage, expectedStatus: http.StatusForbidden},
+		{name: "level fallback", role: store.Role{ID: 2, Name: "moderator", Level: 2}, permission: store.PermCommentsModerate, expectedStatus: http.StatusNoContent},
+		{name: "level fallback too low", role: store.Role{ID: 2, Name: "moderator", Level: 2}, permission: store.PermTagsManage, expectedStatus: http.StatusForbidden},
+		{name: "level fallback of the lowest role", role: store.Role{ID: 1, Name: "user", Level: 1}, permission: store.PermCommentsModerate, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			ctx := context.WithValue(req.Context(), userCTx, &store.User{ID: uuid.New(), Role: tt.role})
+
+			rr := executeRequest(req.WithContext(ctx), app.RequirePermission(tt.permission)(http.HandlerFunc(next)))
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ITine-Tech/blog/internal/store"
+)
+
+const maxModerationBatch = 100
+
+type ModerateCommentsPayload struct {
+	IDs []int `json:"ids"`
+}
+
+type ModerationResult struct {
+	// Updated is the number of comments whose status changed.
+	Updated int64 `json:"updated"`
+}
+
+// GetModerationQueue godoc
+//
+//	@Summary		List comments for moderation
+//	@Description	Lists comments by moderation status, oldest first. Only moderators and admins can use this endpoint.
+//	@Tags			Moderation
+//	@Produce		json
+//	@Param			status	query		string	false	"Comment status"	Enums(pending, approved, rejected)	default(pending)
+//	@Param			limit	query		int		false	"Comments per page (1-100)"	default(20)
+//	@Param			offset	query		int		false	"Number of comments to skip"	default(0)
+//	@Success		200		{object}	[]store.Comment
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/comments [get]
+func (app *application) getModerationQueueHandler(w http.ResponseWriter, r *http.Request) {
+	mq, err := parseModerationQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	comments, err := app.store.Comments.GetByStatus(r.Context(), mq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, comments); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// ApproveComments godoc
+//
+//	@Summary		Approve comments
+//	@Description	Approves pending or rejected comments, which makes them public. Only moderators and admins can use this endpoint.
+//	@Tags			Moderation
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		ModerateCommentsPayload	true	"IDs of the comments"
+//	@Success		200		{object}	ModerationResult
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/comments/approve [post]
+func (app *application) approveCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	app.moderateComments(w, r, store.CommentStatusApproved)
+}
+
+// RejectComments godoc
+//
+//	@Summary		Reject comments
+//	@Description	Rejects pending comments. Approved comments cannot be rejected, delete them instead. Only moderators and admins can use this endpoint.
+//	@Tags			Moderation
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		ModerateCommentsPayload	true	"IDs of the comments"
+//	@Success		200		{object}	ModerationResult
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/comments/reject [post]
+func (app *application) rejectCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	app.moderateComments(w, r, store.CommentStatusRejected)
+}
+
+func (app *application) moderateComments(w http.ResponseWriter, r *http.Request, status string) {
+	var payload ModerateCommentsPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if len(payload.IDs) == 0 || len(payload.IDs) > maxModerationBatch {
+		app.badRequestResponse(w, r, fmt.Errorf("ids must contain between 1 and %d comment IDs", maxModerationBatch))
+		return
+	}
+
+	updated, err := app.store.Comments.SetStatus(r.Context(), payload.IDs, status)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, ModerationResult{Updated: updated}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func parseModerationQuery(r *http.Request) (store.ModerationQuery, error) {
+	qs := r.URL.Query()
+
+	mq := store.ModerationQuery{
+		Status: store.CommentStatusPending,
+		Limit:  store.DefaultCommentLimit,
+	}
+
+	if status := qs.Get("status"); status != "" {
+		if !store.ValidCommentStatus(status) {
+			return mq, store.ErrInvalidCommentStatus
+		}
+		mq.Status = status
+	}
+
+	if limit := qs.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > store.MaxCommentLimit {
+			return mq, fmt.Errorf("limit must be a number between 1 and %d", store.MaxCommentLimit)
+		}
+		mq.Limit = l
+	}
+
+	if offset := qs.Get("offset"); offset != "" {
+		o, err := strconv.Atoi(offset)
+		if err != nil || o < 0 {
+			return mq, fmt.Errorf("offset must be a positive number")
+		}
+		mq.Offset = o
+	}
+
+	return mq, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/google/uuid"
+)
+
+func TestModerationHandlers(t *testing.T) {
+	app := newTestApplication(t)
+
+	moderator := &store.User{ID: uuid.New(), Role: store.Role{Name: "moderator", Level: 2}}
+	user := &store.User{ID: uuid.New(), Role: store.Role{Name: "user", Level: 1}}
+
+	tests := []struct {
+		name           string
+		user           *store.User
+		method         string
+		target         string
+		body           string
+		handler        http.HandlerFunc
+		expectedStatus int
+	}{
+		{name: "queue", user: moderator, method: http.MethodGet, target: "/admin/comments", handler: app.getModerationQueueHandler, expectedStatus: http.StatusOK},
+		{name: "queue of rejected comments", user: moderator, method: http.MethodGet, target: "/admin/comments?status=rejected&limit=5", handler: app.getModerationQueueHandler, expectedStatus: http.StatusOK},
+		{name: "invalid status", user: moderator, method: http.MethodGet, target: "/admin/comments?status=spam", handler: app.getModerationQueueHandler, expectedStatus: http.StatusBadRequest},
+		{name: "invalid limit", user: moderator, method: http.MethodGet, target: "/admin/comments?limit=101", handler: app.getModerationQueueHandler, expectedStatus: http.StatusBadRequest},
+		{name: "approve", user: moderator, method: http.MethodPost, target: "/admin/comments/approve", body: `{"ids":[1,2]}`, handler: app.approveCommentsHandler, expectedStatus: http.StatusOK},
+		{name: "reject without ids", user: moderator, method: http.MethodPost, target: "/admin/comments/reject", body: `{"ids":[]}`, handler: app.rejectCommentsHandler, expectedStatus: http.StatusBadRequest},
+		{name: "user", user: user, method: http.MethodGet, target: "/admin/comments", handler: app.getModerationQueueHandler, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			ctx := context.WithValue(req.Context(), userCTx, tt.user)
+
+			rr := executeRequest(req.WithContext(ctx), app.RequirePermission(store.PermCommentsModerate)(tt.handler))
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/slug"
+	"github.com/ITine-Tech/blog/internal/store"
+)
+
+// parseFeedQuery reads the pagination, sorting and filter parameters of a feed request.
+//
+// Supported query parameters:
+// - limit: number of posts per page (1-100, default 20)
+// - cursor: the next_cursor of the previous page
+// - sort: asc or desc (default desc), ordered by publish time
+// - tags: comma separated list of tag names or slugs
+// - tag_match: any or all (default any)
+// - author: user ID or username
+// - since, until: RFC 3339 timestamps bounding the publish time
+// - status: draft, scheduled, published or archived; only the owner and admins see unpublished posts
+func parseFeedQuery(r *http.Request) (store.PaginatedFeedQuery, error) {
+	qs := r.URL.Query()
+
+	fq := store.PaginatedFeedQuery{
+		Limit:    store.DefaultFeedLimit,
+		Sort:     store.SortDesc,
+		TagMatch: store.TagMatchAny,
+		Author:   strings.TrimSpace(qs.Get("author")),
+	}
+
+	if limit := qs.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > store.MaxFeedLimit {
+			return fq, fmt.Errorf("limit must be a number between 1 and %d", store.MaxFeedLimit)
+		}
+		fq.Limit = l
+	}
+
+	if sort := qs.Get("sort"); sort != "" {
+		if sort != store.SortAsc && sort != store.SortDesc {
+			return fq, fmt.Errorf("sort must be %q or %q", store.SortAsc, store.SortDesc)
+		}
+		fq.Sort = sort
+	}
+
+	if tags := qs.Get("tags"); tags != "" {
+		for _, tag := range strings.Split(tags, ",") {
+			if tag = slug.Make(tag); tag != "" {
+				fq.Tags = append(fq.Tags, tag)
+			}
+		}
+	}
+
+	if match := qs.Get("tag_match"); match != "" {
+		if match != store.TagMatchAny && match != store.TagMatchAll {
+			return fq, fmt.Errorf("tag_match must be %q or %q", store.TagMatchAny, store.TagMatchAll)
+		}
+		fq.TagMatch = match
+	}
+
+	for param, target := range map[string]**time.Time{"since": &fq.Since, "until": &fq.Until} {
+		value := qs.Get(param)
+		if value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fq, fmt.Errorf("%s must be an RFC 3339 timestamp", param)
+		}
+		*target = &t
+	}
+
+	if status := qs.Get("status"); status != "" {
+		switch status {
+		case store.PostStatusDraft, store.PostStatusScheduled, store.PostStatusPublished, store.PostStatusArchived:
+			fq.Status = status
+		default:
+			return fq, store.ErrInvalidStatus
+		}
+	}
+
+	if cursor := qs.Get("cursor"); cursor != "" {
+		c, err := store.DecodeFeedCursor(cursor)
+		if err != nil {
+			return fq, err
+		}
+		if c.Sort != fq.Sort {
+			return fq, fmt.Errorf("cursor was created for sort=%s", c.Sort)
+		}
+		fq.Cursor = c
+	}
+
+	return fq, nil
+}
+
+// parseUserQuery reads the pagination and filter parameters of a list of users.
+//
+// Supported query parameters:
+// - limit: number of users per page (1-100, default 20)
+// - offset: number of users to skip
+// - role: name of the role
+// - active: true or false
+// - since, until: RFC 3339 timestamps bounding the creation time
+func parseUserQuery(r *http.Request) (store.UserQuery, error) {
+	qs := r.URL.Query()
+
+	uq := store.UserQuery{
+		Limit: store.DefaultUserLimit,
+		Role:  strings.TrimSpace(qs.Get("role")),
+	}
+
+	if limit := qs.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > store.MaxUserLimit {
+			return uq, fmt.Errorf("limit must be a number between 1 and %d", store.MaxUserLimit)
+		}
+		uq.Limit = l
+	}
+
+	if offset := qs.Get("offset"); offset != "" {
+		o, err := strconv.Atoi(offset)
+		if err != nil || o < 0 {
+			return uq, fmt.Errorf("offset must be a positive number")
+		}
+		uq.Offset = o
+	}
+
+	if active := qs.Get("active"); active != "" {
+		a, err := strconv.ParseBool(active)
+		if err != nil {
+			return uq, fmt.Errorf("active must be true or false")
+		}
+		uq.Active = &a
+	}
+
+	for param, target := range map[string]**time.Time{"since": &uq.CreatedSince, "until": &uq.CreatedUntil} {
+		value := qs.Get(param)
+		if value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return uq, fmt.Errorf("%s must be an RFC 3339 timestamp", param)
+		}
+		*target = &t
+	}
+
+	return uq, nil
+}
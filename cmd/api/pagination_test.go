@@ -0,0 +1,120 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/store"
+)
+
+func Test_parseFeedQuery(t *testing.T) {
+	descCursor := store.FeedCursor{PublishedAt: time.Now(), ID: 7, Sort: store.SortDesc}.Encode()
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+		check   func(t *testing.T, fq store.PaginatedFeedQuery)
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			check: func(t *testing.T, fq store.PaginatedFeedQuery) {
+				if fq.Limit != store.DefaultFeedLimit || fq.Sort != store.SortDesc || fq.TagMatch != store.TagMatchAny {
+					t.Errorf("unexpected defaults %+v", fq)
+				}
+			},
+		},
+		{
+			name:  "filters",
+			query: "limit=5&sort=desc&tags=go,%20sql,&tag_match=all&author=gopher&since=2025-01-01T00:00:00Z&cursor=" + descCursor,
+			check: func(t *testing.T, fq store.PaginatedFeedQuery) {
+				if fq.Limit != 5 || len(fq.Tags) != 2 || fq.Tags[1] != "sql" || fq.TagMatch != store.TagMatchAll {
+					t.Errorf("unexpected query %+v", fq)
+				}
+				if fq.Author != "gopher" || fq.Since == nil || fq.Until != nil || fq.Cursor == nil || fq.Cursor.ID != 7 {
+					t.Errorf("unexpected query %+v", fq)
+				}
+			},
+		},
+		{name: "limit too large", query: "limit=1000", wantErr: true},
+		{name: "limit not a number", query: "limit=abc", wantErr: true},
+		{name: "invalid sort", query: "sort=up", wantErr: true},
+		{name: "invalid tag match", query: "tag_match=some", wantErr: true},
+		{name: "invalid since", query: "since=yesterday", wantErr: true},
+		{name: "invalid cursor", query: "cursor=abc", wantErr: true},
+		{name: "cursor of other sort order", query: "sort=asc&cursor=" + descCursor, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/feed?"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fq, err := parseFeedQuery(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFeedQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.check != nil {
+				tt.check(t, fq)
+			}
+		})
+	}
+}
+
+func Test_parseUserQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+		check   func(t *testing.T, uq store.UserQuery)
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			check: func(t *testing.T, uq store.UserQuery) {
+				if uq.Limit != store.DefaultUserLimit || uq.Offset != 0 || uq.Role != "" || uq.Active != nil {
+					t.Errorf("unexpected defaults %+v", uq)
+				}
+			},
+		},
+		{
+			name:  "filters",
+			query: "limit=5&offset=10&role=admin&active=false&since=2025-01-01T00:00:00Z",
+			check: func(t *testing.T, uq store.UserQuery) {
+				if uq.Limit != 5 || uq.Offset != 10 || uq.Role != "admin" || uq.Active == nil || *uq.Active {
+					t.Errorf("unexpected query %+v", uq)
+				}
+				if uq.CreatedSince == nil || uq.CreatedUntil != nil {
+					t.Errorf("unexpected query %+v", uq)
+				}
+			},
+		},
+		{name: "limit too large", query: "limit=1000", wantErr: true},
+		{name: "negative offset", query: "offset=-1", wantErr: true},
+		{name: "invalid active", query: "active=maybe", wantErr: true},
+		{name: "invalid until", query: "until=tomorrow", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/admin/users?"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			uq, err := parseUserQuery(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseUserQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.check != nil {
+				tt.check(t, uq)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/ITine-Tech/blog/internal/mailer"
+	"github.com/ITine-Tech/blog/internal/store"
+)
+
+type ForgotPasswordPayload struct {
+	Email string `json:"email" validate:"required,max=255"`
+}
+
+type ResetPasswordPayload struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=5,max=50"`
+}
+
+// forgotPasswordHandler godoc
+//
+// @Summary	Requests a password reset
+// @Description Sends a one-time password reset link to the email address. The response is the same whether or not an account with this email exists.
+// @Tags Authentication
+// @Accept	json
+// @Produce	json
+// @Param	payload body	ForgotPasswordPayload true "email"
+// @Success 202		{string} string	"Password reset requested"
+// @Failure	400		{object} error	"Bad Request"
+// @Failure 500		{object} error	"Internal Server Error"
+// @Router	/authentication/password/forgot [post]
+func (app *application) forgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var payload ForgotPasswordPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if payload.Email == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("email is required"))
+		return
+	}
+
+	resetToken := uuid.New().String()
+
+	user, err := app.store.Users.CreatePasswordReset(r.Context(), payload.Email, hashToken(resetToken), app.config.mail.passwordResetExp)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		// respond the same way as for existing accounts, so the email cannot be probed
+	case err != nil:
+		app.internalServerError(w, r, err)
+		return
+	default:
+		vars := struct {
+			Username  string
+			ResetURL  string
+			ExpiresIn string
+		}{
+			Username:  user.Username,
+			ResetURL:  fmt.Sprintf("%s/password/reset?token=%s", app.config.frontendURL, resetToken),
+			ExpiresIn: app.config.mail.passwordResetExp.String(),
+		}
+
+		// the mailer retries and sleeps in between, so sending in the background keeps the response
+		// time of existing accounts the same as that of unknown ones
+		go func() {
+			if err := app.mailer.Send(mailer.PasswordResetTemplate, user.Username, user.Email, vars); err != nil {
+				log.Printf("error sending password reset email: %s", err)
+			}
+		}()
+	}
+
+	if err := app.jsonResponse(w, http.StatusAccepted, "if an account with this email exists, a reset link has been sent"); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// resetPasswordHandler godoc
+//
+// @Summary	Resets a password
+// @Description Sets a new password using a one-time reset token. All existing sessions of the user are ended.
+// @Tags Authentication
+// @Accept	json
+// @Produce	json
+// @Param	payload body	ResetPasswordPayload true "token and new password"
+// @Success 204		{string} string	"Password reset"
+// @Failure	400		{object} error	"Bad Request"
+// @Failure 500		{object} error	"Internal Server Error"
+// @Router	/authentication/password/reset [post]
+func (app *application) resetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var payload ResetPasswordPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if payload.Token == "" || payload.Password == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("token and password are required"))
+		return
+	}
+
+	if len(payload.Password) < 5 || len(payload.Password) > 50 {
+		app.badRequestResponse(w, r, fmt.Errorf("password must be between 5 and 50 characters"))
+		return
+	}
+
+	if err := app.store.Users.ResetPassword(r.Context(), hashToken(payload.Token), payload.Password); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.badRequestResponse(w, r, fmt.Errorf("invalid or expired token"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -5,12 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ITine-Tech/blog/internal/store"
 
 	_ "github.com/ITine-Tech/blog/docs"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 type postKey string
@@ -21,22 +25,36 @@ type CreatePost struct {
 	Title string   `json:"title"`
 	Text  string   `json:"text"`
 	Tags  []string `json:"tags"`
+	// Status is draft, scheduled or published (default).
+	Status string `json:"status"`
+	// PublishAt is required for scheduled posts.
+	PublishAt *time.Time `json:"publish_at"`
+	// Moderation is the comment moderation policy of the post: open, first_time or all.
+	// Empty uses the global policy.
+	Moderation string `json:"moderation"`
 }
 
 type UpdatePostPayload struct {
-	Title *string `json:"title" //validate:"omitempty,max=100"`
-	Text  *string `json:"text" //validate:"omitempty,max=1000"`
+	Title     *string    `json:"title" //validate:"omitempty,max=100"`
+	Text      *string    `json:"text" //validate:"omitempty,max=1000"`
+	Status    *string    `json:"status"`
+	PublishAt *time.Time `json:"publish_at"`
+	// Moderation sets the comment moderation policy. An empty string falls back to the global policy.
+	Moderation *string `json:"moderation"`
 }
 
 // CreatePosts godoc
 //
 //	@Summary		Create a post
-//	@Description	Creates a new post
+//	@Description	Creates a new post. Posts are published right away unless they are saved as draft or scheduled.
+//	@Description	Tags are matched by slug, so "Go" is added to an existing "go" tag.
 //	@Tags			Posts
 //	@Accept			json
 //	@Produce		json
 //	@Param			payload body		CreatePost true	"postPayload"
 //	@Success		200		{object}	store.Post
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Suspended"
 //	@Failure		500		{object}	error	"Internal Server Error"
 //	@Security		ApiKeyAuth
 //	@Router			/posts [post]
@@ -53,6 +71,9 @@ func (app *application) CreatePostsHandler(w http.ResponseWriter, r *http.Reques
 	}
 
 	user := getUserFromCtx(r)
+	if app.refuseSuspended(w, r, user) {
+		return
+	}
 
 	post := &store.Post{
 		Title:  postPayload.Title,
@@ -61,10 +82,32 @@ func (app *application) CreatePostsHandler(w http.ResponseWriter, r *http.Reques
 		Tags:   postPayload.Tags,
 	}
 
+	status := postPayload.Status
+	if status == "" {
+		status = store.PostStatusPublished
+	}
+
+	if err := post.SetStatus(status, postPayload.PublishAt, time.Now()); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	moderation, err := moderationOverride(postPayload.Moderation)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	post.Moderation = moderation
+
 	ctx := r.Context()
 
 	if err := app.store.Posts.CreatePost(ctx, post); err != nil {
-		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		switch {
+		case errors.Is(err, store.ErrInvalidTag):
+			app.badRequestResponse(w, r, err)
+		default:
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
@@ -75,22 +118,56 @@ func (app *application) CreatePostsHandler(w http.ResponseWriter, r *http.Reques
 
 // GetAllPosts godoc
 //
-//	@Summary		Get all posts by ID
-//	@Description	Get all posts by ID
+//	@Summary		Get the feed
+//	@Description	Get a page of posts. Use next_cursor of the response as cursor to fetch the next page.
 //	@Tags			Feed
 //	@Accept			json
 //	@Produce		json
-//	@Success		200		{object}	store.Post
+//	@Param			limit		query		int		false	"Posts per page (1-100)"	default(20)
+//	@Param			cursor		query		string	false	"Cursor of the next page"
+//	@Param			sort		query		string	false	"Sort by publish time"	Enums(asc, desc)	default(desc)
+//	@Param			tags		query		string	false	"Comma separated list of tag names or slugs"
+//	@Param			tag_match	query		string	false	"Match any or all tags"	Enums(any, all)	default(any)
+//	@Param			author		query		string	false	"User ID or username of the author"
+//	@Param			since		query		string	false	"Only posts published at or after this RFC 3339 timestamp"
+//	@Param			until		query		string	false	"Only posts published before this RFC 3339 timestamp"
+//	@Param			status		query		string	false	"Only posts with this status. Unpublished posts are only visible to their author and admins."	Enums(draft, scheduled, published, archived)
+//	@Success		200		{object}	[]store.Post
+//	@Failure		400		{object}	error	"Bad Request"
 //	@Failure		500		{object}	error	"Internal Server Error"
 //	@Router			/feed [get]
 func (app *application) getAllPostsHandler(w http.ResponseWriter, r *http.Request) {
-	posts, err := app.store.Posts.GetAllPosts(r.Context())
+	fq, err := parseFeedQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	app.writeFeed(w, r, fq)
+}
+
+// writeFeed responds with the page of posts described by fq, as far as the user of the request may see them.
+func (app *application) writeFeed(w http.ResponseWriter, r *http.Request, fq store.PaginatedFeedQuery) {
+	ctx := r.Context()
+
+	if user := getUserFromCtx(r); user != nil {
+		fq.ViewerID = &user.ID
+
+		readAny, err := app.hasPermission(ctx, user, store.PermPostsReadAny)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		fq.IncludeUnpublished = readAny
+	}
+
+	posts, nextCursor, err := app.store.Posts.GetAllPosts(ctx, fq)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, posts); err != nil {
+	if err := app.paginatedJSONResponse(w, http.StatusOK, posts, nextCursor); err != nil {
 
 		app.internalServerError(w, r, err)
 	}
@@ -99,12 +176,17 @@ func (app *application) getAllPostsHandler(w http.ResponseWriter, r *http.Reques
 // GetPostByID godoc
 //
 //	@Summary		Get a post by ID
-//	@Description	Get a post by ID
+//	@Description	Get a post by ID. The ETag header carries the version of the post, send it as If-Match when updating the post.
 //	@Tags			Feed
 //	@Accept			json
 //	@Produce		json
 //	@Param postID path int true "Post ID" regexp(^[0-9]+$)
+//	@Param			comments_depth	query	int	false	"Levels of replies to load (1-10)"	default(3)
+//	@Param			comments_limit	query	int	false	"Comments per level and parent (1-100)"	default(20)
+//	@Param			comments_offset	query	int	false	"Top-level comments to skip"	default(0)
+//	@Param			comments_parent	query	int	false	"Load the replies of this comment instead of the top-level comments"
 //	@Success		200		{object}	store.Post
+//	@Failure		400		{object}	error	"Bad Request"
 //	@Failure		404		{object}	error	"Not found"
 //	@Failure		500		{object}	error	"Internal Server Error"
 //	@Router			/feed/{postID} [get]
@@ -127,9 +209,87 @@ func (app *application) getPostByIDHandler(w http.ResponseWriter, r *http.Reques
 		default:
 			app.internalServerError(w, r, err)
 		}
+		return
+	}
+
+	app.writePost(w, r, post)
+}
+
+// GetPostBySlug godoc
+//
+//	@Summary		Get a post by slug
+//	@Description	Get a post by its slug. Former slugs of a post redirect to its current slug with 301 Moved Permanently.
+//	@Description	The ETag header carries the version of the post, send it as If-Match when updating the post.
+//	@Tags			Feed
+//	@Produce		json
+//	@Param			slug			path	string	true	"Post slug"
+//	@Param			comments_depth	query	int	false	"Levels of replies to load (1-10)"	default(3)
+//	@Param			comments_limit	query	int	false	"Comments per level and parent (1-100)"	default(20)
+//	@Param			comments_offset	query	int	false	"Top-level comments to skip"	default(0)
+//	@Param			comments_parent	query	int	false	"Load the replies of this comment instead of the top-level comments"
+//	@Success		200		{object}	store.Post
+//	@Success		301		"Moved Permanently"
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Router			/feed/by-slug/{slug} [get]
+func (app *application) getPostBySlugHandler(w http.ResponseWriter, r *http.Request) {
+	postSlug := strings.ToLower(chi.URLParam(r, "slug"))
+	ctx := r.Context()
+
+	post, err := app.store.Posts.GetPostBySlug(ctx, postSlug)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if post.Slug != postSlug {
+		// do not reveal the new slug of posts the user cannot see
+		visible, err := app.canViewPost(ctx, getUserFromCtx(r), post)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if !visible {
+			app.notFoundResponse(w, r, fmt.Errorf("post %d is %s", post.ID, post.Status))
+			return
+		}
+
+		target := url.URL{Path: "/feed/by-slug/" + post.Slug, RawQuery: r.URL.RawQuery}
+		http.Redirect(w, r, target.String(), http.StatusMovedPermanently)
+		return
 	}
 
-	comments, err := app.store.Comments.GetByPostID(ctx, id)
+	app.writePost(w, r, post)
+}
+
+// writePost responds with the post, its reactions and a page of its comments, if the user of the request may see it.
+func (app *application) writePost(w http.ResponseWriter, r *http.Request, post *store.Post) {
+	ctx := r.Context()
+	user := getUserFromCtx(r)
+
+	visible, err := app.canViewPost(ctx, user, post)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !visible {
+		app.notFoundResponse(w, r, fmt.Errorf("post %d is %s", post.ID, post.Status))
+		return
+	}
+
+	cq, err := parseCommentTreeQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	comments, err := app.store.Comments.GetByPostID(ctx, post.ID, cq)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -137,6 +297,20 @@ func (app *application) getPostByIDHandler(w http.ResponseWriter, r *http.Reques
 
 	post.Comments = comments
 
+	var viewerID *uuid.UUID
+	if user != nil {
+		viewerID = &user.ID
+	}
+	if err := app.store.Reactions.Load(ctx, []*store.Post{post}, viewerID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.store.Bookmarks.Load(ctx, []*store.Post{post}, viewerID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(post.Version))
 	if err := app.jsonResponse(w, http.StatusOK, post); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -146,20 +320,29 @@ func (app *application) getPostByIDHandler(w http.ResponseWriter, r *http.Reques
 // UpdatePostByID godoc
 //
 //	@Summary		Updates a post by ID
-//	@Description	Updates a post by ID
+//	@Description	Updates a post by ID. Changing the status publishes, schedules, unpublishes (draft) or archives the post.
+//	@Description	The If-Match header has to contain the ETag of the version that was edited.
 //	@Tags			Posts
 //	@Accept			json
 //	@Produce		json
 //	@Param postID path int true "Post ID" regexp(^[0-9]+$)
+//	@Param			If-Match	header	string	true	"ETag of the edited version"
 //	@Param			payload body		UpdatePostPayload true	"payload"
 //	@Success		200		{object}	store.Post
 //	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		409		{object}	error	"Conflict"
+//	@Failure		412		{object}	error	"Precondition Failed"
+//	@Failure		428		{object}	error	"Precondition Required"
 //	@Failure		500		{object}	error	"Internal Server Error"
 //	@Security		ApiKeyAuth
 //	@Router			/posts/{postID} [patch]
 func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request) {
 	post := getPostFromCtx(r)
 
+	if !app.checkIfMatch(w, r, post.Version) {
+		return
+	}
+
 	var payload UpdatePostPayload
 	if err := readJSON(w, r, &payload); err != nil {
 		app.badRequestResponse(w, r, err)
@@ -172,12 +355,39 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 	if payload.Text != nil {
 		post.Text = *payload.Text
 	}
+	if payload.Status != nil || payload.PublishAt != nil {
+		status := post.Status
+		if payload.Status != nil {
+			status = *payload.Status
+		}
+
+		if err := post.SetStatus(status, payload.PublishAt, time.Now()); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+	if payload.Moderation != nil {
+		moderation, err := moderationOverride(*payload.Moderation)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		post.Moderation = moderation
+	}
 
 	if err := app.store.Posts.UpdatePost(r.Context(), post); err != nil {
-		app.internalServerError(w, r, err)
+		switch {
+		case errors.Is(err, store.ErrConflict):
+			app.conflictResponse(w, r, err)
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
 		return
 	}
 
+	w.Header().Set("ETag", etag(post.Version))
 	if err := app.jsonResponse(w, http.StatusOK, post); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -219,6 +429,17 @@ func (app *application) DeletePostHandler(w http.ResponseWriter, r *http.Request
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// moderationOverride validates the moderation policy of a post payload. An empty policy removes the override.
+func moderationOverride(policy string) (*string, error) {
+	if policy == "" {
+		return nil, nil
+	}
+	if !store.ValidModeration(policy) {
+		return nil, store.ErrInvalidModeration
+	}
+	return &policy, nil
+}
+
 func (app *application) PostsContextMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		strID := chi.URLParam(r, "postID")
@@ -247,6 +468,24 @@ func (app *application) PostsContextMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// canViewPost reports whether user may see post. Published posts are public,
+// all other posts are only visible to their author and users with the posts:read:any permission. user is nil for anonymous requests.
+func (app *application) canViewPost(ctx context.Context, user *store.User, post *store.Post) (bool, error) {
+	if post.IsPublished() {
+		return true, nil
+	}
+
+	if user == nil {
+		return false, nil
+	}
+
+	if post.UserID == user.ID {
+		return true, nil
+	}
+
+	return app.hasPermission(ctx, user, store.PermPostsReadAny)
+}
+
 func getPostFromCtx(r *http.Request) *store.Post {
 	post, _ := r.Context().Value(postCtx).(*store.Post)
 	return post
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ITine-Tech/blog/internal/store"
+)
+
+func TestGetPostBySlugHandler(t *testing.T) {
+	app := newTestApplication(t)
+	app.store.Posts = &store.MockPostStore{
+		Posts: []*store.Post{
+			{ID: 1, Slug: "hello-world", Status: store.PostStatusPublished},
+			{ID: 2, Slug: "secret-plans", Status: store.PostStatusDraft},
+		},
+		FormerSlugs: map[string]int64{"hello": 1, "plans": 2},
+	}
+	mux := app.mount()
+
+	tests := []struct {
+		name             string
+		target           string
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{name: "current slug", target: "/feed/by-slug/hello-world", expectedStatus: http.StatusOK},
+		{name: "current slug in upper case", target: "/feed/by-slug/Hello-World", expectedStatus: http.StatusOK},
+		{name: "former slug", target: "/feed/by-slug/hello?comments_depth=1", expectedStatus: http.StatusMovedPermanently, expectedLocation: "/feed/by-slug/hello-world?comments_depth=1"},
+		{name: "former slug of a draft", target: "/feed/by-slug/plans", expectedStatus: http.StatusNotFound},
+		{name: "draft", target: "/feed/by-slug/secret-plans", expectedStatus: http.StatusNotFound},
+		{name: "unknown slug", target: "/feed/by-slug/nothing", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.target, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+			if location := rr.Header().Get("Location"); location != tt.expectedLocation {
+				t.Errorf("expected location %q, got %q", tt.expectedLocation, location)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// defaultReactionKinds are used unless REACTIONS configures others.
+var defaultReactionKinds = []string{"like", "love", "laugh", "wow", "sad"}
+
+var reactionKind = regexp.MustCompile(`^[a-z0-9_-]{1,20}$`)
+
+// parseReactionKinds reads a comma separated list of reaction kinds. An empty list means the default kinds.
+func parseReactionKinds(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return defaultReactionKinds, nil
+	}
+
+	var kinds []string
+	for _, kind := range strings.Split(s, ",") {
+		kind = strings.TrimSpace(kind)
+		if !reactionKind.MatchString(kind) {
+			return nil, fmt.Errorf("invalid reaction %q: reactions are 1 to 20 lowercase letters, digits, - or _", kind)
+		}
+		if !slices.Contains(kinds, kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, nil
+}
+
+// AddReaction godoc
+//
+//	@Summary		React to a post
+//	@Description	Adds a reaction of the authenticated user to the post. Reacting twice with the same kind has no effect.
+//	@Description	The counts are returned with the post in reactions, the kinds of the user in my_reactions.
+//	@Tags			Posts
+//	@Param			postID	path	int		true	"Post ID"	regexp(^[0-9]+$)
+//	@Param			kind	path	string	true	"Kind of reaction, e.g. like"
+//	@Success		204
+//	@Failure		400	{object}	error	"Unknown kind of reaction"
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/reactions/{kind} [put]
+func (app *application) addReactionHandler(w http.ResponseWriter, r *http.Request) {
+	app.setReaction(w, r, true)
+}
+
+// RemoveReaction godoc
+//
+//	@Summary		Take back a reaction
+//	@Description	Removes a reaction of the authenticated user from the post. Removing a reaction that does not exist has no effect.
+//	@Tags			Posts
+//	@Param			postID	path	int		true	"Post ID"	regexp(^[0-9]+$)
+//	@Param			kind	path	string	true	"Kind of reaction, e.g. like"
+//	@Success		204
+//	@Failure		400	{object}	error	"Unknown kind of reaction"
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/reactions/{kind} [delete]
+func (app *application) removeReactionHandler(w http.ResponseWriter, r *http.Request) {
+	app.setReaction(w, r, false)
+}
+
+func (app *application) setReaction(w http.ResponseWriter, r *http.Request, add bool) {
+	post := getPostFromCtx(r)
+	user := getUserFromCtx(r)
+	ctx := r.Context()
+
+	kind := chi.URLParam(r, "kind")
+	if !slices.Contains(app.config.reactions.kinds, kind) {
+		app.badRequestResponse(w, r, fmt.Errorf("unknown reaction %q, use one of %s", kind, strings.Join(app.config.reactions.kinds, ", ")))
+		return
+	}
+
+	visible, err := app.canViewPost(ctx, user, post)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !visible {
+		app.notFoundResponse(w, r, fmt.Errorf("post %d is %s", post.ID, post.Status))
+		return
+	}
+
+	if add {
+		err = app.store.Reactions.Add(ctx, post.ID, user.ID, kind)
+	} else {
+		err = app.store.Reactions.Remove(ctx, post.ID, user.ID, kind)
+	}
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
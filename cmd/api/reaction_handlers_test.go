@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func TestReactionHandlers(t *testing.T) {
+	app := newTestApplication(t)
+	reactions := app.store.Reactions.(*store.MockReactionStore)
+
+	published := &store.Post{ID: 1, UserID: uuid.New(), Status: store.PostStatusPublished}
+	draft := &store.Post{ID: 2, UserID: uuid.New(), Status: store.PostStatusDraft}
+	user := &store.User{ID: uuid.New(), Role: store.Role{Name: "user", Level: 1}}
+
+	tests := []struct {
+		name           string
+		method         string
+		post           *store.Post
+		kind           string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{name: "react", method: http.MethodPut, post: published, kind: "like", expectedStatus: http.StatusNoContent, expectedCount: 1},
+		{name: "react again", method: http.MethodPut, post: published, kind: "like", expectedStatus: http.StatusNoContent, expectedCount: 1},
+		{name: "unknown kind", method: http.MethodPut, post: published, kind: "hate", expectedStatus: http.StatusBadRequest, expectedCount: 1},
+		{name: "draft of another user", method: http.MethodPut, post: draft, kind: "like", expectedStatus: http.StatusNotFound},
+		{name: "take back", method: http.MethodDelete, post: published, kind: "like", expectedStatus: http.StatusNoContent, expectedCount: 0},
+		{name: "take back again", method: http.MethodDelete, post: published, kind: "like", expectedStatus: http.StatusNoContent, expectedCount: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/posts/1/reactions/"+tt.kind, nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("kind", tt.kind)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, userCTx, user)
+			ctx = context.WithValue(ctx, postCtx, tt.post)
+
+			handler := app.addReactionHandler
+			if tt.method == http.MethodDelete {
+				handler = app.removeReactionHandler
+			}
+			rr := executeRequest(req.WithContext(ctx), http.HandlerFunc(handler))
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+			if count := len(reactions.Reactions[tt.post.ID]["like"]); count != tt.expectedCount {
+				t.Errorf("expected %d likes, got %d", tt.expectedCount, count)
+			}
+		})
+	}
+}
+
+func TestGetPostHandler_Reactions(t *testing.T) {
+	app := newTestApplication(t)
+	app.store.Posts = &store.MockPostStore{
+		Posts: []*store.Post{{ID: 1, Slug: "hello-world", Status: store.PostStatusPublished}},
+	}
+
+	viewer := uuid.New()
+	app.store.Reactions.Add(context.Background(), 1, viewer, "like")
+	app.store.Reactions.Add(context.Background(), 1, uuid.New(), "like")
+	app.store.Reactions.Add(context.Background(), 1, uuid.New(), "wow")
+
+	tests := []struct {
+		name                string
+		user                *store.User
+		expectedMyReactions []string
+	}{
+		{name: "anonymous"},
+		{name: "reacted", user: &store.User{ID: viewer}, expectedMyReactions: []string{"like"}},
+		{name: "not reacted", user: &store.User{ID: uuid.New()}, expectedMyReactions: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/feed/by-slug/hello-world", nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("slug", "hello-world")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			if tt.user != nil {
+				ctx = context.WithValue(ctx, userCTx, tt.user)
+			}
+
+			rr := executeRequest(req.WithContext(ctx), http.HandlerFunc(app.getPostBySlugHandler))
+			checkResponseCode(t, http.StatusOK, rr.Code)
+
+			var resp struct {
+				Data map[string]json.RawMessage `json:"data"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+
+			var counts map[string]int
+			if err := json.Unmarshal(resp.Data["reactions"], &counts); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(counts, map[string]int{"like": 2, "wow": 1}) {
+				t.Errorf("unexpected reactions %v", counts)
+			}
+
+			raw, ok := resp.Data["my_reactions"]
+			if tt.expectedMyReactions == nil {
+				if ok {
+					t.Errorf("expected no my_reactions for anonymous requests, got %s", raw)
+				}
+				return
+			}
+
+			var mine []string
+			if err := json.Unmarshal(raw, &mine); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(mine, tt.expectedMyReactions) {
+				t.Errorf("expected my_reactions %v, got %v", tt.expectedMyReactions, mine)
+			}
+		})
+	}
+}
+
+func TestParseReactionKinds(t *testing.T) {
+	kinds, err := parseReactionKinds(" like, heart ,like")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(kinds, []string{"like", "heart"}) {
+		t.Errorf("unexpected kinds %v", kinds)
+	}
+
+	if kinds, _ := parseReactionKinds(""); !reflect.DeepEqual(kinds, defaultReactionKinds) {
+		t.Errorf("expected the default kinds, got %v", kinds)
+	}
+
+	for _, invalid := range []string{"like,,love", "Like", "thumbs up"} {
+		if _, err := parseReactionKinds(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
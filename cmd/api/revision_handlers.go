@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ITine-Tech/blog/internal/diff"
+	"github.com/ITine-Tech/blog/internal/store"
+)
+
+type RevisionDiff struct {
+	PostID      int64       `json:"post_id"`
+	FromVersion int         `json:"from_version"`
+	ToVersion   int         `json:"to_version"`
+	Title       []diff.Line `json:"title"`
+	Text        []diff.Line `json:"text"`
+	Unified     string      `json:"unified"`
+}
+
+// GetPostRevisions godoc
+//
+//	@Summary		Lists the revisions of a post
+//	@Description	Lists all previous versions of a post, newest first. Only the author and users allowed to edit any post can see the revisions.
+//	@Tags			Revisions
+//	@Produce		json
+//	@Param			postID	path		int	true	"Post ID"	regexp(^[0-9]+$)
+//	@Success		200		{object}	[]store.PostRevision
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/revisions [get]
+func (app *application) getPostRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+
+	revisions, err := app.store.Posts.GetRevisions(r.Context(), post.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, revisions); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetPostRevision godoc
+//
+//	@Summary		Gets a revision of a post
+//	@Description	Gets the title and text of a post at the given version. Only the author and users allowed to edit any post can see the revisions.
+//	@Tags			Revisions
+//	@Produce		json
+//	@Param			postID	path		int	true	"Post ID"	regexp(^[0-9]+$)
+//	@Param			version	path		int	true	"Version"	regexp(^[0-9]+$)
+//	@Success		200		{object}	store.PostRevision
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/revisions/{version} [get]
+func (app *application) getPostRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	revision, err := app.getRevision(r.Context(), getPostFromCtx(r), version)
+	if err != nil {
+		app.revisionErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, revision); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DiffPostRevisions godoc
+//
+//	@Summary		Compares two revisions of a post
+//	@Description	Returns a line based diff of the title and text between two versions of a post. Only the author and users allowed to edit any post can see the revisions.
+//	@Tags			Revisions
+//	@Produce		json
+//	@Param			postID	path		int	true	"Post ID"	regexp(^[0-9]+$)
+//	@Param			from	query		int	true	"Old version"
+//	@Param			to		query		int	false	"New version, defaults to the current version"
+//	@Success		200		{object}	RevisionDiff
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/revisions/diff [get]
+func (app *application) diffPostRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("from must be a version number"))
+		return
+	}
+
+	to := post.Version
+	if param := r.URL.Query().Get("to"); param != "" {
+		to, err = strconv.Atoi(param)
+		if err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("to must be a version number"))
+			return
+		}
+	}
+
+	ctx := r.Context()
+
+	oldRevision, err := app.getRevision(ctx, post, from)
+	if err != nil {
+		app.revisionErrorResponse(w, r, err)
+		return
+	}
+
+	newRevision, err := app.getRevision(ctx, post, to)
+	if err != nil {
+		app.revisionErrorResponse(w, r, err)
+		return
+	}
+
+	textDiff := diff.Lines(oldRevision.Text, newRevision.Text)
+
+	result := RevisionDiff{
+		PostID:      post.ID,
+		FromVersion: from,
+		ToVersion:   to,
+		Title:       diff.Lines(oldRevision.Title, newRevision.Title),
+		Text:        textDiff,
+		Unified:     diff.Unified(textDiff),
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, result); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// RestorePostRevision godoc
+//
+//	@Summary		Restores a revision of a post
+//	@Description	Restores the title and text of a post from the given version. The restore is saved as a new version.
+//	@Tags			Revisions
+//	@Produce		json
+//	@Param			postID	path		int	true	"Post ID"	regexp(^[0-9]+$)
+//	@Param			version	path		int	true	"Version"	regexp(^[0-9]+$)
+//	@Success		200		{object}	store.Post
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		409		{object}	error	"Conflict"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/revisions/{version}/restore [post]
+func (app *application) restorePostRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if version == post.Version {
+		app.badRequestResponse(w, r, fmt.Errorf("version %d is the current version", version))
+		return
+	}
+
+	ctx := r.Context()
+
+	revision, err := app.store.Posts.GetRevision(ctx, post.ID, version)
+	if err != nil {
+		app.revisionErrorResponse(w, r, err)
+		return
+	}
+
+	post.Title = revision.Title
+	post.Text = revision.Text
+
+	if err := app.store.Posts.UpdatePost(ctx, post); err != nil {
+		switch {
+		case errors.Is(err, store.ErrConflict):
+			app.conflictResponse(w, r, err)
+		default:
+			app.revisionErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	w.Header().Set("ETag", etag(post.Version))
+	if err := app.jsonResponse(w, http.StatusOK, post); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// getRevision returns the post at the given version. The current version is taken from the post itself.
+func (app *application) getRevision(ctx context.Context, post *store.Post, version int) (*store.PostRevision, error) {
+	if version == post.Version {
+		return &store.PostRevision{
+			PostID:    post.ID,
+			Version:   post.Version,
+			Title:     post.Title,
+			Text:      post.Text,
+			CreatedAt: post.UpdatedAt,
+		}, nil
+	}
+
+	return app.store.Posts.GetRevision(ctx, post.ID, version)
+}
+
+func (app *application) revisionErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		app.notFoundResponse(w, r, err)
+	default:
+		app.internalServerError(w, r, err)
+	}
+}
+
+// postVisibilityMiddleware responds with 404 if the post in the context is not visible to the user.
+func (app *application) postVisibilityMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		post := getPostFromCtx(r)
+
+		visible, err := app.canViewPost(r.Context(), getUserFromCtx(r), post)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if !visible {
+			app.notFoundResponse(w, r, fmt.Errorf("post %d is %s", post.ID, post.Status))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
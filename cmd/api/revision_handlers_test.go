@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestRevisionHandlers_Ownership(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	author := &store.User{ID: uuid.New(), Username: "author", Role: store.Role{ID: 1, Name: "user", Level: 1}, IsActive: true}
+	other := &store.User{ID: uuid.New(), Username: "other", Role: store.Role{ID: 1, Name: "user", Level: 1}, IsActive: true}
+	admin := &store.User{ID: uuid.New(), Username: "admin", Role: store.Role{ID: 3, Name: "admin", Level: 3}, IsActive: true}
+	app.store.Users = &store.MockUserStore{Users: []*store.User{author, other, admin}}
+
+	now := time.Now()
+	app.store.Posts = &store.MockPostStore{Posts: []*store.Post{
+		{ID: 1, Title: "Title", Text: "Text", UserID: author.ID, Status: store.PostStatusPublished, PublishedAt: &now, Version: 1},
+	}}
+
+	token := func(user *store.User) string {
+		t.Helper()
+
+		token, err := app.authenticator.GenerateToken(jwt.MapClaims{
+			"sub": user.ID.String(),
+			"jti": uuid.NewString(),
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name           string
+		principal      *store.User
+		target         string
+		expectedStatus int
+	}{
+		{name: "list as the author", principal: author, target: "/posts/1/revisions", expectedStatus: http.StatusOK},
+		{name: "list as another user", principal: other, target: "/posts/1/revisions", expectedStatus: http.StatusForbidden},
+		{name: "list as an admin", principal: admin, target: "/posts/1/revisions", expectedStatus: http.StatusOK},
+		{name: "get as the author", principal: author, target: "/posts/1/revisions/1", expectedStatus: http.StatusOK},
+		{name: "get as another user", principal: other, target: "/posts/1/revisions/1", expectedStatus: http.StatusForbidden},
+		{name: "diff as the author", principal: author, target: "/posts/1/revisions/diff?from=1", expectedStatus: http.StatusOK},
+		{name: "diff as another user", principal: other, target: "/posts/1/revisions/diff?from=1", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.target, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token(tt.principal))
+
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+type roleKey string
+
+const roleCtx roleKey = "role"
+
+var roleName = regexp.MustCompile(`^[a-z0-9_-]{1,50}$`)
+
+type CreateRolePayload struct {
+	Name              string   `json:"name"`
+	Level             int      `json:"level"`
+	Description       string   `json:"description"`
+	RequiresTwoFactor bool     `json:"requires_two_factor"`
+	Permissions       []string `json:"permissions"`
+}
+
+type UpdateRolePayload struct {
+	Name              *string `json:"name"`
+	Level             *int    `json:"level"`
+	Description       *string `json:"description"`
+	RequiresTwoFactor *bool   `json:"requires_two_factor"`
+}
+
+type RolePermissionsPayload struct {
+	Permissions []string `json:"permissions"`
+}
+
+// validateRole checks the name and level of a role.
+func validateRole(role *store.Role) error {
+	if !roleName.MatchString(role.Name) {
+		return fmt.Errorf("name must be 1 to 50 lowercase letters, digits, - or _")
+	}
+	if role.Level < 1 {
+		return fmt.Errorf("level must be at least 1")
+	}
+	return nil
+}
+
+// GetRoles godoc
+//
+//	@Summary		List roles
+//	@Description	Lists all roles with their permissions, ordered by level. Requires the roles:manage permission.
+//	@Tags			Admin
+//	@Produce		json
+//	@Success		200	{object}	[]store.Role
+//	@Failure		403	{object}	error	"Forbidden"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/roles [get]
+func (app *application) getRolesHandler(w http.ResponseWriter, r *http.Request) {
+	roles, err := app.store.Roles.GetAll(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, roles); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// CreateRole godoc
+//
+//	@Summary		Create a role
+//	@Description	Creates a role with permissions. A role without permissions falls back to its level: its users may do what
+//	@Description	the built-in roles up to that level may do. Users of a role that requires two-factor authentication have to
+//	@Description	enable it before they can use the API. Requires the roles:manage permission.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		CreateRolePayload	true	"Role"
+//	@Success		201		{object}	store.Role
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		409		{object}	error	"Conflict"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/roles [post]
+func (app *application) createRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var payload CreateRolePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	role := &store.Role{
+		Name:              payload.Name,
+		Level:             payload.Level,
+		Description:       payload.Description,
+		RequiresTwoFactor: payload.RequiresTwoFactor,
+		Permissions:       payload.Permissions,
+	}
+	if err := validateRole(role); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Roles.Create(r.Context(), role); err != nil {
+		switch {
+		case errors.Is(err, store.ErrConflict):
+			app.conflictResponse(w, r, fmt.Errorf("a role named %q already exists", role.Name))
+		case errors.Is(err, store.ErrUnknownPermission):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.writeRole(w, r, http.StatusCreated, role.ID)
+}
+
+// GetRole godoc
+//
+//	@Summary		Get a role
+//	@Description	Gets a role with its permissions. Requires the roles:manage permission.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			roleID	path		int	true	"Role ID"
+//	@Success		200		{object}	store.Role
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/roles/{roleID} [get]
+func (app *application) getRoleHandler(w http.ResponseWriter, r *http.Request) {
+	if err := app.jsonResponse(w, http.StatusOK, getRoleFromCtx(r)); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// UpdateRole godoc
+//
+//	@Summary		Update a role
+//	@Description	Updates the name, level, description or two-factor requirement of a role. Requires the roles:manage permission.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			roleID	path		int					true	"Role ID"
+//	@Param			payload	body		UpdateRolePayload	true	"Changes"
+//	@Success		200		{object}	store.Role
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		409		{object}	error	"Conflict"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/roles/{roleID} [patch]
+func (app *application) updateRoleHandler(w http.ResponseWriter, r *http.Request) {
+	role := getRoleFromCtx(r)
+
+	var payload UpdateRolePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if payload.Name != nil {
+		role.Name = *payload.Name
+	}
+	if payload.Level != nil {
+		role.Level = *payload.Level
+	}
+	if payload.Description != nil {
+		role.Description = *payload.Description
+	}
+	if payload.RequiresTwoFactor != nil {
+		role.RequiresTwoFactor = *payload.RequiresTwoFactor
+	}
+	if err := validateRole(role); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Roles.Update(r.Context(), role); err != nil {
+		switch {
+		case errors.Is(err, store.ErrConflict):
+			app.conflictResponse(w, r, fmt.Errorf("a role named %q already exists", role.Name))
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.writeRole(w, r, http.StatusOK, role.ID)
+}
+
+// SetRolePermissions godoc
+//
+//	@Summary		Set the permissions of a role
+//	@Description	Replaces the permissions of a role. An empty list lets the role fall back to its level.
+//	@Description	Requires the roles:manage permission.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			roleID	path		int						true	"Role ID"
+//	@Param			payload	body		RolePermissionsPayload	true	"Permissions"
+//	@Success		200		{object}	store.Role
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/roles/{roleID}/permissions [put]
+func (app *application) setRolePermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	role := getRoleFromCtx(r)
+
+	var payload RolePermissionsPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Roles.SetPermissions(r.Context(), role.ID, payload.Permissions); err != nil {
+		switch {
+		case errors.Is(err, store.ErrUnknownPermission):
+			app.badRequestResponse(w, r, err)
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.writeRole(w, r, http.StatusOK, role.ID)
+}
+
+// writeRole responds with the role as it is stored now.
+func (app *application) writeRole(w http.ResponseWriter, r *http.Request, status int, id int) {
+	role, err := app.store.Roles.GetByID(r.Context(), id)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, status, role); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetPermissions godoc
+//
+//	@Summary		List permissions
+//	@Description	Lists all permissions that can be assigned to roles. Requires the roles:manage permission.
+//	@Tags			Admin
+//	@Produce		json
+//	@Success		200	{object}	[]store.Permission
+//	@Failure		403	{object}	error	"Forbidden"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/permissions [get]
+func (app *application) getPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	permissions, err := app.store.Roles.GetAllPermissions(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, permissions); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) roleContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		roleID, err := strconv.Atoi(chi.URLParam(r, "roleID"))
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+
+		role, err := app.store.Roles.GetByID(ctx, roleID)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				app.notFoundResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+
+		ctx = context.WithValue(ctx, roleCtx, role)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getRoleFromCtx(r *http.Request) *store.Role {
+	role, _ := r.Context().Value(roleCtx).(*store.Role)
+	return role
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func TestRoleHandlers(t *testing.T) {
+	app := newTestApplication(t)
+	mux := chi.NewRouter()
+	mux.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			admin := &store.User{ID: uuid.New(), Role: store.Role{ID: 3, Name: "admin", Level: 3}}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userCTx, admin)))
+		})
+	})
+	mux.Use(app.RequirePermission(store.PermRolesManage))
+	mux.Get("/admin/roles", app.getRolesHandler)
+	mux.Post("/admin/roles", app.createRoleHandler)
+	mux.Route("/admin/roles/{roleID}", func(r chi.Router) {
+		r.Use(app.roleContextMiddleware)
+		r.Get("/", app.getRoleHandler)
+		r.Patch("/", app.updateRoleHandler)
+		r.Put("/permissions", app.setRolePermissionsHandler)
+	})
+
+	tests := []struct {
+		name                string
+		method              string
+		target              string
+		body                string
+		expectedStatus      int
+		expectedPermissions []string
+	}{
+		{name: "list", method: http.MethodGet, target: "/admin/roles", expectedStatus: http.StatusOK},
+		{name: "create", method: http.MethodPost, target: "/admin/roles", body: `{"name":"editor","level":1,"permissions":["tags:manage","posts:update:any"]}`, expectedStatus: http.StatusCreated, expectedPermissions: []string{"posts:update:any", "tags:manage"}},
+		{name: "create a duplicate", method: http.MethodPost, target: "/admin/roles", body: `{"name":"editor","level":1}`, expectedStatus: http.StatusConflict},
+		{name: "create with an unknown permission", method: http.MethodPost, target: "/admin/roles", body: `{"name":"owner","level":4,"permissions":["everything"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "create with an invalid name", method: http.MethodPost, target: "/admin/roles", body: `{"name":"Chief Editor","level":1}`, expectedStatus: http.StatusBadRequest},
+		{name: "create with an invalid level", method: http.MethodPost, target: "/admin/roles", body: `{"name":"guest","level":0}`, expectedStatus: http.StatusBadRequest},
+		{name: "get", method: http.MethodGet, target: "/admin/roles/4", expectedStatus: http.StatusOK, expectedPermissions: []string{"posts:update:any", "tags:manage"}},
+		{name: "get a missing role", method: http.MethodGet, target: "/admin/roles/99", expectedStatus: http.StatusNotFound},
+		{name: "rename", method: http.MethodPatch, target: "/admin/roles/4", body: `{"name":"chief-editor"}`, expectedStatus: http.StatusOK, expectedPermissions: []string{"posts:update:any", "tags:manage"}},
+		{name: "require two-factor authentication", method: http.MethodPatch, target: "/admin/roles/4", body: `{"requires_two_factor":true}`, expectedStatus: http.StatusOK, expectedPermissions: []string{"posts:update:any", "tags:manage"}},
+		{name: "rename to an existing name", method: http.MethodPatch, target: "/admin/roles/4", body: `{"name":"admin"}`, expectedStatus: http.StatusConflict},
+		{name: "set permissions", method: http.MethodPut, target: "/admin/roles/2/permissions", body: `{"permissions":["comments:moderate","comments:delete:any"]}`, expectedStatus: http.StatusOK, expectedPermissions: []string{"comments:delete:any", "comments:moderate"}},
+		{name: "set an unknown permission", method: http.MethodPut, target: "/admin/roles/2/permissions", body: `{"permissions":["comments:read"]}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+			if tt.expectedPermissions == nil {
+				return
+			}
+
+			var resp struct {
+				Data store.Role `json:"data"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(resp.Data.Permissions, tt.expectedPermissions) {
+				t.Errorf("expected permissions %v, got %v", tt.expectedPermissions, resp.Data.Permissions)
+			}
+		})
+	}
+
+	role, err := app.store.Roles.GetByID(context.Background(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !role.RequiresTwoFactor || role.Name != "chief-editor" {
+		t.Errorf("expected chief-editor to require two-factor authentication, got %+v", role)
+	}
+}
+
+func TestRoleHandlers_Forbidden(t *testing.T) {
+	app := newTestApplication(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/roles", nil)
+	ctx := context.WithValue(req.Context(), userCTx, &store.User{ID: uuid.New(), Role: store.Role{ID: 2, Name: "moderator", Level: 2}})
+
+	rr := executeRequest(req.WithContext(ctx), app.RequirePermission(store.PermRolesManage)(http.HandlerFunc(app.getRolesHandler)))
+
+	checkResponseCode(t, http.StatusForbidden, rr.Code)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// publishScheduledPosts publishes every scheduled post that is due, checking once per interval
+// until ctx is cancelled.
+func (app *application) publishScheduledPosts(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			published, err := app.store.Posts.PublishScheduledPosts(ctx, now)
+			if err != nil {
+				log.Printf("error publishing scheduled posts: %s", err)
+				continue
+			}
+
+			if published > 0 {
+				log.Printf("published %d scheduled posts", published)
+			}
+		}
+	}
+}
+
+// renderStaleHTMLBatch is the number of posts or comments rendered per query.
+const renderStaleHTMLBatch = 100
+
+// renderStaleHTML caches the HTML of all posts and comments that have not been rendered by the
+// current version of the Markdown renderer yet, e.g. after the rendering rules changed.
+// Until then they are rendered on every read.
+func (app *application) renderStaleHTML(ctx context.Context) {
+	renderAll(ctx, "posts", app.store.Posts.RenderStaleHTML)
+	renderAll(ctx, "comments", app.store.Comments.RenderStaleHTML)
+}
+
+func renderAll(ctx context.Context, name string, render func(context.Context, int) (int, error)) {
+	total := 0
+	for {
+		n, err := render(ctx, renderStaleHTMLBatch)
+		if err != nil {
+			log.Printf("error rendering the HTML of %s: %s", name, err)
+			return
+		}
+		total += n
+
+		if n < renderStaleHTMLBatch {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("rendered the HTML of %d %s", total, name)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ITine-Tech/blog/internal/slug"
+	"github.com/ITine-Tech/blog/internal/store"
+)
+
+// Search godoc
+//
+//	@Summary		Search posts and comments
+//	@Description	Full-text search over posts and comments, best matches first. q supports quoted "phrases", OR and -excluded words.
+//	@Tags			Search
+//	@Produce		json
+//	@Param			q		query		string	true	"Search terms"
+//	@Param			type	query		string	false	"Only search posts or comments"	Enums(post, comment)
+//	@Param			tag		query		string	false	"Only posts with this tag (name or slug) and their comments"
+//	@Param			author	query		string	false	"User ID or username of the author"
+//	@Param			limit	query		int		false	"Results per page (1-100)"	default(20)
+//	@Param			offset	query		int		false	"Number of results to skip"	default(0)
+//	@Success		200		{object}	[]store.SearchResult
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Router			/search [get]
+func (app *application) searchHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+
+	sq := store.SearchQuery{
+		Query:  strings.TrimSpace(qs.Get("q")),
+		Type:   qs.Get("type"),
+		Tag:    slug.Make(qs.Get("tag")),
+		Author: strings.TrimSpace(qs.Get("author")),
+		Limit:  store.DefaultSearchLimit,
+	}
+
+	if sq.Query == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("q is required"))
+		return
+	}
+
+	if qs.Has("tag") && sq.Tag == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("tag must contain letters or digits"))
+		return
+	}
+
+	if sq.Type != "" && sq.Type != store.SearchTypePost && sq.Type != store.SearchTypeComment {
+		app.badRequestResponse(w, r, fmt.Errorf("type must be %q or %q", store.SearchTypePost, store.SearchTypeComment))
+		return
+	}
+
+	if limit := qs.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > store.MaxSearchLimit {
+			app.badRequestResponse(w, r, fmt.Errorf("limit must be a number between 1 and %d", store.MaxSearchLimit))
+			return
+		}
+		sq.Limit = l
+	}
+
+	if offset := qs.Get("offset"); offset != "" {
+		o, err := strconv.Atoi(offset)
+		if err != nil || o < 0 {
+			app.badRequestResponse(w, r, fmt.Errorf("offset must be a positive number"))
+			return
+		}
+		sq.Offset = o
+	}
+
+	results, err := app.store.Search.Search(r.Context(), sq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, results); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSearchHandler(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+	}{
+		{name: "search terms", query: "?q=%22go+generics%22+-java", expectedStatus: http.StatusOK},
+		{name: "scoped to tag and author", query: "?q=go&tag=golang&author=gopher&type=post", expectedStatus: http.StatusOK},
+		{name: "missing query", query: "", expectedStatus: http.StatusBadRequest},
+		{name: "blank query", query: "?q=+", expectedStatus: http.StatusBadRequest},
+		{name: "tag without letters", query: "?q=go&tag=%3F%3F", expectedStatus: http.StatusBadRequest},
+		{name: "empty tag", query: "?q=go&tag=", expectedStatus: http.StatusBadRequest},
+		{name: "invalid type", query: "?q=go&type=user", expectedStatus: http.StatusBadRequest},
+		{name: "invalid limit", query: "?q=go&limit=0", expectedStatus: http.StatusBadRequest},
+		{name: "invalid offset", query: "?q=go&offset=-1", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/search"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
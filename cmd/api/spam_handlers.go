@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/spam"
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type spamReportKey string
+
+const spamReportCtx spamReportKey = "spamReport"
+
+var errSpam = errors.New("the submission looks like spam and has been rejected")
+
+// checkCommentSpam scores a new or edited comment of author and sets the comment's fingerprint.
+// An edited comment is not counted against itself.
+func (app *application) checkCommentSpam(ctx context.Context, author *store.User, comment *store.Comment) (spam.Result, error) {
+	cfg := app.spam.Config()
+	now := time.Now()
+
+	comment.Fingerprint = spam.Fingerprint(comment.Content)
+
+	recent, err := app.store.Spam.CountRecentComments(ctx, author.ID, now.Add(-cfg.VelocityWindow), comment.ID)
+	if err != nil {
+		return spam.Result{}, err
+	}
+
+	duplicates := 0
+	if comment.Fingerprint != "" {
+		duplicates, err = app.store.Spam.CountFingerprint(ctx, comment.Fingerprint, now.Add(-cfg.DuplicateWindow), comment.ID)
+		if err != nil {
+			return spam.Result{}, err
+		}
+	}
+
+	return app.spam.Check(spam.Submission{
+		Content:          comment.Content,
+		AccountCreatedAt: author.CreatedAt,
+		RecentCount:      recent,
+		DuplicateCount:   duplicates,
+	}), nil
+}
+
+// reportSpam keeps held and rejected submissions for review. The submission itself has
+// already been handled, so failing to store the report is only logged.
+func (app *application) reportSpam(ctx context.Context, report store.SpamReport, result spam.Result) {
+	if result.Verdict == spam.VerdictAllow {
+		return
+	}
+
+	report.Score = result.Score
+	report.Reasons = result.Reasons
+	report.Verdict = result.Verdict
+
+	if err := app.store.Spam.CreateReport(ctx, &report); err != nil {
+		log.Printf("error storing spam report: %s", err)
+	}
+}
+
+// GetSpamReports godoc
+//
+//	@Summary		List spam reports
+//	@Description	Lists comments and registrations the spam filter held or rejected, newest first. Only moderators and admins can use this endpoint.
+//	@Tags			Moderation
+//	@Produce		json
+//	@Param			kind		query		string	false	"Only reports of this kind"		Enums(comment, registration)
+//	@Param			verdict		query		string	false	"Only reports with this verdict"	Enums(hold, reject)
+//	@Param			reviewed	query		bool	false	"Include reviewed reports"		default(false)
+//	@Param			limit		query		int		false	"Reports per page (1-100)"		default(20)
+//	@Param			offset		query		int		false	"Number of reports to skip"		default(0)
+//	@Success		200			{object}	[]store.SpamReport
+//	@Failure		400			{object}	error	"Bad Request"
+//	@Failure		403			{object}	error	"Forbidden"
+//	@Failure		500			{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/spam [get]
+func (app *application) getSpamReportsHandler(w http.ResponseWriter, r *http.Request) {
+	sq, err := parseSpamReportQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	reports, err := app.store.Spam.GetReports(r.Context(), sq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, reports); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// ReleaseSpam godoc
+//
+//	@Summary		Release a false positive
+//	@Description	Approves the comment of the report, or sends the activation email of a held registration, and marks the report as reviewed.
+//	@Description	Rejected registrations have not been created and cannot be released, and reviewed reports cannot be released again.
+//	@Tags			Moderation
+//	@Param			reportID	path	int	true	"Report ID"	regexp(^[0-9]+$)
+//	@Success		204
+//	@Failure		400	{object}	error	"Bad Request"
+//	@Failure		403	{object}	error	"Forbidden"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		409	{object}	error	"Conflict"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/spam/{reportID}/release [post]
+func (app *application) releaseSpamHandler(w http.ResponseWriter, r *http.Request) {
+	report := getSpamReportFromCtx(r)
+	ctx := r.Context()
+
+	// a reviewed report has been released or dismissed already; releasing it again would send another invitation
+	if report.ReviewedAt != nil {
+		app.conflictResponse(w, r, fmt.Errorf("report %d has already been reviewed", report.ID))
+		return
+	}
+
+	switch {
+	case report.CommentID != nil:
+		if _, err := app.store.Comments.SetStatus(ctx, []int{*report.CommentID}, store.CommentStatusApproved); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	case report.UserID != nil:
+		user, err := app.store.Users.GetUserByID(ctx, *report.UserID)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				app.notFoundResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+
+		token := uuid.New().String()
+		if err := app.store.Users.Reinvite(ctx, user.ID, hashToken(token), app.config.mail.exp); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		if err := app.sendInvitation(user, token); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	default:
+		app.badRequestResponse(w, r, fmt.Errorf("report %d has nothing to release", report.ID))
+		return
+	}
+
+	app.markSpamReviewed(w, r, report)
+}
+
+// DismissSpam godoc
+//
+//	@Summary		Confirm a spam report
+//	@Description	Marks the report as reviewed and leaves the submission held or rejected.
+//	@Tags			Moderation
+//	@Param			reportID	path	int	true	"Report ID"	regexp(^[0-9]+$)
+//	@Success		204
+//	@Failure		403	{object}	error	"Forbidden"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/admin/spam/{reportID}/dismiss [post]
+func (app *application) dismissSpamHandler(w http.ResponseWriter, r *http.Request) {
+	app.markSpamReviewed(w, r, getSpamReportFromCtx(r))
+}
+
+func (app *application) markSpamReviewed(w http.ResponseWriter, r *http.Request, report *store.SpamReport) {
+	if err := app.store.Spam.MarkReviewed(r.Context(), report.ID); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) spamReportContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reportID, err := strconv.ParseInt(chi.URLParam(r, "reportID"), 10, 64)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+
+		report, err := app.store.Spam.GetReport(ctx, reportID)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				app.notFoundResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+
+		ctx = context.WithValue(ctx, spamReportCtx, report)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getSpamReportFromCtx(r *http.Request) *store.SpamReport {
+	report, _ := r.Context().Value(spamReportCtx).(*store.SpamReport)
+	return report
+}
+
+func parseSpamReportQuery(r *http.Request) (store.SpamReportQuery, error) {
+	qs := r.URL.Query()
+
+	sq := store.SpamReportQuery{
+		Kind:    qs.Get("kind"),
+		Verdict: qs.Get("verdict"),
+		Limit:   store.DefaultCommentLimit,
+	}
+
+	if sq.Kind != "" && sq.Kind != store.SpamKindComment && sq.Kind != store.SpamKindRegistration {
+		return sq, fmt.Errorf("kind must be %q or %q", store.SpamKindComment, store.SpamKindRegistration)
+	}
+
+	if sq.Verdict != "" && sq.Verdict != spam.VerdictHold && sq.Verdict != spam.VerdictReject {
+		return sq, fmt.Errorf("verdict must be %q or %q", spam.VerdictHold, spam.VerdictReject)
+	}
+
+	if reviewed := qs.Get("reviewed"); reviewed != "" {
+		b, err := strconv.ParseBool(reviewed)
+		if err != nil {
+			return sq, fmt.Errorf("reviewed must be true or false")
+		}
+		sq.Reviewed = b
+	}
+
+	if limit := qs.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > store.MaxCommentLimit {
+			return sq, fmt.Errorf("limit must be a number between 1 and %d", store.MaxCommentLimit)
+		}
+		sq.Limit = l
+	}
+
+	if offset := qs.Get("offset"); offset != "" {
+		o, err := strconv.Atoi(offset)
+		if err != nil || o < 0 {
+			return sq, fmt.Errorf("offset must be a positive number")
+		}
+		sq.Offset = o
+	}
+
+	return sq, nil
+}
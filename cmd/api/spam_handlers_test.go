@@ -0,0 +1,153 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/mailer"
+	"github.com/ITine-Tech/blog/internal/spam"
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestRegisterUserHandler_Spam(t *testing.T) {
+	blocklist, err := spam.ParseBlocklist(strings.NewReader("domain throwaway.example\nword casino"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedMails  int
+		expectedReport string
+	}{
+		{
+			name:           "regular registration",
+			body:           `{"username":"gopher","email":"gopher@go.dev","password":"secret"}`,
+			expectedStatus: http.StatusCreated,
+			expectedMails:  1,
+		},
+		{
+			name:           "blocklisted email domain",
+			body:           `{"username":"gopher","email":"gopher@throwaway.example","password":"secret"}`,
+			expectedStatus: http.StatusAccepted,
+			expectedReport: spam.VerdictHold,
+		},
+		{
+			name:           "blocklisted email domain and username",
+			body:           `{"username":"casino","email":"casino@throwaway.example","password":"secret"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedReport: spam.VerdictReject,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			app.spam = spam.New(spam.DefaultConfig(), blocklist)
+			mux := app.mount()
+
+			req, err := http.NewRequest(http.MethodPost, "/authentication/user", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+
+			if mails := len(app.mailer.(*mailer.MemoryMailer).Messages()); mails != tt.expectedMails {
+				t.Errorf("expected %d emails, got %d", tt.expectedMails, mails)
+			}
+
+			reports := app.store.Spam.(*store.MockSpamStore).Reports
+			switch {
+			case tt.expectedReport == "" && len(reports) > 0:
+				t.Errorf("expected no spam report, got %+v", reports)
+			case tt.expectedReport != "" && (len(reports) != 1 || reports[0].Verdict != tt.expectedReport):
+				t.Errorf("expected a %s report, got %+v", tt.expectedReport, reports)
+			}
+		})
+	}
+}
+
+func Test_parseSpamReportQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "defaults", query: ""},
+		{name: "filters", query: "?kind=comment&verdict=hold&reviewed=true&limit=50&offset=10"},
+		{name: "invalid kind", query: "?kind=post", wantErr: true},
+		{name: "invalid verdict", query: "?verdict=allow", wantErr: true},
+		{name: "invalid reviewed", query: "?reviewed=maybe", wantErr: true},
+		{name: "invalid limit", query: "?limit=0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/admin/spam"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = parseSpamReportQuery(req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSpamReportQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReleaseSpamHandler(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	held := &store.User{ID: uuid.New(), Username: "held", Email: "held@throwaway.example"}
+	admin := &store.User{ID: uuid.New(), Username: "admin", Role: store.Role{ID: 3, Name: "admin", Level: 3}, IsActive: true}
+	app.store.Users = &store.MockUserStore{Users: []*store.User{held, admin}}
+	app.store.Spam = &store.MockSpamStore{Reports: []store.SpamReport{
+		{ID: 1, Kind: store.SpamKindRegistration, UserID: &held.ID, Verdict: spam.VerdictHold},
+	}}
+
+	token, err := app.authenticator.GenerateToken(jwt.MapClaims{
+		"sub": admin.ID.String(),
+		"jti": uuid.NewString(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		expectedStatus int
+	}{
+		{name: "release", expectedStatus: http.StatusNoContent},
+		{name: "release again", expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/admin/spam/1/release", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+		})
+	}
+
+	if mails := len(app.mailer.(*mailer.MemoryMailer).Messages()); mails != 1 {
+		t.Errorf("expected 1 invitation, got %d", mails)
+	}
+}
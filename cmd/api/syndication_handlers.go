@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/ITine-Tech/blog/internal/markdown"
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/ITine-Tech/blog/internal/syndication"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SiteFeed godoc
+//
+//	@Summary		Subscribe to the blog
+//	@Description	The latest published posts as RSS 2.0, Atom or JSON Feed 1.1, depending on the extension.
+//	@Description	Supports conditional requests with If-None-Match and If-Modified-Since.
+//	@Tags			Syndication
+//	@Produce		xml
+//	@Produce		json
+//	@Success		200
+//	@Success		304	"Not Modified"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Router			/feed.rss [get]
+//	@Router			/feed.atom [get]
+//	@Router			/feed.json [get]
+func (app *application) siteFeedHandler(w http.ResponseWriter, r *http.Request) {
+	feed := syndication.Feed{
+		Title:       app.config.syndication.title,
+		Description: fmt.Sprintf("The latest posts of %s", app.config.syndication.title),
+		Link:        app.config.frontendURL,
+	}
+
+	app.writeSyndicationFeed(w, r, feed, store.PaginatedFeedQuery{})
+}
+
+// TagFeed godoc
+//
+//	@Summary		Subscribe to a tag
+//	@Description	The latest published posts of a tag as RSS 2.0, Atom or JSON Feed 1.1, depending on the extension.
+//	@Description	Supports conditional requests with If-None-Match and If-Modified-Since.
+//	@Tags			Syndication
+//	@Produce		xml
+//	@Produce		json
+//	@Param			slug	path	string	true	"Tag slug"
+//	@Success		200
+//	@Success		304	"Not Modified"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Router			/tags/{slug}/feed.rss [get]
+//	@Router			/tags/{slug}/feed.atom [get]
+//	@Router			/tags/{slug}/feed.json [get]
+func (app *application) tagFeedHandler(w http.ResponseWriter, r *http.Request) {
+	tag := getTagFromCtx(r)
+
+	feed := syndication.Feed{
+		Title:       fmt.Sprintf("%s: %s", app.config.syndication.title, tag.Name),
+		Description: fmt.Sprintf("The latest posts tagged %s", tag.Name),
+		Link:        fmt.Sprintf("%s/tags/%s", app.config.frontendURL, tag.Slug),
+	}
+
+	app.writeSyndicationFeed(w, r, feed, store.PaginatedFeedQuery{Tags: []string{tag.Slug}, TagMatch: store.TagMatchAny})
+}
+
+// AuthorFeed godoc
+//
+//	@Summary		Subscribe to an author
+//	@Description	The latest published posts of an author as RSS 2.0, Atom or JSON Feed 1.1, depending on the extension.
+//	@Description	Supports conditional requests with If-None-Match and If-Modified-Since.
+//	@Tags			Syndication
+//	@Produce		xml
+//	@Produce		json
+//	@Param			username	path	string	true	"Username of the author"
+//	@Success		200
+//	@Success		304	"Not Modified"
+//	@Failure		404	{object}	error	"Not found"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Router			/authors/{username}/feed.rss [get]
+//	@Router			/authors/{username}/feed.atom [get]
+//	@Router			/authors/{username}/feed.json [get]
+func (app *application) authorFeedHandler(w http.ResponseWriter, r *http.Request) {
+	author, err := app.store.Users.GetUserByUsername(r.Context(), chi.URLParam(r, "username"))
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	feed := syndication.Feed{
+		Title:       fmt.Sprintf("%s: %s", app.config.syndication.title, author.Username),
+		Description: fmt.Sprintf("The latest posts by %s", author.Username),
+		Link:        fmt.Sprintf("%s/authors/%s", app.config.frontendURL, author.Username),
+		Author:      author.Username,
+	}
+
+	app.writeSyndicationFeed(w, r, feed, store.PaginatedFeedQuery{Author: author.ID.String()})
+}
+
+// writeSyndicationFeed fills feed with the latest published posts matching fq and writes it
+// in the format of the request's extension. The feed is only sent if it changed since the
+// client's copy, which is identified by an ETag over the posts, their versions and tags.
+func (app *application) writeSyndicationFeed(w http.ResponseWriter, r *http.Request, feed syndication.Feed, fq store.PaginatedFeedQuery) {
+	format := strings.TrimPrefix(path.Ext(r.URL.Path), ".")
+	contentType, ok := syndication.ContentTypes[format]
+	if !ok {
+		app.notFoundResponse(w, r, fmt.Errorf("unknown feed format %q", format))
+		return
+	}
+
+	ctx := r.Context()
+
+	fq.Limit = app.config.syndication.limit
+	fq.Sort = store.SortDesc
+
+	posts, _, err := app.store.Posts.GetAllPosts(ctx, fq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	authors := map[uuid.UUID]string{}
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%d\n", format, feed.Title, markdown.Version)
+
+	feed.FeedURL = app.config.apiURL + r.URL.Path
+	if feed.Author == "" {
+		feed.Author = app.config.syndication.title
+	}
+
+	for _, post := range posts {
+		author, ok := authors[post.UserID]
+		if !ok {
+			user, err := app.store.Users.GetUserByID(ctx, post.UserID)
+			if err != nil && !errors.Is(err, store.ErrNotFound) {
+				app.internalServerError(w, r, err)
+				return
+			}
+			if user != nil {
+				author = user.Username
+			}
+			authors[post.UserID] = author
+		}
+
+		published := post.CreatedAt
+		if post.PublishedAt != nil {
+			published = *post.PublishedAt
+		}
+
+		feed.Items = append(feed.Items, syndication.Item{
+			ID:        fmt.Sprintf("%s/feed/%d", app.config.apiURL, post.ID),
+			Title:     post.Title,
+			Link:      fmt.Sprintf("%s/posts/%s", app.config.frontendURL, post.Slug),
+			Content:   post.Text,
+			HTML:      post.HTML,
+			Author:    author,
+			Tags:      post.Tags,
+			Published: published,
+			Updated:   post.UpdatedAt,
+		})
+
+		if post.UpdatedAt.After(feed.Updated) {
+			feed.Updated = post.UpdatedAt
+		}
+		// renaming or merging tags changes the categories without changing the version of the post
+		fmt.Fprintf(hash, "%d:%d:%q\n", post.ID, post.Version, post.Tags)
+	}
+
+	tag := `W/"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	w.Header().Set("ETag", tag)
+	if !feed.Updated.IsZero() {
+		w.Header().Set("Last-Modified", feed.Updated.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, tag, feed.Updated) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := syndication.Write(&buf, format, feed); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
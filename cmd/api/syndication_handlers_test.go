@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/store"
+)
+
+func TestSyndicationHandlers(t *testing.T) {
+	updated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	app := newTestApplication(t)
+	app.store.Posts = &store.MockPostStore{
+		Posts: []*store.Post{
+			{ID: 1, Title: "Hello", Slug: "hello", Text: "First post", Tags: []string{"go"}, Status: store.PostStatusPublished, CreatedAt: updated, UpdatedAt: updated, Version: 1},
+		},
+	}
+	mux := app.mount()
+
+	tests := []struct {
+		name                string
+		target              string
+		header              http.Header
+		expectedStatus      int
+		expectedContentType string
+	}{
+		{name: "rss", target: "/feed.rss", expectedStatus: http.StatusOK, expectedContentType: "application/rss+xml"},
+		{name: "atom", target: "/feed.atom", expectedStatus: http.StatusOK, expectedContentType: "application/atom+xml"},
+		{name: "json feed", target: "/feed.json", expectedStatus: http.StatusOK, expectedContentType: "application/feed+json"},
+		{name: "tag", target: "/tags/go/feed.atom", expectedStatus: http.StatusOK, expectedContentType: "application/atom+xml"},
+		{name: "missing tag", target: "/tags/missing/feed.atom", expectedStatus: http.StatusNotFound},
+		{name: "author", target: "/authors/gopher/feed.json", expectedStatus: http.StatusOK, expectedContentType: "application/feed+json"},
+		{name: "missing author", target: "/authors/missing/feed.json", expectedStatus: http.StatusNotFound},
+		{name: "not modified since", target: "/feed.rss", header: http.Header{"If-Modified-Since": {updated.Format(http.TimeFormat)}}, expectedStatus: http.StatusNotModified},
+		{name: "modified since", target: "/feed.rss", header: http.Header{"If-Modified-Since": {updated.Add(-time.Minute).Format(http.TimeFormat)}}, expectedStatus: http.StatusOK, expectedContentType: "application/rss+xml"},
+		{name: "etag mismatch wins over If-Modified-Since", target: "/feed.rss", header: http.Header{"If-None-Match": {`W/"stale"`}, "If-Modified-Since": {updated.Format(http.TimeFormat)}}, expectedStatus: http.StatusOK, expectedContentType: "application/rss+xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.target, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+			if contentType := rr.Header().Get("Content-Type"); !strings.HasPrefix(contentType, tt.expectedContentType) {
+				t.Errorf("expected content type %q, got %q", tt.expectedContentType, contentType)
+			}
+		})
+	}
+}
+
+func TestSyndicationHandlers_ETag(t *testing.T) {
+	app := newTestApplication(t)
+	post := &store.Post{ID: 1, Slug: "hello", Tags: []string{"go"}, Status: store.PostStatusPublished, UpdatedAt: time.Now(), Version: 1}
+	app.store.Posts = &store.MockPostStore{Posts: []*store.Post{post}}
+	mux := app.mount()
+
+	req, _ := http.NewRequest(http.MethodGet, "/feed.json", nil)
+	rr := executeRequest(req, mux)
+	checkResponseCode(t, http.StatusOK, rr.Code)
+
+	tag := rr.Header().Get("ETag")
+	if tag == "" {
+		t.Fatal("expected an ETag")
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/feed.json", nil)
+	req.Header.Set("If-None-Match", tag)
+	rr = executeRequest(req, mux)
+	checkResponseCode(t, http.StatusNotModified, rr.Code)
+
+	// another format of the same posts is a different representation
+	req, _ = http.NewRequest(http.MethodGet, "/feed.rss", nil)
+	req.Header.Set("If-None-Match", tag)
+	rr = executeRequest(req, mux)
+	checkResponseCode(t, http.StatusOK, rr.Code)
+
+	// a renamed tag changes the categories of the feed but not the version of the post
+	post.Tags = []string{"golang"}
+	req, _ = http.NewRequest(http.MethodGet, "/feed.json", nil)
+	req.Header.Set("If-None-Match", tag)
+	rr = executeRequest(req, mux)
+	checkResponseCode(t, http.StatusOK, rr.Code)
+}
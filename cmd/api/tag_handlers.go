@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ITine-Tech/blog/internal/slug"
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+type tagKey string
+
+const tagCtx tagKey = "tag"
+
+type RenameTagPayload struct {
+	Name string `json:"name"`
+}
+
+type MergeTagPayload struct {
+	Into string `json:"into"`
+}
+
+// GetTags godoc
+//
+//	@Summary		List tags
+//	@Description	Lists all tags of published posts with their number of posts, most used first.
+//	@Tags			Tags
+//	@Produce		json
+//	@Success		200	{object}	[]store.Tag
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Router			/tags [get]
+func (app *application) getTagsHandler(w http.ResponseWriter, r *http.Request) {
+	tags, err := app.store.Tags.GetAll(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, tags); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetTagPosts godoc
+//
+//	@Summary		List the posts of a tag
+//	@Description	Lists the posts of a tag, newest first. Takes the same query parameters as the feed, except for tags.
+//	@Tags			Tags
+//	@Produce		json
+//	@Param			slug	path		string	true	"Tag slug"
+//	@Param			limit	query		int		false	"Posts per page (1-100)"	default(20)
+//	@Param			cursor	query		string	false	"Cursor of the next page"
+//	@Param			sort	query		string	false	"Sort by publish time"	Enums(asc, desc)	default(desc)
+//	@Param			author	query		string	false	"User ID or username of the author"
+//	@Param			since	query		string	false	"Only posts published at or after this RFC 3339 timestamp"
+//	@Param			until	query		string	false	"Only posts published before this RFC 3339 timestamp"
+//	@Success		200		{object}	[]store.Post
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Router			/tags/{slug}/posts [get]
+func (app *application) getTagPostsHandler(w http.ResponseWriter, r *http.Request) {
+	tag := getTagFromCtx(r)
+
+	fq, err := parseFeedQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	fq.Tags = []string{tag.Slug}
+	fq.TagMatch = store.TagMatchAny
+
+	app.writeFeed(w, r, fq)
+}
+
+// RenameTag godoc
+//
+//	@Summary		Rename a tag
+//	@Description	Renames a tag and updates its slug. If another tag already has the new slug, the tags have to be merged instead. Requires the tags:manage permission.
+//	@Tags			Tags
+//	@Accept			json
+//	@Produce		json
+//	@Param			slug	path		string				true	"Tag slug"
+//	@Param			payload	body		RenameTagPayload	true	"New name"
+//	@Success		200		{object}	store.Tag
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		409		{object}	error	"Conflict"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/tags/{slug} [patch]
+func (app *application) renameTagHandler(w http.ResponseWriter, r *http.Request) {
+	tag := getTagFromCtx(r)
+
+	var payload RenameTagPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	tags, err := store.NormalizeTags([]string{payload.Name})
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	tag.Name = tags[0].Name
+	tag.Slug = tags[0].Slug
+
+	if err := app.store.Tags.Rename(r.Context(), tag); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, store.ErrConflict):
+			app.conflictResponse(w, r, fmt.Errorf("tag %q already exists, merge the tags instead", tag.Slug))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, tag); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// MergeTag godoc
+//
+//	@Summary		Merge a tag into another
+//	@Description	Moves all posts of the tag to the target tag and deletes the tag. Requires the tags:manage permission.
+//	@Tags			Tags
+//	@Accept			json
+//	@Produce		json
+//	@Param			slug	path		string			true	"Slug of the tag to merge"
+//	@Param			payload	body		MergeTagPayload	true	"Name or slug of the target tag"
+//	@Success		200		{object}	store.Tag
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/tags/{slug}/merge [post]
+func (app *application) mergeTagHandler(w http.ResponseWriter, r *http.Request) {
+	source := getTagFromCtx(r)
+
+	var payload MergeTagPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	into := slug.Make(payload.Into)
+	if into == "" {
+		app.badRequestResponse(w, r, fmt.Errorf("the tag to merge into is required"))
+		return
+	}
+	if into == source.Slug {
+		app.badRequestResponse(w, r, fmt.Errorf("a tag cannot be merged into itself"))
+		return
+	}
+
+	ctx := r.Context()
+
+	target, err := app.store.Tags.GetBySlug(ctx, into)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.store.Tags.Merge(ctx, source.ID, target.ID); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	// Fetch the target again for the new post count.
+	target, err = app.store.Tags.GetBySlug(ctx, target.Slug)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, target); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) tagContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		tag, err := app.store.Tags.GetBySlug(ctx, strings.ToLower(chi.URLParam(r, "slug")))
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				app.notFoundResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+
+		ctx = context.WithValue(ctx, tagCtx, tag)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getTagFromCtx(r *http.Request) *store.Tag {
+	tag, _ := r.Context().Value(tagCtx).(*store.Tag)
+	return tag
+}
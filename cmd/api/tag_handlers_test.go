@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/google/uuid"
+)
+
+func TestTagHandlers(t *testing.T) {
+	app := newTestApplication(t)
+
+	admin := &store.User{ID: uuid.New(), Role: store.Role{Name: "admin", Level: 3}}
+	user := &store.User{ID: uuid.New(), Role: store.Role{Name: "user", Level: 1}}
+
+	tests := []struct {
+		name           string
+		user           *store.User
+		method         string
+		target         string
+		body           string
+		handler        http.HandlerFunc
+		expectedStatus int
+	}{
+		{name: "rename", user: admin, method: http.MethodPatch, target: "/tags/go", body: `{"name":"Golang"}`, handler: app.renameTagHandler, expectedStatus: http.StatusOK},
+		{name: "rename without letters", user: admin, method: http.MethodPatch, target: "/tags/go", body: `{"name":"!!!"}`, handler: app.renameTagHandler, expectedStatus: http.StatusBadRequest},
+		{name: "merge", user: admin, method: http.MethodPost, target: "/tags/golang/merge", body: `{"into":"Go"}`, handler: app.mergeTagHandler, expectedStatus: http.StatusOK},
+		{name: "merge into itself", user: admin, method: http.MethodPost, target: "/tags/golang/merge", body: `{"into":"GoLang"}`, handler: app.mergeTagHandler, expectedStatus: http.StatusBadRequest},
+		{name: "merge into missing tag", user: admin, method: http.MethodPost, target: "/tags/golang/merge", body: `{"into":"missing"}`, handler: app.mergeTagHandler, expectedStatus: http.StatusNotFound},
+		{name: "user", user: user, method: http.MethodPatch, target: "/tags/go", body: `{"name":"Golang"}`, handler: app.renameTagHandler, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			slug := strings.Split(tt.target, "/")[2]
+			ctx := context.WithValue(req.Context(), userCTx, tt.user)
+			ctx = context.WithValue(ctx, tagCtx, &store.Tag{ID: int64(len(slug)), Name: slug, Slug: slug})
+
+			rr := executeRequest(req.WithContext(ctx), app.RequirePermission(store.PermTagsManage)(tt.handler))
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -2,10 +2,15 @@ package main
 
 import (
 	"github.com/ITine-Tech/blog/internal/auth"
+	"github.com/ITine-Tech/blog/internal/mailer"
+	"github.com/ITine-Tech/blog/internal/media"
+	"github.com/ITine-Tech/blog/internal/spam"
 	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/ITine-Tech/blog/internal/totp"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 // newTestApplication creates a new instance of the application for testing purposes.
@@ -22,9 +27,29 @@ func newTestApplication(t *testing.T) *application {
 	mockStore := store.NewMockStore()
 	testAuth := &auth.TestAuthenticator{}
 
+	blobs, err := media.NewLocalBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cipher, err := totp.NewCipher(make([]byte, totp.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	return &application{
+		config: config{
+			syndication: syndicationConfig{title: "Blog", limit: store.DefaultFeedLimit},
+			media:       mediaConfig{maxSize: 1 << 20, quota: 2 << 20, thumbnailSize: 64},
+			reactions:   reactionsConfig{kinds: defaultReactionKinds},
+			auth:        authConfig{twoFactor: twoFactorConfig{issuer: "Blog", challengeExpiry: 5 * time.Minute}},
+		},
 		store:         mockStore,
 		authenticator: testAuth,
+		mailer:        mailer.NewMemoryMailer(),
+		spam:          spam.New(spam.DefaultConfig(), nil),
+		blobs:         blobs,
+		totp:          cipher,
 	}
 }
 
@@ -0,0 +1,513 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/ITine-Tech/blog/internal/totp"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// twoFactorChallengeType is the typ claim of challenge tokens, which keeps them from being used as access tokens.
+const twoFactorChallengeType = "2fa"
+
+var (
+	errInvalidChallenge  = errors.New("invalid or used challenge token")
+	errInvalidCode       = errors.New("invalid or used code")
+	errTwoFactorDisabled = errors.New("two-factor authentication is not enabled")
+	errCodeRequired      = errors.New("either code or recovery_code is required")
+)
+
+type TwoFactorChallenge struct {
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int64  `json:"expires_in"`
+}
+
+type TwoFactorLoginPayload struct {
+	ChallengeToken string `json:"challenge_token"`
+	// Code is the current code of the authenticator app. RecoveryCode can be sent instead.
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TwoFactorEnrolment struct {
+	// Secret is for entering the account by hand, URI for showing it as QR code.
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type TwoFactorCodePayload struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// newTOTPCipher returns the cipher the TOTP secrets of users are stored with. key is a base64 encoded
+// 32 byte key. It is required, so that a leaked token secret does not expose the secrets as well.
+func newTOTPCipher(key string) (*totp.Cipher, error) {
+	if key == "" {
+		return nil, errors.New("TOTP_ENCRYPTION_KEY is required, e.g. from openssl rand -base64 32")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("TOTP_ENCRYPTION_KEY must be base64 encoded: %w", err)
+	}
+	return totp.NewCipher(decoded)
+}
+
+// writeTwoFactorChallenge responds with a challenge token the user can exchange for tokens with a code.
+func (app *application) writeTwoFactorChallenge(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	now := time.Now()
+	expiry := app.config.auth.twoFactor.challengeExpiry
+
+	token, err := app.authenticator.GenerateToken(jwt.MapClaims{
+		"sub": userID,
+		"jti": uuid.New().String(),
+		"exp": now.Add(expiry).Unix(),
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"iss": app.config.auth.token.issuer,
+		"aud": app.config.auth.token.audience,
+		"typ": twoFactorChallengeType,
+	})
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	challenge := TwoFactorChallenge{
+		ChallengeToken: token,
+		ExpiresIn:      int64(expiry.Seconds()),
+	}
+	if err := app.jsonResponse(w, http.StatusAccepted, challenge); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// VerifyTwoFactor godoc
+//
+//	@Summary		Complete a login with two-factor authentication
+//	@Description	Exchanges the challenge token from /authentication/token and the current code of the authenticator app,
+//	@Description	or an unused recovery code, for an access and a refresh token. Every challenge token can only be tried once;
+//	@Description	after a wrong code, log in again. Every code and recovery code is only accepted once.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		TwoFactorLoginPayload	true	"Challenge and code"
+//	@Success		201		{object}	TokenPair				"Tokens"
+//	@Failure		400		{object}	error					"Bad Request"
+//	@Failure		401		{object}	error					"Unauthorized"
+//	@Failure		403		{object}	error					"Suspended"
+//	@Failure		500		{object}	error					"Internal Server Error"
+//	@Router			/authentication/2fa [post]
+func (app *application) verifyTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	var payload TwoFactorLoginPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	switch {
+	case payload.ChallengeToken == "":
+		app.badRequestResponse(w, r, errors.New("challenge_token is required"))
+		return
+	case (payload.Code == "") == (payload.RecoveryCode == ""):
+		app.badRequestResponse(w, r, errCodeRequired)
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := app.redeemTwoFactorChallenge(ctx, payload.ChallengeToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidChallenge):
+			app.unauthorizedResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	user, err := app.store.Users.GetUserByID(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.unauthorizedResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	if !user.IsActive {
+		app.unauthorizedResponse(w, r, fmt.Errorf("user %s is not active", user.ID))
+		return
+	}
+	if app.refuseSuspended(w, r, user) {
+		return
+	}
+
+	if err := app.verifySecondFactor(ctx, user.ID, payload.Code, payload.RecoveryCode); err != nil {
+		switch {
+		case errors.Is(err, errInvalidCode), errors.Is(err, errTwoFactorDisabled):
+			app.unauthorizedResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	tokens, err := app.issueTokenPair(ctx, user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, tokens); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// redeemTwoFactorChallenge validates the challenge token, revokes it so that it cannot be tried again
+// and returns the ID of its user.
+func (app *application) redeemTwoFactorChallenge(ctx context.Context, token string) (uuid.UUID, error) {
+	jwtToken, err := app.authenticator.ValidateToken(token)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %s", errInvalidChallenge, err)
+	}
+
+	claims, _ := jwtToken.Claims.(jwt.MapClaims)
+	if claims["typ"] != twoFactorChallengeType {
+		return uuid.Nil, fmt.Errorf("%w: not a challenge token", errInvalidChallenge)
+	}
+
+	sub, _ := claims["sub"].(string)
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %s", errInvalidChallenge, err)
+	}
+
+	jti, err := jtiFromClaims(claims)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %s", errInvalidChallenge, err)
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return uuid.Nil, fmt.Errorf("%w: token has no expiry", errInvalidChallenge)
+	}
+
+	revoked, err := app.store.Tokens.IsAccessTokenRevoked(ctx, jti)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if revoked {
+		return uuid.Nil, errInvalidChallenge
+	}
+
+	if err := app.store.Tokens.RevokeAccessToken(ctx, jti, exp.Time); err != nil {
+		return uuid.Nil, err
+	}
+	return userID, nil
+}
+
+// verifySecondFactor checks the TOTP code or, if it is set, the recovery code of the user and uses it up.
+//
+// Returns errInvalidCode for wrong and used codes and errTwoFactorDisabled if the user has not enabled
+// two-factor authentication.
+func (app *application) verifySecondFactor(ctx context.Context, userID uuid.UUID, code, recoveryCode string) error {
+	enrolment, err := app.store.TwoFactor.Get(ctx, userID)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return errTwoFactorDisabled
+	case err != nil:
+		return err
+	case !enrolment.Enabled:
+		return errTwoFactorDisabled
+	}
+
+	if recoveryCode != "" {
+		err := app.store.TwoFactor.UseRecoveryCode(ctx, userID, hashToken(totp.NormalizeRecoveryCode(recoveryCode)))
+		if errors.Is(err, store.ErrNotFound) {
+			return errInvalidCode
+		}
+		return err
+	}
+
+	return app.useCode(ctx, userID, enrolment, code)
+}
+
+// useCode checks the TOTP code against the secret of the enrolment and records it, so that it is not accepted again.
+func (app *application) useCode(ctx context.Context, userID uuid.UUID, enrolment *store.TwoFactorEnrolment, code string) error {
+	secret, err := app.totp.Decrypt(enrolment.Secret, userID[:])
+	if err != nil {
+		return err
+	}
+
+	counter, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return errInvalidCode
+	}
+
+	if err := app.store.TwoFactor.UseCode(ctx, userID, counter); err != nil {
+		if errors.Is(err, store.ErrCodeReused) {
+			return errInvalidCode
+		}
+		return err
+	}
+	return nil
+}
+
+// StartTwoFactor godoc
+//
+//	@Summary		Start to enable two-factor authentication
+//	@Description	Creates a TOTP secret for the user to add to an authenticator app, by hand or from the otpauth URI as QR code.
+//	@Description	Two-factor authentication is enabled once a code is confirmed at /me/2fa/confirm. Starting again replaces the secret.
+//	@Tags			Two-Factor Authentication
+//	@Produce		json
+//	@Success		201	{object}	TwoFactorEnrolment
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		409	{object}	error	"Already enabled"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/me/2fa [post]
+func (app *application) startTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromCtx(r)
+
+	if user.TwoFactorEnabled {
+		app.conflictResponse(w, r, errors.New("two-factor authentication is already enabled"))
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	encrypted, err := app.totp.Encrypt(secret, user.ID[:])
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.TwoFactor.SetSecret(r.Context(), user.ID, encrypted); err != nil {
+		switch {
+		case errors.Is(err, store.ErrConflict):
+			app.conflictResponse(w, r, errors.New("two-factor authentication is already enabled"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	enrolment := TwoFactorEnrolment{
+		Secret: secret,
+		URI:    totp.URI(app.config.auth.twoFactor.issuer, user.Username, secret),
+	}
+	if err := app.jsonResponse(w, http.StatusCreated, enrolment); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// ConfirmTwoFactor godoc
+//
+//	@Summary		Enable two-factor authentication
+//	@Description	Enables two-factor authentication with the first code of the authenticator app and returns the recovery codes.
+//	@Description	Every recovery code can be used once instead of a code. They are only shown now.
+//	@Tags			Two-Factor Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		TwoFactorCodePayload	true	"Code"
+//	@Success		200		{object}	RecoveryCodes
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		401		{object}	error	"Unauthorized"
+//	@Failure		409		{object}	error	"Already enabled or not started"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/me/2fa/confirm [post]
+func (app *application) confirmTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromCtx(r)
+	ctx := r.Context()
+
+	var payload TwoFactorCodePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if payload.Code == "" {
+		app.badRequestResponse(w, r, errors.New("code is required"))
+		return
+	}
+
+	enrolment, err := app.store.TwoFactor.Get(ctx, user.ID)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		app.conflictResponse(w, r, errors.New("start to enable two-factor authentication first"))
+		return
+	case err != nil:
+		app.internalServerError(w, r, err)
+		return
+	case enrolment.Enabled:
+		app.conflictResponse(w, r, errors.New("two-factor authentication is already enabled"))
+		return
+	}
+
+	if err := app.useCode(ctx, user.ID, enrolment, payload.Code); err != nil {
+		switch {
+		case errors.Is(err, errInvalidCode):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.TwoFactor.Enable(ctx, user.ID, hashes); err != nil {
+		switch {
+		case errors.Is(err, store.ErrConflict):
+			app.conflictResponse(w, r, errors.New("two-factor authentication is already enabled"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, RecoveryCodes{RecoveryCodes: codes}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DisableTwoFactor godoc
+//
+//	@Summary		Disable two-factor authentication
+//	@Description	Disables two-factor authentication with a code or a recovery code and removes the secret and recovery codes.
+//	@Description	Users whose role requires two-factor authentication cannot disable it.
+//	@Tags			Two-Factor Authentication
+//	@Accept			json
+//	@Param			payload	body	TwoFactorCodePayload	true	"Code or recovery code"
+//	@Success		204
+//	@Failure		400	{object}	error	"Bad Request"
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		403	{object}	error	"Required by the role"
+//	@Failure		404	{object}	error	"Not enabled"
+//	@Failure		500	{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/me/2fa [delete]
+func (app *application) disableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromCtx(r)
+
+	if user.Role.RequiresTwoFactor {
+		app.forbiddenResponse(w, r, fmt.Errorf("the role %s requires two-factor authentication", user.Role.Name))
+		return
+	}
+
+	if !app.checkSecondFactor(w, r) {
+		return
+	}
+
+	if err := app.store.TwoFactor.Disable(r.Context(), user.ID); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, errTwoFactorDisabled)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes godoc
+//
+//	@Summary		Replace the recovery codes
+//	@Description	Replaces all recovery codes, used or not, with new ones, given a code or a recovery code. They are only shown now.
+//	@Tags			Two-Factor Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		TwoFactorCodePayload	true	"Code or recovery code"
+//	@Success		200		{object}	RecoveryCodes
+//	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		401		{object}	error	"Unauthorized"
+//	@Failure		404		{object}	error	"Not enabled"
+//	@Failure		500		{object}	error	"Internal Server Error"
+//	@Security		ApiKeyAuth
+//	@Router			/me/2fa/recovery-codes [post]
+func (app *application) regenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.checkSecondFactor(w, r) {
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.TwoFactor.ReplaceRecoveryCodes(r.Context(), getUserFromCtx(r).ID, hashes); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, RecoveryCodes{RecoveryCodes: codes}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// checkSecondFactor verifies the code or recovery code in the request body for the user in the context.
+// It responds with the error and returns false if it is missing, wrong or used.
+func (app *application) checkSecondFactor(w http.ResponseWriter, r *http.Request) bool {
+	var payload TwoFactorCodePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return false
+	}
+	if (payload.Code == "") == (payload.RecoveryCode == "") {
+		app.badRequestResponse(w, r, errCodeRequired)
+		return false
+	}
+
+	err := app.verifySecondFactor(r.Context(), getUserFromCtx(r).ID, payload.Code, payload.RecoveryCode)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, errInvalidCode):
+		app.badRequestResponse(w, r, err)
+	case errors.Is(err, errTwoFactorDisabled):
+		app.notFoundResponse(w, r, err)
+	default:
+		app.internalServerError(w, r, err)
+	}
+	return false
+}
+
+// generateRecoveryCodes returns new recovery codes and the hashes they are stored under.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes, err := totp.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = hashToken(totp.NormalizeRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
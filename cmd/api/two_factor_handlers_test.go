@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/ITine-Tech/blog/internal/totp"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestTwoFactor(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	user := &store.User{ID: uuid.New(), Username: "user", Role: store.Role{ID: 1, Name: "user", Level: 1}, IsActive: true}
+	if err := user.Password.Set("secret"); err != nil {
+		t.Fatal(err)
+	}
+	app.store.Users = &store.MockUserStore{Users: []*store.User{user}}
+	app.store.TwoFactor = &store.MockTwoFactorStore{Users: []*store.User{user}}
+
+	accessToken, err := app.authenticator.GenerateToken(jwt.MapClaims{
+		"sub": user.ID.String(),
+		"jti": uuid.NewString(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	do := func(method, target, token, body string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req, err := http.NewRequest(method, target, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return executeRequest(req, mux)
+	}
+	decode := func(rr *httptest.ResponseRecorder, v any) {
+		t.Helper()
+
+		resp := struct {
+			Data any `json:"data"`
+		}{Data: v}
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// codes of this period and the next are accepted until the period after next
+	var secret string
+	period := totp.Counter(time.Now())
+	code := func(periods int64) string {
+		t.Helper()
+
+		code, err := totp.Code(secret, period+periods)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+	codeBody := func(code string) string {
+		return `{"code":"` + code + `"}`
+	}
+	challenge := func() string {
+		t.Helper()
+
+		rr := do(http.MethodPost, "/authentication/token", "", `{"username":"user","password":"secret"}`)
+		checkResponseCode(t, http.StatusAccepted, rr.Code)
+
+		var c TwoFactorChallenge
+		decode(rr, &c)
+		return c.ChallengeToken
+	}
+	login := func(challenge, field, code string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		return do(http.MethodPost, "/authentication/2fa", "", `{"challenge_token":"`+challenge+`","`+field+`":"`+code+`"}`)
+	}
+
+	rr := do(http.MethodPost, "/authentication/token", "", `{"username":"user","password":"secret"}`)
+	checkResponseCode(t, http.StatusCreated, rr.Code)
+
+	rr = do(http.MethodPost, "/me/2fa/confirm", accessToken, codeBody("123456"))
+	checkResponseCode(t, http.StatusConflict, rr.Code)
+
+	rr = do(http.MethodPost, "/me/2fa", accessToken, "")
+	checkResponseCode(t, http.StatusCreated, rr.Code)
+	var enrolment TwoFactorEnrolment
+	decode(rr, &enrolment)
+	secret = enrolment.Secret
+	if !strings.HasPrefix(enrolment.URI, "otpauth://totp/Blog:user?") {
+		t.Errorf("unexpected URI %s", enrolment.URI)
+	}
+
+	wrong := "000000"
+	if code(0) == wrong {
+		wrong = "111111"
+	}
+	rr = do(http.MethodPost, "/me/2fa/confirm", accessToken, codeBody(wrong))
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+
+	rr = do(http.MethodPost, "/me/2fa/confirm", accessToken, codeBody(code(0)))
+	checkResponseCode(t, http.StatusOK, rr.Code)
+	var recovery RecoveryCodes
+	decode(rr, &recovery)
+	if len(recovery.RecoveryCodes) != totp.RecoveryCodes {
+		t.Fatalf("expected %d recovery codes, got %v", totp.RecoveryCodes, recovery.RecoveryCodes)
+	}
+	if !user.TwoFactorEnabled {
+		t.Fatal("expected two-factor authentication to be enabled")
+	}
+
+	rr = do(http.MethodPost, "/me/2fa", accessToken, "")
+	checkResponseCode(t, http.StatusConflict, rr.Code)
+
+	t.Run("challenge is no access token", func(t *testing.T) {
+		rr := do(http.MethodGet, "/users", challenge(), "")
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("used code", func(t *testing.T) {
+		rr := login(challenge(), "code", code(0))
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("code", func(t *testing.T) {
+		c := challenge()
+
+		rr := login(c, "code", code(1))
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+
+		rr = login(c, "code", code(1))
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("missing code", func(t *testing.T) {
+		rr := do(http.MethodPost, "/authentication/2fa", "", `{"challenge_token":"`+challenge()+`"}`)
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("recovery code", func(t *testing.T) {
+		rr := login(challenge(), "recovery_code", strings.ToUpper(recovery.RecoveryCodes[0]))
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+
+		rr = login(challenge(), "recovery_code", recovery.RecoveryCodes[0])
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("regenerate recovery codes", func(t *testing.T) {
+		old := slices.Clone(recovery.RecoveryCodes)
+		rr := do(http.MethodPost, "/me/2fa/recovery-codes", accessToken, `{"recovery_code":"`+old[1]+`"}`)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		decode(rr, &recovery)
+
+		rr = login(challenge(), "recovery_code", old[2])
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		rr := do(http.MethodDelete, "/me/2fa", accessToken, `{"recovery_code":"`+recovery.RecoveryCodes[0]+`"}`)
+		checkResponseCode(t, http.StatusNoContent, rr.Code)
+
+		rr = do(http.MethodPost, "/authentication/token", "", `{"username":"user","password":"secret"}`)
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+
+		rr = do(http.MethodDelete, "/me/2fa", accessToken, `{"recovery_code":"`+recovery.RecoveryCodes[1]+`"}`)
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestTwoFactor_RequiredByRole(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	staff := store.Role{ID: 4, Name: "staff", Level: 2, RequiresTwoFactor: true}
+	user := &store.User{ID: uuid.New(), Username: "user", Role: staff, IsActive: true}
+	enrolled := &store.User{ID: uuid.New(), Username: "enrolled", Role: staff, IsActive: true, TwoFactorEnabled: true}
+	admin := &store.User{ID: uuid.New(), Username: "admin", Role: store.Role{ID: 3, Name: "admin", Level: 3}, IsActive: true}
+	app.store.Users = &store.MockUserStore{Users: []*store.User{user, enrolled, admin}}
+	app.store.TwoFactor = &store.MockTwoFactorStore{
+		Users:      []*store.User{user, enrolled, admin},
+		Enrolments: map[uuid.UUID]*store.TwoFactorEnrolment{enrolled.ID: {Enabled: true}},
+	}
+
+	token := func(user *store.User) string {
+		t.Helper()
+
+		token, err := app.authenticator.GenerateToken(jwt.MapClaims{
+			"sub": user.ID.String(),
+			"jti": uuid.NewString(),
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		principal      *store.User
+		target         string
+		body           string
+		expectedStatus int
+	}{
+		{name: "unenrolled user is rejected", method: http.MethodGet, principal: user, target: "/users", expectedStatus: http.StatusForbidden},
+		{name: "unenrolled user can enrol", method: http.MethodPost, principal: user, target: "/me/2fa", expectedStatus: http.StatusCreated},
+		{name: "enrolled user is accepted", method: http.MethodGet, principal: enrolled, target: "/users", expectedStatus: http.StatusOK},
+		{name: "enrolled user cannot disable it", method: http.MethodDelete, principal: enrolled, target: "/me/2fa", body: `{"code":"123456"}`, expectedStatus: http.StatusForbidden},
+		{name: "reset as a user", method: http.MethodDelete, principal: enrolled, target: "/admin/users/" + enrolled.ID.String() + "/2fa", expectedStatus: http.StatusForbidden},
+		{name: "reset your own", method: http.MethodDelete, principal: admin, target: "/admin/users/" + admin.ID.String() + "/2fa", expectedStatus: http.StatusBadRequest},
+		{name: "reset", method: http.MethodDelete, principal: admin, target: "/admin/users/" + enrolled.ID.String() + "/2fa", expectedStatus: http.StatusNoContent},
+		{name: "reset user has to enrol again", method: http.MethodGet, principal: enrolled, target: "/users", expectedStatus: http.StatusForbidden},
+		{name: "reset when not enabled", method: http.MethodDelete, principal: admin, target: "/admin/users/" + user.ID.String() + "/2fa", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token(tt.principal))
+
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func Test_newTOTPCipher(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "missing", key: "", wantErr: true},
+		{name: "key", key: base64.StdEncoding.EncodeToString(make([]byte, totp.KeySize))},
+		{name: "not base64", key: "not a key!", wantErr: true},
+		{name: "too short", key: base64.StdEncoding.EncodeToString(make([]byte, 16)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTOTPCipher(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	_ "github.com/ITine-Tech/blog/docs"
 	"github.com/ITine-Tech/blog/internal/store"
@@ -13,7 +15,30 @@ import (
 
 type userKey string
 
-const userCTx userKey = "userID"
+// userCTx holds the authenticated user, targetUserCtx the user a /users/{userID} route is about.
+const (
+	userCTx       userKey = "userID"
+	targetUserCtx userKey = "targetUser"
+)
+
+// UserWithSuspension is a user with their suspension, as shown to themselves and to holders of users:manage.
+type UserWithSuspension struct {
+	*store.User
+	SuspendedUntil   *time.Time `json:"suspended_until,omitempty"`
+	SuspensionReason *string    `json:"suspension_reason,omitempty"`
+}
+
+func withSuspension(user *store.User) UserWithSuspension {
+	return UserWithSuspension{User: user, SuspendedUntil: user.SuspendedUntil, SuspensionReason: user.SuspensionReason}
+}
+
+// profile returns the user as the authenticated user may see them: with their suspension only if it is their own profile.
+func profile(r *http.Request, user *store.User) any {
+	if user.ID == getUserFromCtx(r).ID {
+		return withSuspension(user)
+	}
+	return user
+}
 
 type UpdateUserPayload struct {
 	Username *string `json:"username" //validate:"omitempty,max=100"`
@@ -53,38 +78,51 @@ func (app *application) activateUserHandler(w http.ResponseWriter, r *http.Reque
 
 // GetAllUsers godoc
 //
-//	@Summary		Fetches all user profiles
-//	@Description	Fetches all user profiles
+//	@Summary		Fetches user profiles
+//	@Description	Fetches a page of the active user profiles, newest first. Inactive accounts are listed under /admin/users.
 //	@Tags			Users
 //	@Accept			json
 //	@Produce		json
-//	@Success		200		{object}	store.User
+//	@Param			limit	query		int		false	"Users per page (1-100)"	default(20)
+//	@Param			offset	query		int		false	"Users to skip"	default(0)
+//	@Param			role	query		string	false	"Name of the role"
+//	@Param			since	query		string	false	"Only users created at or after this RFC 3339 timestamp"
+//	@Param			until	query		string	false	"Only users created before this RFC 3339 timestamp"
+//	@Success		200		{object}	[]store.User
+//	@Failure		400		{object}	error	"Bad Request"
 //	@Failure		500		{object}	error	"Internal Server Error"
 //	@Security		ApiKeyAuth
 //	@Router			/users [get]
 func (app *application) getUsersHandler(w http.ResponseWriter, r *http.Request) {
-	users, err := app.store.Users.GetAllUsers(r.Context())
+	uq, err := parseUserQuery(r)
 	if err != nil {
-		switch err {
-		case store.ErrNotFound:
-			app.notFoundResponse(w, r, err)
-			return
-		default:
-			app.jsonResponse(w, http.StatusInternalServerError, err.Error())
-			return
-		}
+		app.badRequestResponse(w, r, err)
+		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, users); err != nil {
+	active := true
+	uq.Active = &active
+
+	app.writeUsers(w, r, uq)
+}
+
+func (app *application) writeUsers(w http.ResponseWriter, r *http.Request, uq store.UserQuery) {
+	users, err := app.store.Users.GetAllUsers(r.Context(), uq)
+	if err != nil {
 		app.internalServerError(w, r, err)
+		return
 	}
 
+	if err := app.jsonResponse(w, http.StatusOK, users); err != nil {
+		app.internalServerError(w, r, err)
+	}
 }
 
 // GetUserByID godoc
 //
 //	@Summary		Fetches a user profile by ID
-//	@Description	Fetches a user profile by ID
+//	@Description	Fetches a user profile by ID, with the number of followers and followed users. The ETag header carries the version of the profile.
+//	@Description	Users see their own suspension, if any; the suspensions of others are only listed under /admin/users.
 //	@Tags			Users
 //	@Accept			json
 //	@Produce		json
@@ -107,9 +145,17 @@ func (app *application) getUsersHandler(w http.ResponseWriter, r *http.Request)
 // Returns:
 // - No explicit return value. Writes the response directly to the http.ResponseWriter.
 func (app *application) getUserByIDHandler(w http.ResponseWriter, r *http.Request) {
-	user := getUserFromCtx(r)
+	user := getTargetUserFromCtx(r)
+
+	counts, err := app.store.Follows.Counts(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	user.Follows = counts
 
-	if err := app.jsonResponse(w, http.StatusOK, user); err != nil {
+	w.Header().Set("ETag", etag(user.Version))
+	if err := app.jsonResponse(w, http.StatusOK, profile(r, user)); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -118,19 +164,32 @@ func (app *application) getUserByIDHandler(w http.ResponseWriter, r *http.Reques
 // UpdateUser godoc
 //
 //	@Summary		Updates a user profile by ID
-//	@Description	Updates a user profile by ID
+//	@Description	Updates a user profile by ID. The If-Match header has to contain the ETag of the version that was edited.
+//	@Description	Users can only update their own profile. With the users:manage permission, every profile can be updated.
+//	@Description	Role and activation are changed under /admin/users/{userID}.
 //	@Tags			Users
 //	@Accept			json
 //	@Produce		json
 //	@Param			userID	path		string	true	"User ID"
+//	@Param			If-Match	header	string	true	"ETag of the edited version"
 //	@Param			payload body		UpdateUserPayload true	"payload"
 //	@Success		200		{object}	store.User
 //	@Failure		400		{object}	error	"Bad Request"
+//	@Failure		403		{object}	error	"Forbidden"
+//	@Failure		404		{object}	error	"Not found"
+//	@Failure		409		{object}	error	"Conflict"
+//	@Failure		412		{object}	error	"Precondition Failed"
+//	@Failure		428		{object}	error	"Precondition Required"
 //	@Failure		500		{object}	error	"Internal Server Error"
 //	@Security		ApiKeyAuth
 //	@Router			/users/{userID} [patch]
 func (app *application) updateUserHandler(w http.ResponseWriter, r *http.Request) {
-	user := getUserFromCtx(r)
+	user := getTargetUserFromCtx(r)
+	ctx := r.Context()
+
+	if !app.checkIfMatch(w, r, user.Version) {
+		return
+	}
 
 	var payload UpdateUserPayload
 	if err := readJSON(w, r, &payload); err != nil {
@@ -145,12 +204,22 @@ func (app *application) updateUserHandler(w http.ResponseWriter, r *http.Request
 		user.Email = *payload.Email
 	}
 
-	if err := app.store.Users.UpdateUser(r.Context(), user); err != nil {
-		app.internalServerError(w, r, err)
+	if err := app.store.Users.UpdateUser(ctx, user); err != nil {
+		switch {
+		case errors.Is(err, store.ErrConflict):
+			app.conflictResponse(w, r, err)
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, store.ErrDuplicateEmail), errors.Is(err, store.ErrDuplicateUsername):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
 		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, user); err != nil {
+	w.Header().Set("ETag", etag(user.Version))
+	if err := app.jsonResponse(w, http.StatusOK, profile(r, user)); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -159,17 +228,19 @@ func (app *application) updateUserHandler(w http.ResponseWriter, r *http.Request
 // DeleteUser godoc
 //
 //	@Summary		Deletes a user profile
-//	@Description	Deletes a user profile
+//	@Description	Deletes a user profile. Users can only delete their own profile, with the users:manage permission every profile.
 //	@Tags			Users
 //	@Accept			json
 //	@Produce		json
 //	@Param			userID	path		string	true	"User ID"
+//	@Success		204
+//	@Failure		403		{object}	error	"Forbidden"
 //	@Failure		404		{object}	error	"Not found"
 //	@Failure		500		{object}	error	"Internal Server Error"
 //	@Security		ApiKeyAuth
 //	@Router			/users/{userID} [delete]
 func (app *application) deleteUserHandler(w http.ResponseWriter, r *http.Request) {
-	user := getUserFromCtx(r)
+	user := getTargetUserFromCtx(r)
 
 	if err := app.store.Users.DeleteUser(r.Context(), user.ID); err != nil {
 		switch {
@@ -180,15 +251,16 @@ func (app *application) deleteUserHandler(w http.ResponseWriter, r *http.Request
 		}
 		return
 	}
-	w.WriteHeader(http.StatusNotFound)
+	w.WriteHeader(http.StatusNoContent)
 }
 
+// userContextMiddleware loads the user of the path into targetUserCtx. The authenticated user stays in userCTx.
 func (app *application) userContextMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		strID := chi.URLParam(r, "userID")
 		userID, err := uuid.Parse(strID)
 		if err != nil {
-			app.internalServerError(w, r, err)
+			app.badRequestResponse(w, r, fmt.Errorf("invalid user ID: %w", err))
 			return
 		}
 
@@ -206,12 +278,19 @@ func (app *application) userContextMiddleware(next http.Handler) http.Handler {
 			}
 			return
 		}
-		ctx = context.WithValue(ctx, userCTx, user)
+		ctx = context.WithValue(ctx, targetUserCtx, user)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// getUserFromCtx returns the authenticated user, or nil for anonymous requests.
 func getUserFromCtx(r *http.Request) *store.User {
 	user, _ := r.Context().Value(userCTx).(*store.User)
 	return user
 }
+
+// getTargetUserFromCtx returns the user loaded by userContextMiddleware.
+func getTargetUserFromCtx(r *http.Request) *store.User {
+	user, _ := r.Context().Value(targetUserCtx).(*store.User)
+	return user
+}
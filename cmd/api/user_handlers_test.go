@@ -1,8 +1,16 @@
 package main
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/store"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 func TestGetUserByIDHandler(t *testing.T) {
@@ -38,3 +46,174 @@ func TestGetUserByIDHandler(t *testing.T) {
 		checkResponseCode(t, http.StatusOK, rr.Code)
 	})
 }
+
+func TestUpdateUserHandler_IfMatch(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	testToken, err := app.authenticator.GenerateToken(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		ifMatch        string
+		expectedStatus int
+	}{
+		{name: "missing If-Match", ifMatch: "", expectedStatus: http.StatusPreconditionRequired},
+		{name: "stale version", ifMatch: `"5"`, expectedStatus: http.StatusPreconditionFailed},
+		{name: "current version", ifMatch: `"3", "0"`, expectedStatus: http.StatusOK},
+		{name: "any version", ifMatch: "*", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPatch, "/users/7831ef38-724e-4543-b3bd-51e980f88541", strings.NewReader(`{"username":"gopher"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header.Set("Authorization", "Bearer "+testToken)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+
+			if rr.Code != http.StatusPreconditionRequired && rr.Header().Get("ETag") == "" {
+				t.Error("expected an ETag header")
+			}
+		})
+	}
+}
+
+func TestUserHandlers_Authorization(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	self := &store.User{ID: uuid.New(), Username: "self", Role: store.Role{ID: 1, Name: "user", Level: 1}, IsActive: true}
+	other := &store.User{ID: uuid.New(), Username: "other", Role: store.Role{ID: 1, Name: "user", Level: 1}, IsActive: true}
+	admin := &store.User{ID: uuid.New(), Username: "admin", Role: store.Role{ID: 3, Name: "admin", Level: 3}, IsActive: true}
+	app.store.Users = &store.MockUserStore{Users: []*store.User{self, other, admin}}
+
+	token := func(user *store.User) string {
+		t.Helper()
+
+		token, err := app.authenticator.GenerateToken(jwt.MapClaims{
+			"sub": user.ID.String(),
+			"jti": uuid.NewString(),
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		principal      *store.User
+		target         string
+		body           string
+		expectedStatus int
+	}{
+		{name: "update own profile", method: http.MethodPatch, principal: self, target: self.ID.String(), body: `{"username":"renamed"}`, expectedStatus: http.StatusOK},
+		{name: "update another profile", method: http.MethodPatch, principal: self, target: other.ID.String(), body: `{"username":"renamed"}`, expectedStatus: http.StatusForbidden},
+		{name: "promote yourself", method: http.MethodPatch, principal: self, target: self.ID.String(), body: `{"role":"admin"}`, expectedStatus: http.StatusBadRequest},
+		{name: "reactivate yourself", method: http.MethodPatch, principal: self, target: self.ID.String(), body: `{"is_active":true}`, expectedStatus: http.StatusBadRequest},
+		{name: "admin updates another profile", method: http.MethodPatch, principal: admin, target: other.ID.String(), body: `{"username":"renamed"}`, expectedStatus: http.StatusOK},
+		{name: "admin promotes themselves", method: http.MethodPatch, principal: admin, target: admin.ID.String(), body: `{"role":"admin"}`, expectedStatus: http.StatusBadRequest},
+		{name: "admin reactivates themselves", method: http.MethodPatch, principal: admin, target: admin.ID.String(), body: `{"is_active":true}`, expectedStatus: http.StatusBadRequest},
+		{name: "delete another profile", method: http.MethodDelete, principal: self, target: other.ID.String(), expectedStatus: http.StatusForbidden},
+		{name: "delete own profile", method: http.MethodDelete, principal: self, target: self.ID.String(), expectedStatus: http.StatusNoContent},
+		{name: "admin deletes another profile", method: http.MethodDelete, principal: admin, target: other.ID.String(), expectedStatus: http.StatusNoContent},
+		{name: "invalid user ID", method: http.MethodDelete, principal: self, target: "gopher", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, "/users/"+tt.target, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token(tt.principal))
+			req.Header.Set("If-Match", "*")
+
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestUserHandlers_Suspension(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	reason := "spam"
+	until := time.Now().Add(time.Hour)
+	suspended := &store.User{ID: uuid.New(), Username: "suspended", Role: store.Role{ID: 1, Name: "user", Level: 1}, IsActive: true, SuspensionReason: &reason, SuspendedUntil: &until}
+	user := &store.User{ID: uuid.New(), Username: "user", Role: store.Role{ID: 1, Name: "user", Level: 1}, IsActive: true}
+	admin := &store.User{ID: uuid.New(), Username: "admin", Role: store.Role{ID: 3, Name: "admin", Level: 3}, IsActive: true}
+	app.store.Users = &store.MockUserStore{Users: []*store.User{suspended, user, admin}}
+
+	token := func(user *store.User) string {
+		t.Helper()
+
+		token, err := app.authenticator.GenerateToken(jwt.MapClaims{
+			"sub": user.ID.String(),
+			"jti": uuid.NewString(),
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name           string
+		principal      *store.User
+		target         string
+		wantSuspension bool
+	}{
+		{name: "list", principal: user, target: "/users"},
+		{name: "profile of another user", principal: user, target: "/users/" + suspended.ID.String()},
+		{name: "profile of another user as admin", principal: admin, target: "/users/" + suspended.ID.String()},
+		{name: "admin list", principal: admin, target: "/admin/users", wantSuspension: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.target, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token(tt.principal))
+
+			rr := executeRequest(req, mux)
+
+			checkResponseCode(t, http.StatusOK, rr.Code)
+			if got := strings.Contains(rr.Body.String(), "suspension_reason"); got != tt.wantSuspension {
+				t.Errorf("expected the suspension shown to be %v, got %s", tt.wantSuspension, rr.Body.String())
+			}
+		})
+	}
+
+	t.Run("own profile", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/"+suspended.ID.String(), nil)
+		ctx := context.WithValue(req.Context(), targetUserCtx, suspended)
+		ctx = context.WithValue(ctx, userCTx, suspended)
+
+		rr := executeRequest(req.WithContext(ctx), http.HandlerFunc(app.getUserByIDHandler))
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if !strings.Contains(rr.Body.String(), `"suspension_reason":"spam"`) {
+			t.Errorf("expected the own suspension, got %s", rr.Body.String())
+		}
+	})
+}
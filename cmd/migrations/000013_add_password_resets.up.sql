@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS password_resets (
+    token TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expiry TIMESTAMP(0) WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_resets_user_id ON password_resets (user_id);
+
+-- kept at full precision: TIMESTAMP(0) would round up to the next second and reject
+-- tokens issued right after the change, the comparison truncates it instead
+ALTER TABLE users
+ADD column password_changed_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_posts_tags;
+
+DROP INDEX IF EXISTS idx_posts_user_id;
+
+DROP INDEX IF EXISTS idx_posts_created_at_id;
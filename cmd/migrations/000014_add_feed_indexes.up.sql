@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS idx_posts_created_at_id ON posts (created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_posts_user_id ON posts (user_id);
+
+CREATE INDEX IF NOT EXISTS idx_posts_tags ON posts USING gin (tags);
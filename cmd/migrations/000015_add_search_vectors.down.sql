@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_comments_search_vector;
+
+ALTER TABLE comments
+DROP COLUMN search_vector;
+
+DROP INDEX IF EXISTS idx_posts_search_vector;
+
+ALTER TABLE posts
+DROP COLUMN search_vector;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_posts_scheduled;
+
+ALTER TABLE posts
+DROP COLUMN published_at;
+
+ALTER TABLE posts
+DROP COLUMN status;
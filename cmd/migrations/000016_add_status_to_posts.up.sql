@@ -0,0 +1,13 @@
+ALTER TABLE posts
+ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'published'
+    CHECK (status IN ('draft', 'scheduled', 'published', 'archived'));
+
+ALTER TABLE posts
+ADD COLUMN published_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE
+    posts
+SET
+    published_at = created_at;
+
+CREATE INDEX IF NOT EXISTS idx_posts_scheduled ON posts (published_at) WHERE status = 'scheduled';
@@ -0,0 +1,2 @@
+ALTER TABLE users
+ADD column version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_comments_parent_id_created_at;
+
+DROP INDEX IF EXISTS idx_comments_post_id_created_at;
+
+ALTER TABLE comments
+DROP COLUMN parent_id;
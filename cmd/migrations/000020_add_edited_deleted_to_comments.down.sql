@@ -0,0 +1,5 @@
+ALTER TABLE comments
+DROP COLUMN deleted_at;
+
+ALTER TABLE comments
+DROP COLUMN edited_at;
@@ -0,0 +1,5 @@
+ALTER TABLE comments
+ADD COLUMN edited_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE comments
+ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,16 @@
+UPDATE
+    users
+SET
+    role_id = (SELECT id FROM roles WHERE name = 'user')
+WHERE
+    role_id = (SELECT id FROM roles WHERE name = 'moderator');
+
+DELETE FROM roles WHERE name = 'moderator';
+
+DROP INDEX IF EXISTS idx_comments_status_created_at;
+
+ALTER TABLE posts
+DROP COLUMN moderation;
+
+ALTER TABLE comments
+DROP COLUMN status;
@@ -0,0 +1,19 @@
+ALTER TABLE comments
+ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'approved'
+    CHECK (status IN ('pending', 'approved', 'rejected'));
+
+ALTER TABLE posts
+ADD COLUMN moderation VARCHAR(20)
+    CHECK (moderation IN ('open', 'first_time', 'all'));
+
+CREATE INDEX IF NOT EXISTS idx_comments_status_created_at ON comments (status, created_at);
+
+INSERT INTO
+    roles (name, description, level)
+VALUES
+    (
+        'moderator',
+        'A moderator can approve and reject comments',
+        2
+    )
+ON CONFLICT (name) DO NOTHING;
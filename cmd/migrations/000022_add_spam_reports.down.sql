@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS spam_reports;
+
+DROP INDEX IF EXISTS idx_comments_user_id_created_at;
+
+DROP INDEX IF EXISTS idx_comments_fingerprint;
+
+ALTER TABLE comments
+DROP COLUMN fingerprint;
@@ -0,0 +1,21 @@
+ALTER TABLE comments
+ADD COLUMN fingerprint VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_comments_fingerprint ON comments (fingerprint, created_at) WHERE fingerprint IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_comments_user_id_created_at ON comments (user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS spam_reports (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('comment', 'registration')),
+    comment_id BIGINT REFERENCES comments(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    score REAL NOT NULL,
+    reasons TEXT[] NOT NULL DEFAULT '{}',
+    verdict VARCHAR(20) NOT NULL CHECK (verdict IN ('hold', 'reject')),
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_spam_reports_unreviewed ON spam_reports (created_at) WHERE reviewed_at IS NULL;
@@ -0,0 +1,21 @@
+ALTER TABLE posts
+ADD COLUMN tags TEXT[];
+
+UPDATE
+    posts
+SET
+    tags = ARRAY(
+        SELECT tags.name
+        FROM post_tags
+        JOIN tags ON tags.id = post_tags.tag_id
+        WHERE post_tags.post_id = posts.id
+        ORDER BY tags.name
+    );
+
+CREATE INDEX IF NOT EXISTS idx_posts_tags ON posts USING gin (tags);
+
+DROP TABLE IF EXISTS post_tags;
+
+DROP TABLE IF EXISTS tags;
+
+DROP FUNCTION IF EXISTS slugify(TEXT);
@@ -0,0 +1,64 @@
+-- slugify mirrors slug.Make, so backfilled tags get the same slugs as new ones:
+-- slugs longer than 100 characters are cut at the last hyphen within them
+CREATE OR REPLACE FUNCTION slugify(value TEXT) RETURNS TEXT AS $$
+    SELECT
+        CASE
+            WHEN length(s) <= 100 THEN s
+            ELSE trim(BOTH '-' FROM coalesce(substring(left(s, 100) FROM '^(.+)-'), left(s, 100)))
+        END
+    FROM (
+        SELECT trim(BOTH '-' FROM regexp_replace(
+            replace(replace(replace(replace(
+                translate(
+                    lower(value),
+                    'àáâãäåāăąçćĉċčðďđèéêëēĕėęěĝğġģĥħìíîïĩīĭįıĵķĺļľŀłñńņňòóôõöøōŏőŕŗřśŝşšţťŧùúûüũūŭůűųŵýÿŷźżž',
+                    'aaaaaaaaacccccdddeeeeeeeeegggghhiiiiiiiiijklllllnnnnooooooooorrrsssstttuuuuuuuuuuwyyyzzz'
+                ),
+                'æ', 'ae'), 'œ', 'oe'), 'ß', 'ss'), 'þ', 'th'),
+            '[^a-z0-9]+', '-', 'g'
+        )) AS s
+    ) AS slug
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE TABLE IF NOT EXISTS tags (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS post_tags (
+    post_id BIGINT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (post_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_tags_tag_id ON post_tags (tag_id);
+
+-- "Go" and "go" become one tag; the name that comes first alphabetically wins,
+-- cut to the length of tags.name
+INSERT INTO
+    tags (name, slug)
+SELECT DISTINCT ON (slugify(tag))
+    left(trim(tag), 100), slugify(tag)
+FROM
+    posts, unnest(tags) AS tag
+WHERE
+    slugify(tag) <> ''
+ORDER BY
+    slugify(tag), left(trim(tag), 100)
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO
+    post_tags (post_id, tag_id)
+SELECT DISTINCT
+    posts.id, tags.id
+FROM
+    posts, unnest(posts.tags) AS tag
+    JOIN tags ON tags.slug = slugify(tag)
+ON CONFLICT DO NOTHING;
+
+DROP INDEX IF EXISTS idx_posts_tags;
+
+ALTER TABLE posts
+DROP COLUMN tags;
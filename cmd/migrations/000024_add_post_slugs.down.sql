@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS post_slugs;
+
+ALTER TABLE posts
+DROP COLUMN IF EXISTS slug;
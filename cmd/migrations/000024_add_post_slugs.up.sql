@@ -0,0 +1,48 @@
+ALTER TABLE posts
+ADD COLUMN slug VARCHAR(120);
+
+-- posts with the same title are numbered in the order they were created, like new posts are
+UPDATE
+    posts
+SET
+    slug = numbered.slug
+FROM (
+    SELECT
+        id,
+        base || CASE WHEN n > 1 THEN '-' || n ELSE '' END AS slug
+    FROM (
+        SELECT
+            id,
+            base,
+            ROW_NUMBER() OVER (PARTITION BY base ORDER BY created_at, id) AS n
+        FROM (
+            SELECT id, created_at, COALESCE(NULLIF(slugify(title), ''), 'post') AS base
+            FROM posts
+        ) bases
+    ) counted
+) numbered
+WHERE
+    posts.id = numbered.id;
+
+-- a numbered slug can still match the title of another post, e.g. "Hello" and "Hello 2"
+UPDATE
+    posts
+SET
+    slug = slug || '-' || id
+WHERE
+    EXISTS (SELECT 1 FROM posts other WHERE other.slug = posts.slug AND other.id < posts.id);
+
+ALTER TABLE posts
+ALTER COLUMN slug SET NOT NULL;
+
+ALTER TABLE posts
+ADD CONSTRAINT posts_slug_key UNIQUE (slug);
+
+-- former slugs of posts, so that old links can be redirected
+CREATE TABLE IF NOT EXISTS post_slugs (
+    slug VARCHAR(120) PRIMARY KEY,
+    post_id BIGINT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_slugs_post_id ON post_slugs (post_id);
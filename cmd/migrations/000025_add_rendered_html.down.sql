@@ -0,0 +1,7 @@
+ALTER TABLE comments
+DROP COLUMN IF EXISTS html_version,
+DROP COLUMN IF EXISTS html;
+
+ALTER TABLE posts
+DROP COLUMN IF EXISTS html_version,
+DROP COLUMN IF EXISTS html;
@@ -0,0 +1,8 @@
+-- html caches the rendered Markdown; rows rendered by an older html_version are rendered again
+ALTER TABLE posts
+ADD COLUMN html TEXT NOT NULL DEFAULT '',
+ADD COLUMN html_version SMALLINT NOT NULL DEFAULT 0;
+
+ALTER TABLE comments
+ADD COLUMN html TEXT NOT NULL DEFAULT '',
+ADD COLUMN html_version SMALLINT NOT NULL DEFAULT 0;
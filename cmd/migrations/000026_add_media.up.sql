@@ -0,0 +1,17 @@
+-- checksum and thumbnail_checksum are the keys of the files in the blob store
+CREATE TABLE IF NOT EXISTS media (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    filename VARCHAR(255) NOT NULL DEFAULT '',
+    mime_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    thumbnail_mime_type VARCHAR(100) NOT NULL,
+    thumbnail_size BIGINT NOT NULL,
+    thumbnail_checksum CHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_user_id ON media (user_id);
@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS permissions (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id BIGINT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id BIGINT NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+INSERT INTO
+    permissions (name, description)
+VALUES
+    ('posts:read:any', 'Read unpublished posts of other users'),
+    ('posts:update:any', 'Edit and restore posts of other users'),
+    ('posts:delete:any', 'Delete posts of other users'),
+    ('comments:update:any', 'Edit comments of other users'),
+    ('comments:delete:any', 'Delete comments of other users'),
+    ('comments:moderate', 'Approve and reject comments and review spam reports'),
+    ('tags:manage', 'Rename and merge tags'),
+    ('users:manage', 'Edit and delete other users and change their role and activation'),
+    ('roles:manage', 'Create and edit roles and assign permissions')
+ON CONFLICT (name) DO NOTHING;
+
+-- the existing roles get the permissions their level granted so far
+INSERT INTO
+    role_permissions (role_id, permission_id)
+SELECT
+    r.id,
+    p.id
+FROM
+    roles r
+    JOIN permissions p ON p.name = 'comments:moderate'
+WHERE
+    r.name = 'moderator'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO
+    role_permissions (role_id, permission_id)
+SELECT
+    r.id,
+    p.id
+FROM
+    roles r
+    CROSS JOIN permissions p
+WHERE
+    r.name = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS user_suspensions;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS suspension_reason,
+DROP COLUMN IF EXISTS suspended_until;
@@ -0,0 +1,18 @@
+-- a user is suspended while suspension_reason is set and suspended_until has not passed; without suspended_until the user is banned
+ALTER TABLE users
+ADD COLUMN suspended_until TIMESTAMP WITH TIME ZONE,
+ADD COLUMN suspension_reason TEXT;
+
+-- every suspension and ban, with who imposed and who lifted it
+CREATE TABLE IF NOT EXISTS user_suspensions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    suspended_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL,
+    suspended_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    lifted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    lifted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_suspensions_user_id_created_at ON user_suspensions (user_id, created_at);
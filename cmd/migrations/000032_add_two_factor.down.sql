@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS recovery_codes;
+
+ALTER TABLE roles
+DROP COLUMN IF EXISTS requires_two_factor;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS totp_last_counter,
+DROP COLUMN IF EXISTS totp_enabled,
+DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,19 @@
+-- totp_secret is encrypted by the API and set when enrolment starts; totp_enabled once the first code confirmed it.
+-- totp_last_counter is the period of the last accepted code, so that no code is accepted twice.
+ALTER TABLE users
+ADD COLUMN totp_secret TEXT,
+ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN totp_last_counter BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE roles
+ADD COLUMN requires_two_factor BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- recovery codes are stored as SHA-256 hashes and can be used once each
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, code_hash)
+);
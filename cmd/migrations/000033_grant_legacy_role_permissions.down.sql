@@ -0,0 +1 @@
+-- the granted permissions are kept, they cannot be told apart from ones granted by an admin
@@ -0,0 +1,27 @@
+-- the built-in roles that lost their permissions get them back explicitly instead of
+-- relying on the level fallback for roles without permissions
+INSERT INTO
+    role_permissions (role_id, permission_id)
+SELECT
+    r.id,
+    p.id
+FROM
+    roles r
+    JOIN permissions p ON p.name = 'comments:moderate'
+WHERE
+    r.name = 'moderator'
+    AND NOT EXISTS (SELECT 1 FROM role_permissions rp WHERE rp.role_id = r.id)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO
+    role_permissions (role_id, permission_id)
+SELECT
+    r.id,
+    p.id
+FROM
+    roles r
+    CROSS JOIN permissions p
+WHERE
+    r.name = 'admin'
+    AND NOT EXISTS (SELECT 1 FROM role_permissions rp WHERE rp.role_id = r.id)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_posts_feed_time_id ON posts ((COALESCE(published_at, created_at)), id);
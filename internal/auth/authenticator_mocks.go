@@ -12,6 +12,7 @@ const secret = "test"
 
 var testClaims = jwt.MapClaims{
 	"sub": "65ea315e-ca1c-4af8-956b-57ed94378e94",
+	"jti": "0b7e9b5c-3f4a-4d8e-9c61-2f1d7a5e8b30",
 	"exp": time.Now().Add(time.Hour).Unix(),
 	"iss": "test-iss",
 	"aud": "test-aud",
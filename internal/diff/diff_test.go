@@ -0,0 +1,63 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want []Line
+	}{
+		{
+			name: "identical",
+			a:    "one\ntwo",
+			b:    "one\ntwo\n",
+			want: []Line{{OpEqual, "one"}, {OpEqual, "two"}},
+		},
+		{
+			name: "changed line",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			want: []Line{{OpEqual, "one"}, {OpDelete, "two"}, {OpInsert, "2"}, {OpEqual, "three"}},
+		},
+		{
+			name: "appended and removed lines",
+			a:    "a\nb\nc",
+			b:    "b\nc\nd",
+			want: []Line{{OpDelete, "a"}, {OpEqual, "b"}, {OpEqual, "c"}, {OpInsert, "d"}},
+		},
+		{
+			name: "from empty",
+			a:    "",
+			b:    "new\r\ntext",
+			want: []Line{{OpInsert, "new"}, {OpInsert, "text"}},
+		},
+		{
+			name: "to empty",
+			a:    "old",
+			b:    "",
+			want: []Line{{OpDelete, "old"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnified(t *testing.T) {
+	got := Unified(Lines("one\ntwo", "one\nthree"))
+	want := " one\n-two\n+three\n"
+
+	if got != want {
+		t.Errorf("Unified() = %q, want %q", got, want)
+	}
+}
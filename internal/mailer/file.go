@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// FileMailer writes every email as an .eml file into a directory instead of sending it.
+// It is meant for local development; the files can be opened with any mail client.
+type FileMailer struct {
+	dir       string
+	fromEmail string
+}
+
+func NewFileMailer(dir, fromEmail string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &FileMailer{
+		dir:       dir,
+		fromEmail: fromEmail,
+	}, nil
+}
+
+func (m *FileMailer) Send(templateFile, username, email string, data any) error {
+	msg, err := newMessage(m.fromEmail, templateFile, username, email, data)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s_%s.eml", msg.SentAt.Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(email, "_"))
+
+	return os.WriteFile(filepath.Join(m.dir, name), msg.Bytes(), 0o600)
+}
@@ -0,0 +1,93 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/mail"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	FromName   = "Beautiful Blog"
+	maxRetries = 3
+
+	UserInvitationTemplate = "user_invitation.tmpl"
+	PasswordResetTemplate  = "password_reset.tmpl"
+	NotificationTemplate   = "notification.tmpl"
+)
+
+//go:embed templates
+var FS embed.FS
+
+// Mailer sends templated emails. Every template defines a "subject" and a "body" block.
+type Mailer interface {
+	Send(templateFile, username, email string, data any) error
+}
+
+// Message is a rendered email.
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+	SentAt  time.Time
+}
+
+// newMessage renders templateFile with data and addresses the result to username <email>.
+// The subject is plain text, so only the body is HTML escaped.
+func newMessage(from, templateFile, username, email string, data any) (*Message, error) {
+	subjectTmpl, err := texttemplate.ParseFS(FS, "templates/"+templateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := new(bytes.Buffer)
+	if err := subjectTmpl.ExecuteTemplate(subject, "subject", data); err != nil {
+		return nil, err
+	}
+
+	bodyTmpl, err := template.ParseFS(FS, "templates/"+templateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	body := new(bytes.Buffer)
+	if err := bodyTmpl.ExecuteTemplate(body, "body", data); err != nil {
+		return nil, err
+	}
+
+	to := mail.Address{Name: username, Address: email}
+	sender := mail.Address{Name: FromName, Address: from}
+
+	return &Message{
+		From:    sender.String(),
+		To:      to.String(),
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    body.String(),
+		SentAt:  time.Now(),
+	}, nil
+}
+
+// Bytes returns the message in RFC 5322 format, ready to be handed to an SMTP server or written to an .eml file.
+func (m *Message) Bytes() []byte {
+	buf := new(bytes.Buffer)
+
+	fmt.Fprintf(buf, "From: %s\r\n", m.From)
+	fmt.Fprintf(buf, "To: %s\r\n", m.To)
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(buf, "Date: %s\r\n", m.SentAt.Format(time.RFC1123Z))
+	fmt.Fprintf(buf, "Message-ID: <%s@%s>\r\n", uuid.New().String(), "blog")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(m.Body)
+
+	return buf.Bytes()
+}
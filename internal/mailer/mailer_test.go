@@ -0,0 +1,125 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMemoryMailer_Send(t *testing.T) {
+	m := NewMemoryMailer()
+
+	vars := struct {
+		Username      string
+		ActivationURL string
+	}{
+		Username:      "<gopher>",
+		ActivationURL: "http://localhost:3000/confirm/token",
+	}
+
+	if err := m.Send(UserInvitationTemplate, "gopher", "gopher@example.com", vars); err != nil {
+		t.Fatalf("MemoryMailer.Send() error = %v", err)
+	}
+
+	messages := m.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+
+	msg := messages[0]
+	if msg.To != `"gopher" <gopher@example.com>` {
+		t.Errorf("unexpected recipient %q", msg.To)
+	}
+	if msg.Subject != "Finish registration with Beautiful Blog" {
+		t.Errorf("unexpected subject %q", msg.Subject)
+	}
+	if !strings.Contains(msg.Body, vars.ActivationURL) {
+		t.Error("body should contain the activation link")
+	}
+	if !strings.Contains(msg.Body, "&lt;gopher&gt;") {
+		t.Error("template data should be HTML escaped")
+	}
+}
+
+func TestMemoryMailer_PlainTextSubject(t *testing.T) {
+	m := NewMemoryMailer()
+
+	vars := struct {
+		Subject  string
+		Username string
+		Message  string
+		URL      string
+	}{
+		Subject:  "O'Brien replied to your comment",
+		Username: "O'Brien",
+		Message:  "O'Brien replied to your comment",
+	}
+
+	if err := m.Send(NotificationTemplate, "O'Brien", "obrien@example.com", vars); err != nil {
+		t.Fatalf("MemoryMailer.Send() error = %v", err)
+	}
+
+	msg := m.Messages()[0]
+	if msg.Subject != vars.Subject {
+		t.Errorf("subject should not be HTML escaped, got %q", msg.Subject)
+	}
+	if !strings.Contains(msg.Body, "O&#39;Brien") {
+		t.Error("body should be HTML escaped")
+	}
+}
+
+func TestMemoryMailer_UnknownTemplate(t *testing.T) {
+	m := NewMemoryMailer()
+
+	if err := m.Send("unknown.tmpl", "gopher", "gopher@example.com", nil); err == nil {
+		t.Error("expected an error for an unknown template")
+	}
+}
+
+func TestFileMailer_Send(t *testing.T) {
+	dir := t.TempDir()
+
+	m, err := NewFileMailer(dir, "noreply@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vars := struct {
+		Username  string
+		ResetURL  string
+		ExpiresIn string
+	}{
+		Username:  "gopher",
+		ResetURL:  "http://localhost:3000/password/reset?token=token",
+		ExpiresIn: "1h0m0s",
+	}
+
+	if err := m.Send(PasswordResetTemplate, "gopher", "gopher@example.com", vars); err != nil {
+		t.Fatalf("FileMailer.Send() error = %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected 1 .eml file, got %d", len(files))
+	}
+
+	content, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"To: \"gopher\" <gopher@example.com>\r\n",
+		"Subject: Reset your Beautiful Blog password\r\n",
+		"Content-Type: text/html; charset=\"UTF-8\"\r\n",
+		vars.ResetURL,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("eml file should contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+type SMTPMailer struct {
+	host      string
+	port      int
+	username  string
+	password  string
+	fromEmail string
+}
+
+func NewSMTPMailer(host string, port int, username, password, fromEmail string) *SMTPMailer {
+	return &SMTPMailer{
+		host:      host,
+		port:      port,
+		username:  username,
+		password:  password,
+		fromEmail: fromEmail,
+	}
+}
+
+// Send renders the template and delivers it through the SMTP server.
+// Failed deliveries are retried with an exponential backoff.
+func (m *SMTPMailer) Send(templateFile, username, email string, data any) error {
+	msg, err := newMessage(m.fromEmail, templateFile, username, email, data)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	addr := net.JoinHostPort(m.host, strconv.Itoa(m.port))
+
+	for i := 0; i < maxRetries; i++ {
+		err = smtp.SendMail(addr, auth, m.fromEmail, []string{email}, msg.Bytes())
+		if err == nil {
+			return nil
+		}
+
+		log.Printf("failed to send email to %v, attempt %d of %d: %v", email, i+1, maxRetries, err)
+		time.Sleep(time.Second * time.Duration(i+1))
+	}
+
+	return fmt.Errorf("failed to send email after %d attempts: %w", maxRetries, err)
+}
@@ -0,0 +1,50 @@
+// Package markdown renders the Markdown of posts and comments to sanitized HTML.
+package markdown
+
+import (
+	"github.com/russross/blackfriday/v2"
+)
+
+// Version identifies the output of Render. It changes whenever the rendering or the
+// sanitization rules change, so HTML cached by an older version can be rendered again.
+const Version = 1
+
+// Options controls what the rendered HTML may contain.
+type Options struct {
+	// HeadingAnchors gives headings ids, so that sections can be linked.
+	HeadingAnchors bool
+	// Images allows images. Without them only the alt text is kept.
+	Images bool
+	// NoFollow marks links as user generated, so search engines do not follow them.
+	NoFollow bool
+}
+
+var (
+	// Post is meant for the text of posts, which is written by the blog's authors.
+	Post = Options{HeadingAnchors: true, Images: true}
+	// Comment is meant for comments, which anyone can write.
+	Comment = Options{NoFollow: true}
+)
+
+// extensions are GitHub flavoured: tables, fenced code blocks, autolinks and strikethrough.
+const extensions = blackfriday.NoIntraEmphasis | blackfriday.Tables | blackfriday.FencedCode |
+	blackfriday.Autolink | blackfriday.Strikethrough | blackfriday.SpaceHeadings |
+	blackfriday.BackslashLineBreak | blackfriday.NoEmptyLineBeforeBlock
+
+// Render turns Markdown into HTML that only contains allowed elements and attributes.
+// Fenced code blocks keep their language as class, e.g. "language-go".
+func Render(source string, opts Options) string {
+	if source == "" {
+		return ""
+	}
+
+	ext := extensions
+	if opts.HeadingAnchors {
+		ext |= blackfriday.AutoHeadingIDs | blackfriday.HeadingIDs
+	}
+
+	renderer := blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{})
+	html := blackfriday.Run([]byte(source), blackfriday.WithExtensions(ext), blackfriday.WithRenderer(renderer))
+
+	return Sanitize(string(html), opts)
+}
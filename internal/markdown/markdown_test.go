@@ -0,0 +1,126 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		opts     Options
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "emphasis and lists",
+			source:   "Some *emphasis* and **strong** text\n\n- one\n- two\n",
+			opts:     Post,
+			contains: []string{"<em>emphasis</em>", "<strong>strong</strong>", "<ul>", "<li>one</li>"},
+		},
+		{
+			name:     "fenced code with language",
+			source:   "```go\nfmt.Println(\"<hi>\")\n```\n",
+			opts:     Post,
+			contains: []string{`<pre><code class="language-go">fmt.Println(&#34;&lt;hi&gt;&#34;)`},
+		},
+		{
+			name:     "heading anchors in posts",
+			source:   "## Getting Started\n\nText\n",
+			opts:     Post,
+			contains: []string{`<h2 id="getting-started">Getting Started</h2>`},
+		},
+		{
+			name:     "no heading anchors in comments",
+			source:   "## Getting Started\n",
+			opts:     Comment,
+			contains: []string{"<h2>Getting Started</h2>"},
+		},
+		{
+			name:     "tables and strikethrough",
+			source:   "| a | b |\n|:--|--:|\n| 1 | 2 |\n\n~~gone~~\n",
+			opts:     Post,
+			contains: []string{"<table>", `<td align="left">1</td>`, "<del>gone</del>"},
+		},
+		{
+			name:     "comment links are nofollow",
+			source:   "[site](https://example.com) and https://go.dev",
+			opts:     Comment,
+			contains: []string{`<a href="https://example.com" rel="nofollow ugc">site</a>`, `<a href="https://go.dev" rel="nofollow ugc">`},
+		},
+		{
+			name:     "post links are followed",
+			source:   "[site](https://example.com)",
+			opts:     Post,
+			contains: []string{`<a href="https://example.com">site</a>`},
+		},
+		{
+			name:     "images only in posts",
+			source:   "![a gopher](https://go.dev/gopher.png)",
+			opts:     Comment,
+			contains: []string{"a gopher"},
+			excludes: []string{"<img"},
+		},
+		{
+			name:     "images in posts",
+			source:   "![a gopher](https://go.dev/gopher.png)",
+			opts:     Post,
+			contains: []string{`<img src="https://go.dev/gopher.png" alt="a gopher">`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(tt.source, tt.opts)
+
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("expected %q in\n%s", want, got)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("did not expect %q in\n%s", unwanted, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRender_Sanitizes(t *testing.T) {
+	attacks := []string{
+		"<script>alert(1)</script>",
+		"<img src=x onerror=alert(1)>",
+		"[click](javascript:alert(1))",
+		"[click](JaVaScRiPt:alert(1))",
+		`<a href="jav&#x09;ascript:alert(1)">click</a>`,
+		`<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">click</a>`,
+		`<p onclick="alert(1)" style="color:red">text</p>`,
+		`<svg><script>alert(1)</script></svg>`,
+		`<iframe src="https://evil.example"></iframe>`,
+		"<style>body{display:none}</style>",
+		`<div><p>nested <span onmouseover="alert(1)">span</span></p></div>`,
+		`<math><mi xlink:href="javascript:alert(1)">x</mi></math>`,
+		`<code class="language-go onload">x</code>`,
+	}
+
+	for _, opts := range []Options{Post, Comment} {
+		for _, attack := range attacks {
+			got := strings.ToLower(Render(attack, opts))
+
+			for _, unwanted := range []string{"<script", "javascript:", "data:", "onerror", "onclick", "onmouseover", "onload", "style", "<svg", "<iframe", "<math", "<div", "<span"} {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("Render(%q) = %q contains %q", attack, got, unwanted)
+				}
+			}
+		}
+	}
+}
+
+func TestSanitize_KeepsText(t *testing.T) {
+	got := Sanitize(`<div class="x"><p>Hello <span>world</span> &amp; you</p></div>`, Post)
+	if got != "<p>Hello world &amp; you</p>" {
+		t.Errorf("unexpected output %q", got)
+	}
+}
@@ -0,0 +1,182 @@
+package markdown
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedElements are kept with their allowed attributes. Other elements are dropped, but their text is kept.
+var allowedElements = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.Hr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Code: true, atom.Kbd: true,
+	atom.Em: true, atom.Strong: true, atom.Del: true, atom.S: true, atom.Sup: true, atom.Sub: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.A: true, atom.Img: true,
+	atom.Table: true, atom.Thead: true, atom.Tbody: true, atom.Tr: true, atom.Th: true, atom.Td: true,
+}
+
+// removedElements are dropped together with everything inside them.
+var removedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Frame: true, atom.Frameset: true,
+	atom.Object: true, atom.Embed: true, atom.Applet: true, atom.Template: true, atom.Noscript: true,
+	atom.Textarea: true, atom.Select: true, atom.Title: true, atom.Head: true, atom.Form: true,
+}
+
+var voidElements = map[atom.Atom]bool{
+	atom.Br: true, atom.Hr: true, atom.Img: true,
+}
+
+var (
+	languageClass = regexp.MustCompile(`^language-[A-Za-z0-9_+#.-]+$`)
+	headingID     = regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)
+	number        = regexp.MustCompile(`^[0-9]{1,9}$`)
+)
+
+// Sanitize removes everything from an HTML fragment that is not explicitly allowed:
+// scripts, styles, event handlers, inline styles, unknown attributes and links to
+// anything but http, https and mailto URLs.
+func Sanitize(fragment string, opts Options) string {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), body)
+	if err != nil {
+		return html.EscapeString(fragment)
+	}
+
+	var b strings.Builder
+	for _, n := range nodes {
+		sanitizeNode(&b, n, opts)
+	}
+	return b.String()
+}
+
+func sanitizeNode(b *strings.Builder, n *html.Node, opts Options) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	// elements of embedded SVG and MathML have a namespace
+	if n.Namespace != "" || removedElements[n.DataAtom] {
+		return
+	}
+
+	if n.DataAtom == atom.Img && (!opts.Images || !safeURL(attribute(n, "src"), "http", "https")) {
+		b.WriteString(html.EscapeString(attribute(n, "alt")))
+		return
+	}
+
+	allowed := allowedElements[n.DataAtom]
+	if allowed {
+		b.WriteByte('<')
+		b.WriteString(n.Data)
+		for _, a := range allowedAttributes(n, opts) {
+			b.WriteString(" " + a.Key + `="` + html.EscapeString(a.Val) + `"`)
+		}
+		b.WriteByte('>')
+
+		if voidElements[n.DataAtom] {
+			return
+		}
+
+		// the parser drops a newline right after <pre>, so it has to be written twice to survive
+		if n.DataAtom == atom.Pre && n.FirstChild != nil && n.FirstChild.Type == html.TextNode && strings.HasPrefix(n.FirstChild.Data, "\n") {
+			b.WriteByte('\n')
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sanitizeNode(b, c, opts)
+	}
+
+	if allowed {
+		b.WriteString("</" + n.Data + ">")
+	}
+}
+
+func allowedAttributes(n *html.Node, opts Options) []html.Attribute {
+	var attrs []html.Attribute
+	seen := map[string]bool{}
+
+	for _, a := range n.Attr {
+		if a.Namespace != "" || seen[a.Key] || !allowedAttribute(n.DataAtom, a, opts) {
+			continue
+		}
+		seen[a.Key] = true
+		attrs = append(attrs, html.Attribute{Key: a.Key, Val: a.Val})
+	}
+
+	if n.DataAtom == atom.A && opts.NoFollow {
+		attrs = append(attrs, html.Attribute{Key: "rel", Val: "nofollow ugc"})
+	}
+	return attrs
+}
+
+func allowedAttribute(element atom.Atom, a html.Attribute, opts Options) bool {
+	switch element {
+	case atom.A:
+		switch a.Key {
+		case "href":
+			return safeURL(a.Val, "http", "https", "mailto")
+		case "title":
+			return true
+		}
+	case atom.Img:
+		switch a.Key {
+		case "src":
+			return safeURL(a.Val, "http", "https")
+		case "alt", "title":
+			return true
+		}
+	case atom.Code:
+		return a.Key == "class" && languageClass.MatchString(a.Val)
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		return a.Key == "id" && opts.HeadingAnchors && headingID.MatchString(a.Val)
+	case atom.Th, atom.Td:
+		return a.Key == "align" && (a.Val == "left" || a.Val == "center" || a.Val == "right")
+	case atom.Ol:
+		return a.Key == "start" && number.MatchString(a.Val)
+	}
+	return false
+}
+
+// safeURL reports whether raw is a relative URL or an absolute one with one of the schemes.
+func safeURL(raw string, schemes ...string) bool {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return false
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" {
+		return true
+	}
+
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+func attribute(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+var validKey = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// BlobStore keeps the files of uploads. Blobs are addressed by the SHA-256 of their
+// content, so the same file uploaded twice is only stored once and never changes.
+type BlobStore interface {
+	// Put stores the content of r and returns its key.
+	Put(context.Context, io.Reader) (string, error)
+	// Open returns the blob stored under key or ErrBlobNotFound.
+	Open(context.Context, string) (io.ReadSeekCloser, error)
+}
+
+// Key returns the key data is stored under.
+func Key(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// LocalBlobStore keeps blobs in a directory, fanned out by the first bytes of their
+// key, e.g. 3a/7b/3a7b….
+type LocalBlobStore struct {
+	dir string
+}
+
+func NewLocalBlobStore(dir string) (*LocalBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &LocalBlobStore{dir: dir}, nil
+}
+
+// Put writes r to a temporary file first and moves it into place once it is complete,
+// so readers never see a partial blob.
+func (s *LocalBlobStore) Put(ctx context.Context, r io.Reader) (string, error) {
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), r); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	key := hex.EncodeToString(hash.Sum(nil))
+	path := s.path(key)
+
+	if _, err := os.Stat(path); err == nil {
+		return key, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o640); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func (s *LocalBlobStore) Open(_ context.Context, key string) (io.ReadSeekCloser, error) {
+	if !validKey.MatchString(key) {
+		return nil, ErrBlobNotFound
+	}
+
+	f, err := os.Open(s.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrBlobNotFound
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *LocalBlobStore) path(key string) string {
+	return filepath.Join(s.dir, key[:2], key[2:4], key)
+}
@@ -0,0 +1,203 @@
+// Package media processes uploaded images and stores their files.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const (
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypeGIF  = "image/gif"
+)
+
+// MaxPixels limits the size of images, as they are decoded into memory to create thumbnails.
+const MaxPixels = 25_000_000
+
+var (
+	ErrUnsupportedType = errors.New("only JPEG, PNG and GIF images can be uploaded")
+	ErrTooManyPixels   = errors.New("the image has too many pixels")
+	ErrInvalidImage    = errors.New("the image cannot be decoded")
+)
+
+// Image is an upload ready to be stored.
+type Image struct {
+	// Data is the image without its metadata.
+	Data     []byte
+	MIMEType string
+	Width    int
+	Height   int
+
+	Thumbnail         []byte
+	ThumbnailMIMEType string
+}
+
+// Process checks that data is a JPEG, PNG or GIF image, strips its metadata and
+// creates a thumbnail that fits into a square of thumbnailSize pixels.
+// The type is sniffed from the content; the file name or a declared type are not trusted.
+//
+// JPEGs that are rotated by their EXIF orientation are rotated for good and encoded again,
+// because the orientation is lost with the metadata. GIFs are kept as they are, only their
+// first frame is used for the thumbnail.
+func Process(data []byte, thumbnailSize int) (*Image, error) {
+	mimeType := http.DetectContentType(data)
+
+	var err error
+	orientation := 1
+	stripped := data
+
+	switch mimeType {
+	case TypeJPEG:
+		orientation = jpegOrientation(data)
+		stripped, err = stripJPEG(data)
+	case TypePNG:
+		stripped, err = stripPNG(data)
+	case TypeGIF:
+	default:
+		return nil, ErrUnsupportedType
+	}
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(stripped))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	img := &Image{Data: stripped, MIMEType: mimeType}
+
+	if orientation != 1 {
+		src = orient(src, orientation)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, src, &jpeg.Options{Quality: 90}); err != nil {
+			return nil, err
+		}
+		img.Data = buf.Bytes()
+	}
+
+	bounds := src.Bounds()
+	img.Width, img.Height = bounds.Dx(), bounds.Dy()
+
+	thumbnail := resize(src, thumbnailSize)
+
+	var buf bytes.Buffer
+	switch mimeType {
+	case TypeJPEG:
+		img.ThumbnailMIMEType = TypeJPEG
+		err = jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: 85})
+	default:
+		// PNG keeps the transparency of PNGs and GIFs
+		img.ThumbnailMIMEType = TypePNG
+		err = png.Encode(&buf, thumbnail)
+	}
+	if err != nil {
+		return nil, err
+	}
+	img.Thumbnail = buf.Bytes()
+
+	return img, nil
+}
+
+// resize scales src down to fit into a square of size pixels, keeping its aspect ratio.
+// Every pixel of the result is the average of the pixels it covers. Images that already
+// fit are only copied.
+func resize(src image.Image, size int) *image.RGBA {
+	bounds := src.Bounds()
+	sw, sh := bounds.Dx(), bounds.Dy()
+
+	rgba := image.NewRGBA(image.Rect(0, 0, sw, sh))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+
+	if sw <= size && sh <= size {
+		return rgba
+	}
+
+	dw, dh := size, size
+	if sw > sh {
+		dh = max(1, sh*size/sw)
+	} else {
+		dw = max(1, sw*size/sh)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		y0, y1 := dy*sh/dh, max((dy+1)*sh/dh, dy*sh/dh+1)
+
+		for dx := 0; dx < dw; dx++ {
+			x0, x1 := dx*sw/dw, max((dx+1)*sw/dw, dx*sw/dw+1)
+
+			var r, g, b, a, n int
+			for y := y0; y < y1; y++ {
+				row := rgba.Pix[y*rgba.Stride:]
+				for x := x0; x < x1; x++ {
+					p := row[x*4 : x*4+4]
+					r += int(p[0])
+					g += int(p[1])
+					b += int(p[2])
+					a += int(p[3])
+					n++
+				}
+			}
+
+			p := dst.Pix[dy*dst.Stride+dx*4:]
+			p[0], p[1], p[2], p[3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
+
+// orient turns src upright according to an EXIF orientation.
+func orient(src image.Image, orientation int) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	// orientations 5 to 8 swap width and height
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			default:
+				sx, sy = x, y
+			}
+			dst.Set(x, y, src.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,249 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+)
+
+func testImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// exifSegment is an APP1 segment with an orientation and a camera model.
+func exifSegment(orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	tiff = append(tiff, "Gopher Cam 3000"...)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xff, 0xe1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+func testJPEG(t *testing.T, w, h int, orientation uint16) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(w, h), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// the EXIF segment goes right after the start of image marker
+	out := append([]byte{}, data[:2]...)
+	out = append(out, exifSegment(orientation)...)
+	return append(out, data[2:]...)
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(w, h)); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	text := []byte("Author\x00Gopher")
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(text)))
+	chunk = append(chunk, "tEXt"...)
+	chunk = append(chunk, text...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	// signature and IHDR chunk
+	out := append([]byte{}, data[:33]...)
+	out = append(out, chunk...)
+	return append(out, data[33:]...)
+}
+
+func TestProcess(t *testing.T) {
+	tests := []struct {
+		name              string
+		data              []byte
+		expectedType      string
+		expectedThumbnail string
+		expectedWidth     int
+		expectedHeight    int
+		unwanted          string
+	}{
+		{
+			name:              "jpeg",
+			data:              testJPEG(t, 400, 200, 1),
+			expectedType:      TypeJPEG,
+			expectedThumbnail: TypeJPEG,
+			expectedWidth:     400,
+			expectedHeight:    200,
+			unwanted:          "Gopher Cam",
+		},
+		{
+			name:              "rotated jpeg",
+			data:              testJPEG(t, 400, 200, 6),
+			expectedType:      TypeJPEG,
+			expectedThumbnail: TypeJPEG,
+			expectedWidth:     200,
+			expectedHeight:    400,
+			unwanted:          "Gopher Cam",
+		},
+		{
+			name:              "png",
+			data:              testPNG(t, 50, 80),
+			expectedType:      TypePNG,
+			expectedThumbnail: TypePNG,
+			expectedWidth:     50,
+			expectedHeight:    80,
+			unwanted:          "Author",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Process(tt.data, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if img.MIMEType != tt.expectedType || img.ThumbnailMIMEType != tt.expectedThumbnail {
+				t.Errorf("unexpected types %q and %q", img.MIMEType, img.ThumbnailMIMEType)
+			}
+			if img.Width != tt.expectedWidth || img.Height != tt.expectedHeight {
+				t.Errorf("expected %dx%d, got %dx%d", tt.expectedWidth, tt.expectedHeight, img.Width, img.Height)
+			}
+			if bytes.Contains(img.Data, []byte(tt.unwanted)) {
+				t.Errorf("expected %q to be stripped", tt.unwanted)
+			}
+
+			if _, _, err := image.Decode(bytes.NewReader(img.Data)); err != nil {
+				t.Errorf("stripped image cannot be decoded: %v", err)
+			}
+
+			thumbnail, _, err := image.DecodeConfig(bytes.NewReader(img.Thumbnail))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if max(thumbnail.Width, thumbnail.Height) > 100 {
+				t.Errorf("thumbnail %dx%d is larger than 100 pixels", thumbnail.Width, thumbnail.Height)
+			}
+			if (thumbnail.Width > thumbnail.Height) != (img.Width > img.Height) {
+				t.Errorf("thumbnail %dx%d does not keep the aspect ratio", thumbnail.Width, thumbnail.Height)
+			}
+		})
+	}
+}
+
+func TestProcess_Rejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{name: "text", data: []byte("just text"), err: ErrUnsupportedType},
+		{name: "html", data: []byte("<html><script>alert(1)</script></html>"), err: ErrUnsupportedType},
+		{name: "truncated png", data: testPNG(t, 10, 10)[:40], err: ErrInvalidImage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Process(tt.data, 100); !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		w, h, size     int
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{w: 400, h: 200, size: 100, expectedWidth: 100, expectedHeight: 50},
+		{w: 200, h: 400, size: 100, expectedWidth: 50, expectedHeight: 100},
+		{w: 1000, h: 1, size: 100, expectedWidth: 100, expectedHeight: 1},
+		{w: 40, h: 20, size: 100, expectedWidth: 40, expectedHeight: 20},
+	}
+
+	for _, tt := range tests {
+		got := resize(testImage(tt.w, tt.h), tt.size).Bounds()
+		if got.Dx() != tt.expectedWidth || got.Dy() != tt.expectedHeight {
+			t.Errorf("resize(%dx%d, %d) = %dx%d, expected %dx%d", tt.w, tt.h, tt.size, got.Dx(), got.Dy(), tt.expectedWidth, tt.expectedHeight)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	marker := color.RGBA{R: 255, A: 255}
+	src.Set(0, 0, marker)
+
+	// where the top left pixel ends up
+	expected := map[int]image.Point{
+		1: {0, 0}, 2: {2, 0}, 3: {2, 1}, 4: {0, 1},
+		5: {0, 0}, 6: {1, 0}, 7: {1, 2}, 8: {0, 2},
+	}
+
+	for orientation, p := range expected {
+		got := orient(src, orientation)
+		if got.At(p.X, p.Y) != marker {
+			t.Errorf("orientation %d: expected the marker at %v", orientation, p)
+		}
+	}
+}
+
+func TestLocalBlobStore(t *testing.T) {
+	s, err := NewLocalBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	key, err := s.Put(ctx, bytes.NewReader([]byte("gopher")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != Key([]byte("gopher")) {
+		t.Errorf("expected the key to be the checksum, got %q", key)
+	}
+
+	again, err := s.Put(ctx, bytes.NewReader([]byte("gopher")))
+	if err != nil || again != key {
+		t.Errorf("expected the same key for the same content, got %q, %v", again, err)
+	}
+
+	blob, err := s.Open(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blob.Close()
+
+	data, err := io.ReadAll(blob)
+	if err != nil || string(data) != "gopher" {
+		t.Errorf("unexpected content %q, %v", data, err)
+	}
+
+	for _, key := range []string{Key([]byte("missing")), "../../etc/passwd", ""} {
+		if _, err := s.Open(ctx, key); !errors.Is(err, ErrBlobNotFound) {
+			t.Errorf("Open(%q): expected ErrBlobNotFound, got %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "Go", want: "go"},
+		{in: "  Hello, World!  ", want: "hello-world"},
+		{in: "Crème Brûlée", want: "creme-brulee"},
+		{in: "Straße & Œuvre", want: "strasse-oeuvre"},
+		{in: "C++ / Go 1.22", want: "c-go-1-22"},
+		{in: "日本語", want: ""},
+		{in: "---", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := Make(tt.in); got != tt.want {
+			t.Errorf("Make(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMake_MaxLength(t *testing.T) {
+	got := Make(strings.Repeat("word ", 30))
+
+	if len(got) > MaxLength {
+		t.Errorf("expected at most %d bytes, got %d", MaxLength, len(got))
+	}
+	if strings.HasSuffix(got, "-") || !strings.HasSuffix(got, "word") {
+		t.Errorf("expected the slug to be cut between words, got %q", got)
+	}
+}
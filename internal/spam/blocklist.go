@@ -0,0 +1,100 @@
+package spam
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Blocklist contains domains and words that mark a submission as spam.
+// Blocking a domain also blocks its subdomains.
+type Blocklist struct {
+	domains map[string]bool
+	words   []string
+}
+
+// LoadBlocklist reads a blocklist file. See ParseBlocklist for the format.
+func LoadBlocklist(path string) (*Blocklist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseBlocklist(f)
+}
+
+// ParseBlocklist reads one entry per line, either "domain example.com" or "word casino".
+// Empty lines and lines starting with # are ignored.
+func ParseBlocklist(r io.Reader) (*Blocklist, error) {
+	b := &Blocklist{domains: map[string]bool{}}
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		kind, value, _ := strings.Cut(line, " ")
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			return nil, fmt.Errorf("blocklist line %d: missing value", n)
+		}
+
+		switch kind {
+		case "domain":
+			b.domains[strings.TrimPrefix(value, "www.")] = true
+		case "word":
+			word := normalize(value)
+			if word == "" {
+				return nil, fmt.Errorf("blocklist line %d: %q contains no letters or digits", n, value)
+			}
+			b.words = append(b.words, word)
+		default:
+			return nil, fmt.Errorf("blocklist line %d: unknown entry %q", n, kind)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// matchDomain returns the blocked domain that host is or belongs to.
+func (b *Blocklist) matchDomain(host string) (string, bool) {
+	for host != "" {
+		if b.domains[host] {
+			return host, true
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			break
+		}
+		host = parent
+	}
+	return "", false
+}
+
+// matchWords returns the blocked words that occur as whole words or phrases in text.
+func (b *Blocklist) matchWords(text string) []string {
+	normalized := " " + normalize(text) + " "
+
+	var matches []string
+	for _, word := range b.words {
+		if strings.Contains(normalized, " "+word+" ") {
+			matches = append(matches, word)
+		}
+	}
+	return matches
+}
+
+// normalize lowercases s and reduces it to its words, separated by single spaces.
+func normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
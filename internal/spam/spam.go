@@ -0,0 +1,187 @@
+// Package spam scores comments and registrations with simple heuristics.
+// Every heuristic adds points and a reason to the score; the score decides
+// whether a submission is allowed, held for review or rejected.
+package spam
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	VerdictAllow  = "allow"
+	VerdictHold   = "hold"
+	VerdictReject = "reject"
+
+	// minFingerprintLength keeps short replies like "thanks!" from counting as repeated content.
+	minFingerprintLength = 20
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"]+`)
+
+// Config holds the thresholds of the filter.
+type Config struct {
+	// HoldScore and RejectScore are the scores from which submissions are held or rejected.
+	HoldScore   float64
+	RejectScore float64
+	// MaxLinks is the number of links a comment can contain without raising its score.
+	MaxLinks int
+	// MaxLinkDensity is the share of words that can be links without raising the score.
+	MaxLinkDensity float64
+	// VelocityWindow and MaxPerWindow limit how many comments a user can write in a row.
+	VelocityWindow time.Duration
+	MaxPerWindow   int
+	// DuplicateWindow is how far back repeated content is looked for.
+	DuplicateWindow time.Duration
+	// NewAccountAge is the age below which accounts count as new.
+	NewAccountAge time.Duration
+}
+
+// DefaultConfig returns thresholds that hold a comment with a few suspicious signals
+// and reject it if it hits a blocklist on top.
+func DefaultConfig() Config {
+	return Config{
+		HoldScore:       3,
+		RejectScore:     8,
+		MaxLinks:        2,
+		MaxLinkDensity:  0.2,
+		VelocityWindow:  10 * time.Minute,
+		MaxPerWindow:    5,
+		DuplicateWindow: 24 * time.Hour,
+		NewAccountAge:   24 * time.Hour,
+	}
+}
+
+// Submission is a comment or registration to score. The counts are looked up by the
+// caller, so the filter itself does not need a database.
+type Submission struct {
+	Content  string
+	Username string
+	Email    string
+	// AccountCreatedAt is the creation time of the author's account. Zero for registrations.
+	AccountCreatedAt time.Time
+	// RecentCount is the number of comments the author wrote within the velocity window.
+	RecentCount int
+	// DuplicateCount is the number of comments with the same fingerprint within the duplicate window.
+	DuplicateCount int
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons"`
+	Verdict string   `json:"verdict"`
+}
+
+// Filter scores submissions.
+type Filter struct {
+	config    Config
+	blocklist *Blocklist
+	now       func() time.Time
+}
+
+// New returns a filter with the given thresholds. blocklist may be nil.
+func New(config Config, blocklist *Blocklist) *Filter {
+	if blocklist == nil {
+		blocklist = &Blocklist{domains: map[string]bool{}}
+	}
+	return &Filter{config: config, blocklist: blocklist, now: time.Now}
+}
+
+// Config returns the thresholds of the filter.
+func (f *Filter) Config() Config {
+	return f.config
+}
+
+// Check scores s.
+func (f *Filter) Check(s Submission) Result {
+	result := Result{Reasons: []string{}}
+	add := func(points float64, reason string, args ...any) {
+		result.Score += points
+		result.Reasons = append(result.Reasons, fmt.Sprintf(reason, args...))
+	}
+
+	text := strings.TrimSpace(s.Content + " " + s.Username)
+	links := linkPattern.FindAllString(text, -1)
+
+	if extra := len(links) - f.config.MaxLinks; extra > 0 {
+		add(float64(extra), "%d links", len(links))
+	}
+	if words := len(strings.Fields(text)); words > 0 && len(links) > 0 {
+		if density := float64(len(links)) / float64(words); density > f.config.MaxLinkDensity {
+			add(2, "link density of %.0f%%", density*100)
+		}
+	}
+
+	domains := make([]string, 0, len(links)+1)
+	for _, link := range links {
+		if host := linkHost(link); host != "" {
+			domains = append(domains, host)
+		}
+	}
+	if _, domain, ok := strings.Cut(s.Email, "@"); ok {
+		domains = append(domains, strings.ToLower(domain))
+	}
+
+	seen := map[string]bool{}
+	for _, domain := range domains {
+		if blocked, ok := f.blocklist.matchDomain(domain); ok && !seen[blocked] {
+			seen[blocked] = true
+			add(5, "blocklisted domain %s", blocked)
+		}
+	}
+
+	for _, word := range f.blocklist.matchWords(text) {
+		add(3, "blocklisted word %q", word)
+	}
+
+	if s.DuplicateCount > 0 {
+		add(min(2*float64(s.DuplicateCount), 6), "same content posted %d times before", s.DuplicateCount)
+	}
+
+	if f.config.MaxPerWindow > 0 && s.RecentCount >= f.config.MaxPerWindow {
+		add(3, "%d comments within %s", s.RecentCount, f.config.VelocityWindow)
+	}
+
+	if !s.AccountCreatedAt.IsZero() && f.now().Sub(s.AccountCreatedAt) < f.config.NewAccountAge {
+		add(1, "account younger than %s", f.config.NewAccountAge)
+	}
+
+	switch {
+	case result.Score >= f.config.RejectScore:
+		result.Verdict = VerdictReject
+	case result.Score >= f.config.HoldScore:
+		result.Verdict = VerdictHold
+	default:
+		result.Verdict = VerdictAllow
+	}
+	return result
+}
+
+// Fingerprint identifies content regardless of case, punctuation and whitespace.
+// Content that is too short to be meaningful has no fingerprint.
+func Fingerprint(content string) string {
+	normalized := normalize(content)
+	if len(normalized) < minFingerprintLength {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func linkHost(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
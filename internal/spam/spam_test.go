@@ -0,0 +1,93 @@
+package spam
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilter_Check(t *testing.T) {
+	blocklist, err := ParseBlocklist(strings.NewReader(`
+# spam domains
+domain casino.example
+domain throwaway.example
+word cheap pills
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	filter := New(DefaultConfig(), blocklist)
+	filter.now = func() time.Time { return now }
+
+	tests := []struct {
+		name       string
+		submission Submission
+		verdict    string
+	}{
+		{
+			name:       "regular comment",
+			submission: Submission{Content: "Great post, the part about generics helped me a lot. See https://go.dev/doc for more.", AccountCreatedAt: now.AddDate(0, -1, 0)},
+			verdict:    VerdictAllow,
+		},
+		{
+			name:       "new account",
+			submission: Submission{Content: "Thanks for writing this up!", AccountCreatedAt: now.Add(-time.Hour)},
+			verdict:    VerdictAllow,
+		},
+		{
+			name:       "link dump",
+			submission: Submission{Content: "http://a.example http://b.example http://c.example", AccountCreatedAt: now.AddDate(-1, 0, 0)},
+			verdict:    VerdictHold,
+		},
+		{
+			name:       "repeated content from a fast poster",
+			submission: Submission{Content: "Nice article, check out my profile", RecentCount: 6, DuplicateCount: 3, AccountCreatedAt: now.AddDate(-1, 0, 0)},
+			verdict:    VerdictReject,
+		},
+		{
+			name:       "blocklisted subdomain and words",
+			submission: Submission{Content: "Buy CHEAP pills at https://www.win.casino.example/now", AccountCreatedAt: now.AddDate(-1, 0, 0)},
+			verdict:    VerdictReject,
+		},
+		{
+			name:       "registration with blocklisted email domain",
+			submission: Submission{Username: "gopher", Email: "gopher@Throwaway.example"},
+			verdict:    VerdictHold,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := filter.Check(tt.submission)
+			if result.Verdict != tt.verdict {
+				t.Errorf("expected %s, got %s with score %.1f (%s)", tt.verdict, result.Verdict, result.Score, strings.Join(result.Reasons, ", "))
+			}
+			if result.Verdict != VerdictAllow && len(result.Reasons) == 0 {
+				t.Error("expected reasons for a held or rejected submission")
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := Fingerprint("Check out my profile, great deals!")
+	b := Fingerprint("  check OUT my profile great deals ")
+
+	if a == "" || a != b {
+		t.Errorf("expected equal fingerprints for the same words, got %q and %q", a, b)
+	}
+
+	if Fingerprint("Thanks!") != "" {
+		t.Error("expected no fingerprint for short content")
+	}
+}
+
+func TestParseBlocklist_Invalid(t *testing.T) {
+	for _, input := range []string{"domain", "url http://spam.example", "word !!!"} {
+		if _, err := ParseBlocklist(strings.NewReader(input)); err == nil {
+			t.Errorf("expected an error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/markdown"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	DefaultBookmarkLimit = 20
+	MaxBookmarkLimit     = 100
+)
+
+// Bookmark is a post a user saved for later, with a private note.
+type Bookmark struct {
+	PostID    int64     `json:"post_id"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Post is only set on the reading list.
+	Post *Post `json:"post,omitempty"`
+}
+
+// BookmarkQuery describes a page of the reading list, most recently bookmarked first.
+type BookmarkQuery struct {
+	Limit  int
+	Offset int
+}
+
+type BookmarksPostgreStore struct {
+	db *sql.DB
+}
+
+// Save bookmarks the post for the user. If the post is already bookmarked, only the note is updated.
+// A nil note keeps the note of an existing bookmark.
+func (s *BookmarksPostgreStore) Save(ctx context.Context, userID uuid.UUID, postID int64, note *string) (*Bookmark, error) {
+	query := `
+		INSERT INTO bookmarks (user_id, post_id, note)
+		VALUES ($1, $2, COALESCE($3, ''))
+		ON CONFLICT (user_id, post_id) DO UPDATE
+		SET note = COALESCE($3, bookmarks.note), updated_at = NOW()
+		RETURNING note, created_at, updated_at
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	bookmark := &Bookmark{PostID: postID}
+	err := s.db.QueryRowContext(ctx, query, userID, postID, note).Scan(&bookmark.Note, &bookmark.CreatedAt, &bookmark.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return bookmark, nil
+}
+
+// Remove deletes the bookmark. Removing a bookmark that does not exist has no effect.
+func (s *BookmarksPostgreStore) Remove(ctx context.Context, userID uuid.UUID, postID int64) error {
+	query := `
+		DELETE FROM bookmarks WHERE user_id = $1 AND post_id = $2
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, userID, postID)
+	return err
+}
+
+// GetByUser returns a page of the reading list of the user with the bookmarked posts.
+// Posts that were unpublished since are left out, unless the user wrote them.
+func (s *BookmarksPostgreStore) GetByUser(ctx context.Context, userID uuid.UUID, bq BookmarkQuery) ([]Bookmark, error) {
+	query := `
+		SELECT b.note, b.created_at, b.updated_at,
+			p.id, p.title, p.slug, p.text, p.user_id, ` + postTagsColumn + `, p.status, p.published_at, p.moderation, p.created_at, p.updated_at, p.version, p.html, p.html_version
+		FROM bookmarks b
+		JOIN posts p ON p.id = b.post_id
+		WHERE b.user_id = $1 AND (p.status = '` + PostStatusPublished + `' OR p.user_id = $1)
+		ORDER BY b.created_at DESC, p.id DESC
+		LIMIT $2 OFFSET $3
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID, bq.Limit, bq.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookmarks := []Bookmark{}
+	posts := []*Post{}
+	for rows.Next() {
+		var (
+			bookmark    Bookmark
+			post        Post
+			htmlVersion int
+		)
+		err := rows.Scan(
+			&bookmark.Note,
+			&bookmark.CreatedAt,
+			&bookmark.UpdatedAt,
+			&post.ID,
+			&post.Title,
+			&post.Slug,
+			&post.Text,
+			&post.UserID,
+			pq.Array(&post.Tags),
+			&post.Status,
+			&post.PublishedAt,
+			&post.Moderation,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&post.Version,
+			&post.HTML,
+			&htmlVersion,
+		)
+		if err != nil {
+			return nil, err
+		}
+		post.HTML = renderedHTML(post.Text, post.HTML, htmlVersion, markdown.Post)
+
+		bookmark.PostID = post.ID
+		bookmark.Post = &post
+		bookmarks = append(bookmarks, bookmark)
+		posts = append(posts, &post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := loadReactions(ctx, s.db, posts, &userID); err != nil {
+		return nil, err
+	}
+	bookmarked := true
+	for _, post := range posts {
+		post.Bookmarked = &bookmarked
+	}
+
+	return bookmarks, nil
+}
+
+// Load sets whether the viewer bookmarked the posts. It is left unset if there is no viewer.
+func (s *BookmarksPostgreStore) Load(ctx context.Context, posts []*Post, viewerID *uuid.UUID) error {
+	return loadBookmarked(ctx, s.db, posts, viewerID)
+}
+
+// loadBookmarked looks up the bookmarks of the viewer for all posts in a single query.
+func loadBookmarked(ctx context.Context, db *sql.DB, posts []*Post, viewerID *uuid.UUID) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+		post.Bookmarked = nil
+	}
+	if viewerID == nil {
+		return nil
+	}
+
+	query := `
+		SELECT post_id FROM bookmarks WHERE user_id = $1 AND post_id = ANY($2)
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, *viewerID, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	bookmarked := map[int64]bool{}
+	for rows.Next() {
+		var postID int64
+		if err := rows.Scan(&postID); err != nil {
+			return err
+		}
+		bookmarked[postID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		b := bookmarked[post.ID]
+		post.Bookmarked = &b
+	}
+	return nil
+}
@@ -4,70 +4,283 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"sort"
 	"time"
 
+	"github.com/ITine-Tech/blog/internal/markdown"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	DefaultCommentDepth = 3
+	MaxCommentDepth     = 10
+	DefaultCommentLimit = 20
+	MaxCommentLimit     = 100
+
+	CommentStatusPending  = "pending"
+	CommentStatusApproved = "approved"
+	CommentStatusRejected = "rejected"
+
+	// ModerationOpen publishes every comment right away.
+	ModerationOpen = "open"
+	// ModerationFirstTime holds comments of users without an approved comment.
+	ModerationFirstTime = "first_time"
+	// ModerationAll holds every comment.
+	ModerationAll = "all"
+)
+
+var (
+	ErrInvalidParent        = errors.New("the parent comment does not belong to this post or has been deleted")
+	ErrInvalidModeration    = errors.New("moderation must be one of open, first_time or all")
+	ErrInvalidCommentStatus = errors.New("status must be one of pending, approved or rejected")
 )
 
 type Comment struct {
-	ID        int       `json:"id"`
-	PostID    int       `json:"post_id"`
-	UserID    uuid.UUID `json:"user_id"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	User      User      `json:"user"`
+	ID       int       `json:"id"`
+	PostID   int       `json:"post_id"`
+	ParentID *int      `json:"parent_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Content  string    `json:"content"`
+	HTML     string    `json:"html"`
+	// Status is pending until a moderator approved the comment. Only approved comments are public.
+	Status string `json:"status"`
+	// Fingerprint identifies the content to find repeated comments.
+	Fingerprint string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+	// EditedAt is set when the content has been changed after the comment was created.
+	EditedAt *time.Time `json:"edited_at"`
+	// Deleted comments that still have replies are kept as tombstones without content and author.
+	Deleted bool `json:"deleted"`
+	User    User `json:"user"`
+	// Depth is 1 for the top level of the returned tree.
+	Depth int `json:"depth"`
+	// ReplyCount is the number of direct replies, including those not returned because of the limit.
+	ReplyCount int        `json:"reply_count"`
+	Replies    []*Comment `json:"replies"`
+}
+
+// CommentTreeQuery describes which part of a comment thread to load.
+type CommentTreeQuery struct {
+	// ParentID is the comment whose replies form the top level. Nil starts at the top-level comments of the post.
+	ParentID *int
+	// MaxDepth is the number of levels to load.
+	MaxDepth int
+	// Limit is the maximum number of comments per level and parent.
+	Limit int
+	// Offset skips comments of the top level, to page through them.
+	Offset int
+}
+
+// ModerationQuery describes a page of the moderation queue.
+type ModerationQuery struct {
+	Status string
+	Limit  int
+	Offset int
+}
+
+// ValidModeration reports whether policy is a known moderation policy.
+func ValidModeration(policy string) bool {
+	switch policy {
+	case ModerationOpen, ModerationFirstTime, ModerationAll:
+		return true
+	}
+	return false
+}
+
+// ValidCommentStatus reports whether status is a known comment status.
+func ValidCommentStatus(status string) bool {
+	switch status {
+	case CommentStatusPending, CommentStatusApproved, CommentStatusRejected:
+		return true
+	}
+	return false
+}
+
+// ModeratedStatus returns the status of a new comment under policy.
+// hasApproved tells whether the author already has an approved comment.
+func ModeratedStatus(policy string, hasApproved bool) string {
+	switch policy {
+	case ModerationAll:
+		return CommentStatusPending
+	case ModerationFirstTime:
+		if !hasApproved {
+			return CommentStatusPending
+		}
+	}
+	return CommentStatusApproved
 }
 
 type CommentsPostgreStore struct {
 	db *sql.DB
 }
 
-func (s *CommentsPostgreStore) GetByPostID(ctx context.Context, postId int64) ([]Comment, error) {
+// GetByPostID loads the approved comments of a post as a tree in a single query.
+// Top-level comments are ordered newest first, replies oldest first.
+func (s *CommentsPostgreStore) GetByPostID(ctx context.Context, postId int64, cq CommentTreeQuery) ([]Comment, error) {
 	query := `
-		SELECT c.id, c.post_id, c.user_id, c.content, c.created_at, users.username, users.id FROM comments c
-		JOIN users on users.id = c.user_id 
-		WHERE c.post_id = $1
-		ORDER BY c.created_at DESC;
+		WITH RECURSIVE thread AS (
+			SELECT top.id, top.post_id, top.parent_id, top.user_id, top.content, top.html, top.html_version, top.status, top.created_at, top.edited_at, top.deleted_at, 1 AS depth
+			FROM (
+				SELECT c.id, c.post_id, c.parent_id, c.user_id, c.content, c.html, c.html_version, c.status, c.created_at, c.edited_at, c.deleted_at
+				FROM comments c
+				WHERE c.post_id = $1 AND c.parent_id IS NOT DISTINCT FROM $2::bigint AND c.status = 'approved'
+				ORDER BY c.created_at DESC, c.id DESC
+				LIMIT $4 OFFSET $5
+			) top
+			UNION ALL
+			SELECT reply.id, reply.post_id, reply.parent_id, reply.user_id, reply.content, reply.html, reply.html_version, reply.status, reply.created_at, reply.edited_at, reply.deleted_at, t.depth + 1
+			FROM thread t
+			CROSS JOIN LATERAL (
+				SELECT c.id, c.post_id, c.parent_id, c.user_id, c.content, c.html, c.html_version, c.status, c.created_at, c.edited_at, c.deleted_at
+				FROM comments c
+				WHERE c.parent_id = t.id AND c.status = 'approved'
+				ORDER BY c.created_at, c.id
+				LIMIT $4
+			) reply
+			WHERE t.depth < $3
+		)
+		SELECT t.id, t.post_id, t.parent_id, t.user_id, t.content, t.html, t.html_version, t.status, t.created_at, t.edited_at, t.deleted_at IS NOT NULL, t.depth, users.username, users.id,
+			(SELECT COUNT(*) FROM comments r WHERE r.parent_id = t.id AND r.status = 'approved')
+		FROM thread t
+		JOIN users on users.id = t.user_id
+		ORDER BY t.depth;
 	`
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	rows, err := s.db.QueryContext(ctx, query, postId)
+	rows, err := s.db.QueryContext(ctx, query, postId, cq.ParentID, cq.MaxDepth, cq.Limit, cq.Offset)
 	if err != nil {
 		return nil, err
 	}
 
 	defer rows.Close()
 
-	comments := []Comment{}
+	comments := []*Comment{}
 
 	for rows.Next() {
-		var comment Comment
+		comment := &Comment{}
 		comment.User = User{}
+		var htmlVersion int
 
-		err := rows.Scan(&comment.ID, &comment.PostID, &comment.UserID, &comment.Content, &comment.CreatedAt, &comment.User.Username, &comment.User.ID)
+		err := rows.Scan(
+			&comment.ID,
+			&comment.PostID,
+			&comment.ParentID,
+			&comment.UserID,
+			&comment.Content,
+			&comment.HTML,
+			&htmlVersion,
+			&comment.Status,
+			&comment.CreatedAt,
+			&comment.EditedAt,
+			&comment.Deleted,
+			&comment.Depth,
+			&comment.User.Username,
+			&comment.User.ID,
+			&comment.ReplyCount,
+		)
 		if err != nil {
 			return nil, err
 		}
+
+		comment.HTML = renderedHTML(comment.Content, comment.HTML, htmlVersion, markdown.Comment)
+		if comment.Deleted {
+			comment.tombstone()
+		}
 		comments = append(comments, comment)
 
 	}
-	return comments, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return buildCommentTree(comments), nil
+}
+
+// buildCommentTree nests the flat list of comments, ordered by depth, under their parents.
+func buildCommentTree(comments []*Comment) []Comment {
+	byID := make(map[int]*Comment, len(comments))
+	var roots []*Comment
+
+	for _, comment := range comments {
+		comment.Replies = []*Comment{}
+		byID[comment.ID] = comment
+
+		if comment.ParentID != nil && comment.Depth > 1 {
+			if parent, ok := byID[*comment.ParentID]; ok {
+				parent.Replies = append(parent.Replies, comment)
+				continue
+			}
+		}
+
+		roots = append(roots, comment)
+	}
+
+	sort.SliceStable(roots, func(i, j int) bool {
+		return newerComment(roots[i], roots[j])
+	})
+
+	for _, comment := range comments {
+		replies := comment.Replies
+		sort.SliceStable(replies, func(i, j int) bool {
+			return newerComment(replies[j], replies[i])
+		})
+	}
+
+	tree := make([]Comment, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, *root)
+	}
+	return tree
+}
+
+func newerComment(a, b *Comment) bool {
+	if a.CreatedAt.Equal(b.CreatedAt) {
+		return a.ID > b.ID
+	}
+	return a.CreatedAt.After(b.CreatedAt)
 }
 
+// CreateComment adds a comment to a published post. Replies have to reference an approved parent on the same post.
+// Comments without a status are approved.
 func (s *CommentsPostgreStore) CreateComment(ctx context.Context, comment *Comment) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if comment.Status == "" {
+		comment.Status = CommentStatusApproved
+	}
+
+	if comment.ParentID != nil {
+		var parentPostID int
+		err := s.db.QueryRowContext(ctx, `SELECT post_id FROM comments WHERE id = $1 AND deleted_at IS NULL AND status = 'approved'`, *comment.ParentID).Scan(&parentPostID)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrInvalidParent
+			default:
+				return err
+			}
+		}
+
+		if parentPostID != comment.PostID {
+			return ErrInvalidParent
+		}
+	}
+
 	query := `
         WITH post_exists AS (
-            SELECT EXISTS(SELECT 1 FROM posts WHERE id = $1) AS exists
+            SELECT EXISTS(SELECT 1 FROM posts WHERE id = $1 AND status = 'published') AS exists
         )
-        INSERT INTO comments(post_id, user_id, content)
-        SELECT $1, $2, $3
+        INSERT INTO comments(post_id, user_id, content, html, html_version, parent_id, status, fingerprint)
+        SELECT $1, $2, $3, $7, $8, $4::bigint, $5, NULLIF($6, '')
         FROM post_exists
         WHERE exists = TRUE
         RETURNING id, created_at
     `
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
+
+	comment.HTML = markdown.Render(comment.Content, markdown.Comment)
 
 	err := s.db.QueryRowContext(
 		ctx,
@@ -75,6 +288,11 @@ func (s *CommentsPostgreStore) CreateComment(ctx context.Context, comment *Comme
 		comment.PostID,
 		comment.UserID,
 		comment.Content,
+		comment.ParentID,
+		comment.Status,
+		comment.Fingerprint,
+		comment.HTML,
+		markdown.Version,
 	).Scan(
 		&comment.ID,
 		&comment.CreatedAt,
@@ -89,3 +307,200 @@ func (s *CommentsPostgreStore) CreateComment(ctx context.Context, comment *Comme
 	}
 	return nil
 }
+
+func (s *CommentsPostgreStore) GetByID(ctx context.Context, id int) (*Comment, error) {
+	query := `
+		SELECT c.id, c.post_id, c.parent_id, c.user_id, c.content, c.html, c.html_version, c.status, c.created_at, c.edited_at, c.deleted_at IS NOT NULL
+		FROM comments c
+		WHERE c.id = $1
+	`
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	comment := &Comment{}
+	var htmlVersion int
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.ParentID,
+		&comment.UserID,
+		&comment.Content,
+		&comment.HTML,
+		&htmlVersion,
+		&comment.Status,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.Deleted,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	comment.HTML = renderedHTML(comment.Content, comment.HTML, htmlVersion, markdown.Comment)
+	return comment, nil
+}
+
+// UpdateComment saves the new content of a comment with its status and fingerprint and marks it as edited.
+// Deleted comments cannot be edited and return ErrNotFound.
+func (s *CommentsPostgreStore) UpdateComment(ctx context.Context, comment *Comment) error {
+	if !ValidCommentStatus(comment.Status) {
+		return ErrInvalidCommentStatus
+	}
+
+	query := `
+		UPDATE comments
+		SET content = $1, html = $3, html_version = $4, status = $5, fingerprint = NULLIF($6, ''), edited_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+		RETURNING edited_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	html := markdown.Render(comment.Content, markdown.Comment)
+
+	err := s.db.QueryRowContext(ctx, query, comment.Content, comment.ID, html, markdown.Version, comment.Status, comment.Fingerprint).Scan(&comment.EditedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrNotFound
+		default:
+			return err
+		}
+	}
+
+	comment.HTML = html
+	return nil
+}
+
+// DeleteComment removes a comment. Comments with replies are turned into tombstones instead,
+// so the thread below them stays intact.
+func (s *CommentsPostgreStore) DeleteComment(ctx context.Context, id int) error {
+	query := `
+		WITH removed AS (
+			DELETE FROM comments
+			WHERE id = $1 AND NOT EXISTS(SELECT 1 FROM comments r WHERE r.parent_id = $1)
+			RETURNING id
+		), tombstoned AS (
+			UPDATE comments
+			SET content = '', html = '', deleted_at = NOW()
+			WHERE id = $1 AND deleted_at IS NULL AND NOT EXISTS(SELECT 1 FROM removed)
+			RETURNING id
+		)
+		SELECT (SELECT COUNT(*) FROM removed) + (SELECT COUNT(*) FROM tombstoned)
+	`
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var affected int
+	if err := s.db.QueryRowContext(ctx, query, id).Scan(&affected); err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// HasApprovedComment reports whether the user has written at least one approved comment.
+func (s *CommentsPostgreStore) HasApprovedComment(ctx context.Context, userID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS(SELECT 1 FROM comments WHERE user_id = $1 AND status = 'approved')
+	`
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var approved bool
+	if err := s.db.QueryRowContext(ctx, query, userID).Scan(&approved); err != nil {
+		return false, err
+	}
+	return approved, nil
+}
+
+// GetByStatus returns a page of the moderation queue, oldest comments first.
+func (s *CommentsPostgreStore) GetByStatus(ctx context.Context, mq ModerationQuery) ([]Comment, error) {
+	query := `
+		SELECT c.id, c.post_id, c.parent_id, c.user_id, c.content, c.html, c.html_version, c.status, c.created_at, c.edited_at, users.username, users.id
+		FROM comments c
+		JOIN users ON users.id = c.user_id
+		WHERE c.status = $1 AND c.deleted_at IS NULL
+		ORDER BY c.created_at, c.id
+		LIMIT $2 OFFSET $3
+	`
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, mq.Status, mq.Limit, mq.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []Comment{}
+	for rows.Next() {
+		var (
+			comment     Comment
+			htmlVersion int
+		)
+		err := rows.Scan(
+			&comment.ID,
+			&comment.PostID,
+			&comment.ParentID,
+			&comment.UserID,
+			&comment.Content,
+			&comment.HTML,
+			&htmlVersion,
+			&comment.Status,
+			&comment.CreatedAt,
+			&comment.EditedAt,
+			&comment.User.Username,
+			&comment.User.ID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		comment.HTML = renderedHTML(comment.Content, comment.HTML, htmlVersion, markdown.Comment)
+		comment.Replies = []*Comment{}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// SetStatus approves or rejects the comments with the given IDs and returns how many were changed.
+// Approved comments are left alone, so their replies cannot disappear from a thread.
+func (s *CommentsPostgreStore) SetStatus(ctx context.Context, ids []int, status string) (int64, error) {
+	if status != CommentStatusApproved && status != CommentStatusRejected {
+		return 0, ErrInvalidCommentStatus
+	}
+
+	query := `
+		UPDATE comments
+		SET status = $1
+		WHERE id = ANY($2) AND status <> 'approved' AND status <> $1 AND deleted_at IS NULL
+	`
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, status, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// tombstone hides the content and author of a deleted comment.
+func (c *Comment) tombstone() {
+	c.Content = ""
+	c.HTML = ""
+	c.UserID = uuid.Nil
+	c.User = User{}
+	c.EditedAt = nil
+}
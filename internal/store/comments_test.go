@@ -0,0 +1,76 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildCommentTree(t *testing.T) {
+	now := time.Now()
+	parent := func(id int) *int { return &id }
+
+	// ordered by depth, like the rows of GetByPostID
+	comments := []*Comment{
+		{ID: 1, Depth: 1, CreatedAt: now.Add(-3 * time.Hour)},
+		{ID: 2, Depth: 1, CreatedAt: now.Add(-1 * time.Hour)},
+		{ID: 5, ParentID: parent(1), Depth: 2, CreatedAt: now.Add(-90 * time.Minute)},
+		{ID: 3, ParentID: parent(1), Depth: 2, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: 4, ParentID: parent(3), Depth: 3, CreatedAt: now.Add(-30 * time.Minute)},
+	}
+
+	tree := buildCommentTree(comments)
+
+	if len(tree) != 2 {
+		t.Fatalf("expected 2 top-level comments, got %d", len(tree))
+	}
+	if tree[0].ID != 2 || tree[1].ID != 1 {
+		t.Errorf("top-level comments should be newest first, got %d, %d", tree[0].ID, tree[1].ID)
+	}
+
+	replies := tree[1].Replies
+	if len(replies) != 2 || replies[0].ID != 3 || replies[1].ID != 5 {
+		t.Fatalf("replies should be oldest first, got %+v", replies)
+	}
+	if len(replies[0].Replies) != 1 || replies[0].Replies[0].ID != 4 {
+		t.Errorf("expected comment 4 to be nested under comment 3, got %+v", replies[0].Replies)
+	}
+	if tree[0].Replies == nil {
+		t.Error("comments without replies should have an empty list of replies")
+	}
+}
+
+func TestBuildCommentTree_SubThread(t *testing.T) {
+	parent := func(id int) *int { return &id }
+
+	// loading the replies of comment 7: its replies are the top level
+	comments := []*Comment{
+		{ID: 8, ParentID: parent(7), Depth: 1},
+		{ID: 9, ParentID: parent(8), Depth: 2},
+	}
+
+	tree := buildCommentTree(comments)
+
+	if len(tree) != 1 || tree[0].ID != 8 || len(tree[0].Replies) != 1 {
+		t.Errorf("unexpected tree %+v", tree)
+	}
+}
+
+func TestModeratedStatus(t *testing.T) {
+	tests := []struct {
+		policy      string
+		hasApproved bool
+		expected    string
+	}{
+		{ModerationOpen, false, CommentStatusApproved},
+		{ModerationFirstTime, false, CommentStatusPending},
+		{ModerationFirstTime, true, CommentStatusApproved},
+		{ModerationAll, true, CommentStatusPending},
+		{"", false, CommentStatusApproved},
+	}
+
+	for _, tt := range tests {
+		if got := ModeratedStatus(tt.policy, tt.hasApproved); got != tt.expected {
+			t.Errorf("ModeratedStatus(%q, %v) = %q, expected %q", tt.policy, tt.hasApproved, got, tt.expected)
+		}
+	}
+}
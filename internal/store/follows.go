@@ -0,0 +1,201 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultFollowLimit = 20
+	MaxFollowLimit     = 100
+)
+
+var ErrFollowSelf = errors.New("users cannot follow themselves")
+
+// Follow is a user on a list of followers or followed users.
+type Follow struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Username   string    `json:"username"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// FollowCounts counts the followers of a user and the users they follow.
+type FollowCounts struct {
+	Followers int `json:"followers"`
+	Following int `json:"following"`
+}
+
+// FollowQuery describes a page of followers or followed users, most recent first.
+type FollowQuery struct {
+	Limit  int
+	Offset int
+}
+
+type FollowsPostgreStore struct {
+	db *sql.DB
+}
+
+// FollowUser makes followerID follow followeeID. Following a user twice has no effect.
+func (s *FollowsPostgreStore) FollowUser(ctx context.Context, followerID, followeeID uuid.UUID) error {
+	if followerID == followeeID {
+		return ErrFollowSelf
+	}
+
+	query := `
+		INSERT INTO follows (follower_id, followee_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, followerID, followeeID)
+	return err
+}
+
+// UnfollowUser stops followerID from following followeeID. Unfollowing a user that is not followed has no effect.
+func (s *FollowsPostgreStore) UnfollowUser(ctx context.Context, followerID, followeeID uuid.UUID) error {
+	query := `
+		DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, followerID, followeeID)
+	return err
+}
+
+// FollowTag makes the user follow the tag. Following a tag twice has no effect.
+func (s *FollowsPostgreStore) FollowTag(ctx context.Context, userID uuid.UUID, tagID int64) error {
+	query := `
+		INSERT INTO tag_follows (user_id, tag_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, userID, tagID)
+	return err
+}
+
+// UnfollowTag stops the user from following the tag. Unfollowing a tag that is not followed has no effect.
+func (s *FollowsPostgreStore) UnfollowTag(ctx context.Context, userID uuid.UUID, tagID int64) error {
+	query := `
+		DELETE FROM tag_follows WHERE user_id = $1 AND tag_id = $2
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, userID, tagID)
+	return err
+}
+
+// GetFollowers returns the users following the user, most recent first.
+func (s *FollowsPostgreStore) GetFollowers(ctx context.Context, userID uuid.UUID, fq FollowQuery) ([]Follow, error) {
+	query := `
+		SELECT u.id, u.username, f.created_at
+		FROM follows f
+		JOIN users u ON u.id = f.follower_id
+		WHERE f.followee_id = $1
+		ORDER BY f.created_at DESC, u.id
+		LIMIT $2 OFFSET $3
+		`
+	return s.getFollows(ctx, query, userID, fq)
+}
+
+// GetFollowing returns the users the user follows, most recent first.
+func (s *FollowsPostgreStore) GetFollowing(ctx context.Context, userID uuid.UUID, fq FollowQuery) ([]Follow, error) {
+	query := `
+		SELECT u.id, u.username, f.created_at
+		FROM follows f
+		JOIN users u ON u.id = f.followee_id
+		WHERE f.follower_id = $1
+		ORDER BY f.created_at DESC, u.id
+		LIMIT $2 OFFSET $3
+		`
+	return s.getFollows(ctx, query, userID, fq)
+}
+
+func (s *FollowsPostgreStore) getFollows(ctx context.Context, query string, userID uuid.UUID, fq FollowQuery) ([]Follow, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID, fq.Limit, fq.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	follows := []Follow{}
+	for rows.Next() {
+		var f Follow
+		if err := rows.Scan(&f.UserID, &f.Username, &f.FollowedAt); err != nil {
+			return nil, err
+		}
+		follows = append(follows, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return follows, nil
+}
+
+// GetFollowedTags returns the tags the user follows, ordered by name.
+func (s *FollowsPostgreStore) GetFollowedTags(ctx context.Context, userID uuid.UUID) ([]Tag, error) {
+	query := `
+		SELECT t.id, t.name, t.slug, t.created_at
+		FROM tag_follows tf
+		JOIN tags t ON t.id = tf.tag_id
+		WHERE tf.user_id = $1
+		ORDER BY t.name
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.CreatedAt); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Counts returns the number of followers of the user and of the users they follow.
+func (s *FollowsPostgreStore) Counts(ctx context.Context, userID uuid.UUID) (*FollowCounts, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM follows WHERE followee_id = $1),
+			(SELECT COUNT(*) FROM follows WHERE follower_id = $1)
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var counts FollowCounts
+	if err := s.db.QueryRowContext(ctx, query, userID).Scan(&counts.Followers, &counts.Following); err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
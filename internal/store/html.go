@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/markdown"
+)
+
+// renderedHTML returns the cached HTML of source if the current version of the
+// renderer produced it, and renders source again otherwise.
+func renderedHTML(source, cached string, version int, opts markdown.Options) string {
+	if version == markdown.Version {
+		return cached
+	}
+	return markdown.Render(source, opts)
+}
+
+// RenderStaleHTML renders the text of up to limit posts whose HTML is missing or was
+// rendered by an older version of the renderer. It returns the number of posts it looked at,
+// so callers can stop once it is below limit.
+func (s *PostsPostgreStore) RenderStaleHTML(ctx context.Context, limit int) (int, error) {
+	return renderStaleHTML(ctx, s.db, "posts", "text", limit, markdown.Post)
+}
+
+// RenderStaleHTML renders the content of up to limit comments whose HTML is missing or was
+// rendered by an older version of the renderer. It returns the number of comments it looked at,
+// so callers can stop once it is below limit.
+func (s *CommentsPostgreStore) RenderStaleHTML(ctx context.Context, limit int) (int, error) {
+	return renderStaleHTML(ctx, s.db, "comments", "content", limit, markdown.Comment)
+}
+
+// renderStaleHTML caches the HTML of the column source of table. Rows whose source
+// changed in the meantime are skipped; they have been rendered by that change.
+func renderStaleHTML(ctx context.Context, db *sql.DB, table, source string, limit int, opts markdown.Options) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	query := `SELECT id, ` + source + ` FROM ` + table + ` WHERE html_version <> $1 ORDER BY id LIMIT $2`
+
+	rows, err := db.QueryContext(ctx, query, markdown.Version, limit)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	type row struct {
+		id     int64
+		source string
+	}
+
+	var stale []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.source); err != nil {
+			return 0, err
+		}
+		stale = append(stale, r)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	query = `UPDATE ` + table + ` SET html = $1, html_version = $2 WHERE id = $3 AND ` + source + ` = $4`
+	for _, r := range stale {
+		if _, err := db.ExecContext(ctx, query, markdown.Render(r.source, opts), markdown.Version, r.id, r.source); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(stale), nil
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrQuotaExceeded = errors.New("the upload exceeds your storage quota")
+
+// MediaFile is an uploaded image. Its files are kept in the blob store under their checksums.
+type MediaFile struct {
+	ID                int64     `json:"id"`
+	UserID            uuid.UUID `json:"user_id"`
+	Filename          string    `json:"filename"`
+	MIMEType          string    `json:"mime_type"`
+	Size              int64     `json:"size"`
+	Checksum          string    `json:"checksum"`
+	Width             int       `json:"width"`
+	Height            int       `json:"height"`
+	ThumbnailMIMEType string    `json:"thumbnail_mime_type"`
+	ThumbnailSize     int64     `json:"thumbnail_size"`
+	ThumbnailChecksum string    `json:"-"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type MediaPostgreStore struct {
+	db *sql.DB
+}
+
+// Create records an upload unless it would take the user's files, thumbnails included,
+// over quota bytes. A quota of 0 means there is none. Uploads of the same user are
+// serialized by locking the user, so parallel uploads cannot exceed the quota together.
+func (s *MediaPostgreStore) Create(ctx context.Context, file *MediaFile, quota int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, file.UserID); err != nil {
+			return err
+		}
+
+		if quota > 0 {
+			var used int64
+			query := `SELECT COALESCE(SUM(size + thumbnail_size), 0) FROM media WHERE user_id = $1`
+			if err := tx.QueryRowContext(ctx, query, file.UserID).Scan(&used); err != nil {
+				return err
+			}
+			if used+file.Size+file.ThumbnailSize > quota {
+				return ErrQuotaExceeded
+			}
+		}
+
+		query := `
+			INSERT INTO media (user_id, filename, mime_type, size, checksum, width, height, thumbnail_mime_type, thumbnail_size, thumbnail_checksum)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id, created_at
+			`
+
+		return tx.QueryRowContext(
+			ctx,
+			query,
+			file.UserID,
+			file.Filename,
+			file.MIMEType,
+			file.Size,
+			file.Checksum,
+			file.Width,
+			file.Height,
+			file.ThumbnailMIMEType,
+			file.ThumbnailSize,
+			file.ThumbnailChecksum,
+		).Scan(
+			&file.ID,
+			&file.CreatedAt,
+		)
+	})
+}
+
+func (s *MediaPostgreStore) GetByID(ctx context.Context, id int64) (*MediaFile, error) {
+	query := `
+		SELECT id, user_id, filename, mime_type, size, checksum, width, height, thumbnail_mime_type, thumbnail_size, thumbnail_checksum, created_at
+		FROM media
+		WHERE id = $1
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var file MediaFile
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&file.ID,
+		&file.UserID,
+		&file.Filename,
+		&file.MIMEType,
+		&file.Size,
+		&file.Checksum,
+		&file.Width,
+		&file.Height,
+		&file.ThumbnailMIMEType,
+		&file.ThumbnailSize,
+		&file.ThumbnailChecksum,
+		&file.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &file, nil
+}
+
+// Delete removes the record of an upload. The files stay in the blob store,
+// other uploads may share them.
+func (s *MediaPostgreStore) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM media WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
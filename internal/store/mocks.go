@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,41 +11,850 @@ import (
 
 func NewMockStore() Storage {
 	return Storage{
-		Users: &MockUserStore{},
+		Posts:       &MockPostStore{},
+		Users:       &MockUserStore{},
+		Comments:    &MockCommentStore{},
+		Roles:       &MockRoleStore{},
+		Tokens:      &MockTokenStore{},
+		Search:      &MockSearchStore{},
+		Spam:        &MockSpamStore{},
+		Tags:        &MockTagStore{},
+		Media:       &MockMediaStore{},
+		Reactions:   &MockReactionStore{},
+		Follows:     &MockFollowStore{},
+		Bookmarks:   &MockBookmarkStore{},
+		Suspensions: &MockSuspensionStore{},
+		TwoFactor:   &MockTwoFactorStore{},
 	}
 }
 
+// MockPostStore keeps posts in memory. FormerSlugs maps old slugs to post IDs.
+type MockPostStore struct {
+	Posts       []*Post
+	FormerSlugs map[string]int64
+}
+
+func (m *MockPostStore) CreatePost(context.Context, *Post) error {
+	return nil
+}
+
+func (m *MockPostStore) GetAllPosts(context.Context, PaginatedFeedQuery) ([]*Post, string, error) {
+	return m.Posts, "", nil
+}
+
+func (m *MockPostStore) GetPostByID(_ context.Context, id int64) (*Post, error) {
+	for _, post := range m.Posts {
+		if post.ID == id {
+			return post, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *MockPostStore) GetPostBySlug(ctx context.Context, slug string) (*Post, error) {
+	for _, post := range m.Posts {
+		if post.Slug == slug {
+			return post, nil
+		}
+	}
+	if id, ok := m.FormerSlugs[slug]; ok {
+		return m.GetPostByID(ctx, id)
+	}
+	return nil, ErrNotFound
+}
+
+func (m *MockPostStore) UpdatePost(context.Context, *Post) error {
+	return nil
+}
+
+func (m *MockPostStore) DeletePost(context.Context, int64) error {
+	return nil
+}
+
+func (m *MockPostStore) PublishScheduledPosts(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockPostStore) GetRevisions(context.Context, int64) ([]PostRevision, error) {
+	return []PostRevision{}, nil
+}
+
+func (m *MockPostStore) GetRevision(context.Context, int64, int) (*PostRevision, error) {
+	return nil, ErrNotFound
+}
+
+func (m *MockPostStore) RenderStaleHTML(context.Context, int) (int, error) {
+	return 0, nil
+}
+
+// MockUserStore returns the Users by ID, and an empty user for every other ID.
 type MockUserStore struct {
+	Users []*User
 }
 
 func (m *MockUserStore) Create(ctx context.Context, tx *sql.Tx, u *User) error {
 	return nil
 }
 
-func (m *MockUserStore) GetAllUsers(context.Context) ([]*User, error) {
-	return []*User{}, nil
+func (m *MockUserStore) GetAllUsers(_ context.Context, uq UserQuery) ([]*User, error) {
+	users := []*User{}
+	for _, user := range m.Users {
+		switch {
+		case uq.Role != "" && user.Role.Name != uq.Role,
+			uq.Active != nil && user.IsActive != *uq.Active,
+			uq.CreatedSince != nil && user.CreatedAt.Before(*uq.CreatedSince),
+			uq.CreatedUntil != nil && !user.CreatedAt.Before(*uq.CreatedUntil):
+			continue
+		}
+		u := *user
+		users = append(users, &u)
+	}
+
+	start := min(uq.Offset, len(users))
+	end := min(start+uq.Limit, len(users))
+	return users[start:end], nil
 }
 
 func (m *MockUserStore) CreateAndInvite(context.Context, *User, string, time.Duration) error {
 	return nil
 }
 
+func (m *MockUserStore) Reinvite(context.Context, uuid.UUID, string, time.Duration) error {
+	return nil
+}
+
 func (m *MockUserStore) Activate(context.Context, string) error {
 	return nil
 }
 
-func (m *MockUserStore) GetUserByID(context.Context, uuid.UUID) (*User, error) {
+func (m *MockUserStore) CreatePasswordReset(context.Context, string, string, time.Duration) (*User, error) {
 	return &User{}, nil
+}
 
+func (m *MockUserStore) ResetPassword(context.Context, string, string) error {
+	return nil
 }
 
-func (m *MockUserStore) GetUserByUsername(context.Context, string) (*User, error) {
-	return nil, nil
+func (m *MockUserStore) GetUserByID(_ context.Context, id uuid.UUID) (*User, error) {
+	for _, user := range m.Users {
+		if user.ID == id {
+			u := *user
+			return &u, nil
+		}
+	}
+	return &User{}, nil
+
+}
+
+func (m *MockUserStore) GetUserByUsername(_ context.Context, username string) (*User, error) {
+	for _, user := range m.Users {
+		if user.Username == username {
+			u := *user
+			return &u, nil
+		}
+	}
+	if username == "missing" {
+		return nil, ErrNotFound
+	}
+	return &User{Username: username}, nil
 }
 
 func (m *MockUserStore) UpdateUser(context.Context, *User) error {
 	return nil
 }
+
+func (m *MockUserStore) SetRole(_ context.Context, id uuid.UUID, roleID int) error {
+	for _, user := range m.Users {
+		if user.ID == id {
+			user.Role = Role{ID: roleID}
+			user.RoleID = int64(roleID)
+			user.Version++
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *MockUserStore) SetActive(_ context.Context, id uuid.UUID, active bool) error {
+	for _, user := range m.Users {
+		if user.ID == id {
+			user.IsActive = active
+			user.Version++
+			return nil
+		}
+	}
+	return ErrNotFound
+}
 func (m *MockUserStore) DeleteUser(context.Context, uuid.UUID) error {
 	return nil
 }
+
+type MockCommentStore struct {
+	Comments []*Comment
+}
+
+func (m *MockCommentStore) GetByPostID(context.Context, int64, CommentTreeQuery) ([]Comment, error) {
+	return []Comment{}, nil
+}
+
+func (m *MockCommentStore) CreateComment(context.Context, *Comment) error {
+	return nil
+}
+
+func (m *MockCommentStore) GetByID(_ context.Context, id int) (*Comment, error) {
+	for _, comment := range m.Comments {
+		if comment.ID == id {
+			c := *comment
+			return &c, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *MockCommentStore) UpdateComment(_ context.Context, comment *Comment) error {
+	for i, c := range m.Comments {
+		if c.ID == comment.ID {
+			updated := *comment
+			m.Comments[i] = &updated
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *MockCommentStore) DeleteComment(context.Context, int) error {
+	return nil
+}
+
+func (m *MockCommentStore) HasApprovedComment(context.Context, uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+func (m *MockCommentStore) GetByStatus(context.Context, ModerationQuery) ([]Comment, error) {
+	return []Comment{}, nil
+}
+
+func (m *MockCommentStore) SetStatus(_ context.Context, ids []int, _ string) (int64, error) {
+	return int64(len(ids)), nil
+}
+
+func (m *MockCommentStore) RenderStaleHTML(context.Context, int) (int, error) {
+	return 0, nil
+}
+
+// MockRoleStore keeps roles in memory. Without Roles, it has the user, moderator and admin roles without permissions.
+type MockRoleStore struct {
+	Roles []*Role
+}
+
+func (m *MockRoleStore) roles() []*Role {
+	if m.Roles == nil {
+		m.Roles = []*Role{
+			{ID: 1, Name: "user", Level: 1},
+			{ID: 2, Name: "moderator", Level: 2},
+			{ID: 3, Name: "admin", Level: 3},
+		}
+	}
+	return m.Roles
+}
+
+func (m *MockRoleStore) GetByName(_ context.Context, name string) (*Role, error) {
+	for _, role := range m.roles() {
+		if role.Name == name {
+			return &Role{ID: role.ID, Name: role.Name, Level: role.Level, Description: role.Description, RequiresTwoFactor: role.RequiresTwoFactor}, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *MockRoleStore) GetByID(_ context.Context, id int) (*Role, error) {
+	for _, role := range m.roles() {
+		if role.ID == id {
+			r := *role
+			r.Permissions = slices.Clone(role.Permissions)
+			return &r, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *MockRoleStore) GetAll(context.Context) ([]Role, error) {
+	roles := []Role{}
+	for _, role := range m.roles() {
+		roles = append(roles, *role)
+	}
+	return roles, nil
+}
+
+func (m *MockRoleStore) Create(_ context.Context, role *Role) error {
+	for _, r := range m.roles() {
+		if r.Name == role.Name {
+			return ErrConflict
+		}
+	}
+	if err := checkMockPermissions(role.Permissions); err != nil {
+		return err
+	}
+
+	role.ID = len(m.Roles) + 1
+	r := *role
+	r.Permissions = slices.Compact(slices.Sorted(slices.Values(role.Permissions)))
+	m.Roles = append(m.Roles, &r)
+	return nil
+}
+
+func (m *MockRoleStore) Update(_ context.Context, role *Role) error {
+	for _, r := range m.roles() {
+		if r.Name == role.Name && r.ID != role.ID {
+			return ErrConflict
+		}
+	}
+	for _, r := range m.Roles {
+		if r.ID == role.ID {
+			r.Name, r.Level, r.Description, r.RequiresTwoFactor = role.Name, role.Level, role.Description, role.RequiresTwoFactor
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *MockRoleStore) SetPermissions(_ context.Context, roleID int, permissions []string) error {
+	if err := checkMockPermissions(permissions); err != nil {
+		return err
+	}
+	for _, r := range m.roles() {
+		if r.ID == roleID {
+			r.Permissions = slices.Compact(slices.Sorted(slices.Values(permissions)))
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *MockRoleStore) GetPermissions(_ context.Context, roleID int) ([]string, error) {
+	for _, r := range m.roles() {
+		if r.ID == roleID {
+			return r.Permissions, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockRoleStore) GetAllPermissions(context.Context) ([]Permission, error) {
+	permissions := []Permission{}
+	for _, name := range mockPermissions {
+		permissions = append(permissions, Permission{Name: name})
+	}
+	return permissions, nil
+}
+
+var mockPermissions = []string{
+	PermCommentsDeleteAny, PermCommentsModerate, PermCommentsUpdateAny, PermPostsDeleteAny,
+	PermPostsReadAny, PermPostsUpdateAny, PermRolesManage, PermTagsManage, PermUsersManage,
+}
+
+func checkMockPermissions(permissions []string) error {
+	for _, p := range permissions {
+		if !slices.Contains(mockPermissions, p) {
+			return ErrUnknownPermission
+		}
+	}
+	return nil
+}
+
+// MockTokenStore remembers the Revoked access tokens. RefreshTokens maps token hashes to refresh tokens.
+type MockTokenStore struct {
+	Revoked       []uuid.UUID
+	RefreshTokens map[string]*RefreshToken
+}
+
+func (m *MockTokenStore) CreateRefreshToken(context.Context, *RefreshToken) error {
+	return nil
+}
+
+func (m *MockTokenStore) GetRefreshToken(_ context.Context, tokenHash string) (*RefreshToken, error) {
+	token, ok := m.RefreshTokens[tokenHash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return token, nil
+}
+
+func (m *MockTokenStore) RotateRefreshToken(context.Context, string, *RefreshToken) error {
+	return nil
+}
+
+func (m *MockTokenStore) RevokeRefreshTokenFamily(_ context.Context, tokenHash string, userID uuid.UUID) error {
+	token, ok := m.RefreshTokens[tokenHash]
+	if !ok {
+		return nil
+	}
+	if token.UserID != userID {
+		return ErrNotFound
+	}
+
+	now := time.Now()
+	for _, t := range m.RefreshTokens {
+		if t.FamilyID == token.FamilyID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *MockTokenStore) RevokeAccessToken(_ context.Context, jti uuid.UUID, _ time.Time) error {
+	if !slices.Contains(m.Revoked, jti) {
+		m.Revoked = append(m.Revoked, jti)
+	}
+	return nil
+}
+
+func (m *MockTokenStore) IsAccessTokenRevoked(_ context.Context, jti uuid.UUID) (bool, error) {
+	return slices.Contains(m.Revoked, jti), nil
+}
+
+type MockSearchStore struct {
+}
+
+func (m *MockSearchStore) Search(context.Context, SearchQuery) ([]SearchResult, error) {
+	return []SearchResult{}, nil
+}
+
+type MockSpamStore struct {
+	Reports []SpamReport
+}
+
+func (m *MockSpamStore) CreateReport(_ context.Context, report *SpamReport) error {
+	report.ID = int64(len(m.Reports) + 1)
+	m.Reports = append(m.Reports, *report)
+	return nil
+}
+
+func (m *MockSpamStore) GetReports(context.Context, SpamReportQuery) ([]SpamReport, error) {
+	return m.Reports, nil
+}
+
+func (m *MockSpamStore) GetReport(_ context.Context, id int64) (*SpamReport, error) {
+	for _, report := range m.Reports {
+		if report.ID == id {
+			return &report, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *MockSpamStore) MarkReviewed(_ context.Context, id int64) error {
+	for i, report := range m.Reports {
+		if report.ID == id && report.ReviewedAt == nil {
+			now := time.Now()
+			m.Reports[i].ReviewedAt = &now
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *MockSpamStore) CountRecentComments(context.Context, uuid.UUID, time.Time, int) (int, error) {
+	return 0, nil
+}
+
+func (m *MockSpamStore) CountFingerprint(context.Context, string, time.Time, int) (int, error) {
+	return 0, nil
+}
+
+type MockTagStore struct {
+}
+
+func (m *MockTagStore) GetAll(context.Context) ([]Tag, error) {
+	return []Tag{}, nil
+}
+
+func (m *MockTagStore) GetBySlug(_ context.Context, slug string) (*Tag, error) {
+	if slug == "missing" {
+		return nil, ErrNotFound
+	}
+	return &Tag{ID: int64(len(slug)), Name: slug, Slug: slug}, nil
+}
+
+func (m *MockTagStore) Rename(context.Context, *Tag) error {
+	return nil
+}
+
+func (m *MockTagStore) Merge(context.Context, int64, int64) error {
+	return nil
+}
+
+type MockMediaStore struct {
+	Files []*MediaFile
+}
+
+func (m *MockMediaStore) Create(_ context.Context, file *MediaFile, quota int64) error {
+	var used int64
+	for _, f := range m.Files {
+		if f.UserID == file.UserID {
+			used += f.Size + f.ThumbnailSize
+		}
+	}
+	if quota > 0 && used+file.Size+file.ThumbnailSize > quota {
+		return ErrQuotaExceeded
+	}
+
+	file.ID = int64(len(m.Files) + 1)
+	file.CreatedAt = time.Now()
+	m.Files = append(m.Files, file)
+	return nil
+}
+
+func (m *MockMediaStore) GetByID(_ context.Context, id int64) (*MediaFile, error) {
+	for _, f := range m.Files {
+		if f.ID == id {
+			return f, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *MockMediaStore) Delete(_ context.Context, id int64) error {
+	for i, f := range m.Files {
+		if f.ID == id {
+			m.Files = append(m.Files[:i], m.Files[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+type MockReactionStore struct {
+	// Reactions maps a post ID to the kinds and the users that reacted with them.
+	Reactions map[int64]map[string][]uuid.UUID
+}
+
+func (m *MockReactionStore) Add(_ context.Context, postID int64, userID uuid.UUID, kind string) error {
+	if m.Reactions == nil {
+		m.Reactions = map[int64]map[string][]uuid.UUID{}
+	}
+	if m.Reactions[postID] == nil {
+		m.Reactions[postID] = map[string][]uuid.UUID{}
+	}
+
+	if !slices.Contains(m.Reactions[postID][kind], userID) {
+		m.Reactions[postID][kind] = append(m.Reactions[postID][kind], userID)
+	}
+	return nil
+}
+
+func (m *MockReactionStore) Remove(_ context.Context, postID int64, userID uuid.UUID, kind string) error {
+	users := m.Reactions[postID][kind]
+	if i := slices.Index(users, userID); i >= 0 {
+		m.Reactions[postID][kind] = slices.Delete(users, i, i+1)
+	}
+	return nil
+}
+
+func (m *MockReactionStore) Load(_ context.Context, posts []*Post, viewerID *uuid.UUID) error {
+	for _, post := range posts {
+		post.Reactions = map[string]int{}
+		post.MyReactions = nil
+		if viewerID != nil {
+			post.MyReactions = []string{}
+		}
+
+		for kind, users := range m.Reactions[post.ID] {
+			if len(users) == 0 {
+				continue
+			}
+			post.Reactions[kind] = len(users)
+			if viewerID != nil && slices.Contains(users, *viewerID) {
+				post.MyReactions = append(post.MyReactions, kind)
+			}
+		}
+		slices.Sort(post.MyReactions)
+	}
+	return nil
+}
+
+// MockFollowStore keeps follows in memory. Users and Tags map a follower to what they follow, in order.
+type MockFollowStore struct {
+	Users map[uuid.UUID][]uuid.UUID
+	Tags  map[uuid.UUID][]int64
+}
+
+func (m *MockFollowStore) FollowUser(_ context.Context, followerID, followeeID uuid.UUID) error {
+	if followerID == followeeID {
+		return ErrFollowSelf
+	}
+	if m.Users == nil {
+		m.Users = map[uuid.UUID][]uuid.UUID{}
+	}
+	if !slices.Contains(m.Users[followerID], followeeID) {
+		m.Users[followerID] = append(m.Users[followerID], followeeID)
+	}
+	return nil
+}
+
+func (m *MockFollowStore) UnfollowUser(_ context.Context, followerID, followeeID uuid.UUID) error {
+	if i := slices.Index(m.Users[followerID], followeeID); i >= 0 {
+		m.Users[followerID] = slices.Delete(m.Users[followerID], i, i+1)
+	}
+	return nil
+}
+
+func (m *MockFollowStore) FollowTag(_ context.Context, userID uuid.UUID, tagID int64) error {
+	if m.Tags == nil {
+		m.Tags = map[uuid.UUID][]int64{}
+	}
+	if !slices.Contains(m.Tags[userID], tagID) {
+		m.Tags[userID] = append(m.Tags[userID], tagID)
+	}
+	return nil
+}
+
+func (m *MockFollowStore) UnfollowTag(_ context.Context, userID uuid.UUID, tagID int64) error {
+	if i := slices.Index(m.Tags[userID], tagID); i >= 0 {
+		m.Tags[userID] = slices.Delete(m.Tags[userID], i, i+1)
+	}
+	return nil
+}
+
+func (m *MockFollowStore) GetFollowers(_ context.Context, userID uuid.UUID, fq FollowQuery) ([]Follow, error) {
+	follows := []Follow{}
+	for follower, followees := range m.Users {
+		if slices.Contains(followees, userID) {
+			follows = append(follows, Follow{UserID: follower})
+		}
+	}
+	return pageFollows(follows, fq), nil
+}
+
+func (m *MockFollowStore) GetFollowing(_ context.Context, userID uuid.UUID, fq FollowQuery) ([]Follow, error) {
+	follows := []Follow{}
+	for _, followee := range m.Users[userID] {
+		follows = append(follows, Follow{UserID: followee})
+	}
+	return pageFollows(follows, fq), nil
+}
+
+func pageFollows(follows []Follow, fq FollowQuery) []Follow {
+	if fq.Offset >= len(follows) {
+		return []Follow{}
+	}
+	follows = follows[fq.Offset:]
+	if len(follows) > fq.Limit {
+		follows = follows[:fq.Limit]
+	}
+	return follows
+}
+
+func (m *MockFollowStore) GetFollowedTags(_ context.Context, userID uuid.UUID) ([]Tag, error) {
+	tags := []Tag{}
+	for _, id := range m.Tags[userID] {
+		tags = append(tags, Tag{ID: id})
+	}
+	return tags, nil
+}
+
+func (m *MockFollowStore) Counts(_ context.Context, userID uuid.UUID) (*FollowCounts, error) {
+	counts := &FollowCounts{Following: len(m.Users[userID])}
+	for _, followees := range m.Users {
+		if slices.Contains(followees, userID) {
+			counts.Followers++
+		}
+	}
+	return counts, nil
+}
+
+// MockBookmarkStore keeps bookmarks in memory. Notes maps a user to the IDs of their bookmarked posts and the notes.
+type MockBookmarkStore struct {
+	Notes map[uuid.UUID]map[int64]string
+}
+
+func (m *MockBookmarkStore) Save(_ context.Context, userID uuid.UUID, postID int64, note *string) (*Bookmark, error) {
+	if m.Notes == nil {
+		m.Notes = map[uuid.UUID]map[int64]string{}
+	}
+	if m.Notes[userID] == nil {
+		m.Notes[userID] = map[int64]string{}
+	}
+
+	if note != nil {
+		m.Notes[userID][postID] = *note
+	} else if _, ok := m.Notes[userID][postID]; !ok {
+		m.Notes[userID][postID] = ""
+	}
+	return &Bookmark{PostID: postID, Note: m.Notes[userID][postID]}, nil
+}
+
+func (m *MockBookmarkStore) Remove(_ context.Context, userID uuid.UUID, postID int64) error {
+	delete(m.Notes[userID], postID)
+	return nil
+}
+
+func (m *MockBookmarkStore) GetByUser(_ context.Context, userID uuid.UUID, bq BookmarkQuery) ([]Bookmark, error) {
+	ids := make([]int64, 0, len(m.Notes[userID]))
+	for id := range m.Notes[userID] {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	bookmarks := []Bookmark{}
+	bookmarked := true
+	for _, id := range ids {
+		bookmarks = append(bookmarks, Bookmark{PostID: id, Note: m.Notes[userID][id], Post: &Post{ID: id, Bookmarked: &bookmarked}})
+	}
+
+	if bq.Offset >= len(bookmarks) {
+		return []Bookmark{}, nil
+	}
+	bookmarks = bookmarks[bq.Offset:]
+	if len(bookmarks) > bq.Limit {
+		bookmarks = bookmarks[:bq.Limit]
+	}
+	return bookmarks, nil
+}
+
+func (m *MockBookmarkStore) Load(_ context.Context, posts []*Post, viewerID *uuid.UUID) error {
+	for _, post := range posts {
+		post.Bookmarked = nil
+		if viewerID != nil {
+			_, ok := m.Notes[*viewerID][post.ID]
+			post.Bookmarked = &ok
+		}
+	}
+	return nil
+}
+
+// MockSuspensionStore suspends the Users in memory and records the Suspensions.
+type MockSuspensionStore struct {
+	Users       []*User
+	Suspensions []Suspension
+}
+
+func (m *MockSuspensionStore) Suspend(_ context.Context, suspension *Suspension) error {
+	for _, user := range m.Users {
+		if user.ID == suspension.UserID {
+			reason := suspension.Reason
+			user.SuspensionReason, user.SuspendedUntil = &reason, suspension.Until
+			user.Version++
+
+			suspension.ID = int64(len(m.Suspensions) + 1)
+			suspension.CreatedAt = time.Now()
+			m.Suspensions = append(m.Suspensions, *suspension)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *MockSuspensionStore) Lift(_ context.Context, userID, liftedBy uuid.UUID) error {
+	for _, user := range m.Users {
+		if user.ID == userID && user.Suspended(time.Now()) {
+			user.SuspensionReason, user.SuspendedUntil = nil, nil
+			user.Version++
+
+			now := time.Now()
+			for i := range m.Suspensions {
+				if m.Suspensions[i].UserID == userID && m.Suspensions[i].LiftedAt == nil {
+					m.Suspensions[i].LiftedBy, m.Suspensions[i].LiftedAt = &liftedBy, &now
+				}
+			}
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *MockSuspensionStore) GetByUser(_ context.Context, userID uuid.UUID) ([]Suspension, error) {
+	suspensions := []Suspension{}
+	for i := len(m.Suspensions) - 1; i >= 0; i-- {
+		if m.Suspensions[i].UserID == userID {
+			suspensions = append(suspensions, m.Suspensions[i])
+		}
+	}
+	return suspensions, nil
+}
+
+// MockTwoFactorStore keeps enrolments and the hashes of unused recovery codes in memory, and enables
+// two-factor authentication on the Users.
+type MockTwoFactorStore struct {
+	Users         []*User
+	Enrolments    map[uuid.UUID]*TwoFactorEnrolment
+	RecoveryCodes map[uuid.UUID][]string
+}
+
+func (m *MockTwoFactorStore) Get(_ context.Context, userID uuid.UUID) (*TwoFactorEnrolment, error) {
+	tf, ok := m.Enrolments[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	t := *tf
+	return &t, nil
+}
+
+func (m *MockTwoFactorStore) SetSecret(_ context.Context, userID uuid.UUID, secret string) error {
+	if tf, ok := m.Enrolments[userID]; ok && tf.Enabled {
+		return ErrConflict
+	}
+	if m.Enrolments == nil {
+		m.Enrolments = map[uuid.UUID]*TwoFactorEnrolment{}
+	}
+	m.Enrolments[userID] = &TwoFactorEnrolment{Secret: secret}
+	return nil
+}
+
+func (m *MockTwoFactorStore) Enable(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	tf, ok := m.Enrolments[userID]
+	if !ok || tf.Enabled {
+		return ErrConflict
+	}
+	tf.Enabled = true
+	m.setEnabled(userID, true)
+	return m.ReplaceRecoveryCodes(ctx, userID, codeHashes)
+}
+
+func (m *MockTwoFactorStore) Disable(_ context.Context, userID uuid.UUID) error {
+	if tf, ok := m.Enrolments[userID]; !ok || !tf.Enabled {
+		return ErrNotFound
+	}
+	delete(m.Enrolments, userID)
+	delete(m.RecoveryCodes, userID)
+	m.setEnabled(userID, false)
+	return nil
+}
+
+func (m *MockTwoFactorStore) setEnabled(userID uuid.UUID, enabled bool) {
+	for _, user := range m.Users {
+		if user.ID == userID {
+			user.TwoFactorEnabled = enabled
+			user.Version++
+		}
+	}
+}
+
+func (m *MockTwoFactorStore) UseCode(_ context.Context, userID uuid.UUID, counter int64) error {
+	tf, ok := m.Enrolments[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	if counter <= tf.LastCounter {
+		return ErrCodeReused
+	}
+	tf.LastCounter = counter
+	return nil
+}
+
+func (m *MockTwoFactorStore) UseRecoveryCode(_ context.Context, userID uuid.UUID, codeHash string) error {
+	i := slices.Index(m.RecoveryCodes[userID], codeHash)
+	if i < 0 {
+		return ErrNotFound
+	}
+	m.RecoveryCodes[userID] = slices.Delete(m.RecoveryCodes[userID], i, i+1)
+	return nil
+}
+
+func (m *MockTwoFactorStore) ReplaceRecoveryCodes(_ context.Context, userID uuid.UUID, codeHashes []string) error {
+	if m.RecoveryCodes == nil {
+		m.RecoveryCodes = map[uuid.UUID][]string{}
+	}
+	m.RecoveryCodes[userID] = slices.Clone(codeHashes)
+	return nil
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultFeedLimit = 20
+	MaxFeedLimit     = 100
+
+	SortAsc  = "asc"
+	SortDesc = "desc"
+
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// PaginatedFeedQuery describes a page of posts. Pages are keyset paginated over (published_at, id),
+// so inserting new posts does not shift the pages a client is currently walking through.
+type PaginatedFeedQuery struct {
+	Limit    int
+	Sort     string
+	Cursor   *FeedCursor
+	Tags     []string
+	TagMatch string
+	// Author is either a user ID or a username.
+	Author string
+	// Since and Until filter by publish time, or the creation time of drafts.
+	Since *time.Time
+	Until *time.Time
+	// Status only returns posts with this status.
+	Status string
+	// ViewerID is the authenticated user. Besides published posts, the viewer sees their own posts.
+	ViewerID *uuid.UUID
+	// IncludeUnpublished shows posts of every status, e.g. to admins.
+	IncludeUnpublished bool
+	// FollowedBy only returns posts by authors or with tags this user follows.
+	FollowedBy *uuid.UUID
+}
+
+// FeedCursor points at the last post of a page. The next page starts right after it.
+type FeedCursor struct {
+	PublishedAt time.Time `json:"t"`
+	ID          int64     `json:"id"`
+	Sort        string    `json:"s"`
+}
+
+// Encode returns the cursor as an opaque, URL-safe string.
+func (c FeedCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeFeedCursor parses a cursor created by FeedCursor.Encode.
+func DecodeFeedCursor(s string) (*FeedCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c FeedCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if c.ID == 0 || c.PublishedAt.IsZero() || (c.Sort != SortAsc && c.Sort != SortDesc) {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
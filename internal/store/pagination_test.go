@@ -0,0 +1,116 @@
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestFeedCursor_EncodeDecode(t *testing.T) {
+	cursor := FeedCursor{
+		PublishedAt: time.Date(2025, 3, 1, 12, 30, 0, 123456000, time.UTC),
+		ID:          42,
+		Sort:        SortDesc,
+	}
+
+	decoded, err := DecodeFeedCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("DecodeFeedCursor() error = %v", err)
+	}
+
+	if !decoded.PublishedAt.Equal(cursor.PublishedAt) || decoded.ID != cursor.ID || decoded.Sort != cursor.Sort {
+		t.Errorf("DecodeFeedCursor() = %+v, want %+v", decoded, cursor)
+	}
+
+	for _, invalid := range []string{"", "not base64!", "e30", FeedCursor{ID: 1, Sort: "up", PublishedAt: time.Now()}.Encode()} {
+		if _, err := DecodeFeedCursor(invalid); err != ErrInvalidCursor {
+			t.Errorf("DecodeFeedCursor(%q) error = %v, want %v", invalid, err, ErrInvalidCursor)
+		}
+	}
+}
+
+func TestBuildFeedQuery(t *testing.T) {
+	since := time.Now().Add(-time.Hour)
+	viewer := uuid.New()
+
+	tests := []struct {
+		name     string
+		fq       PaginatedFeedQuery
+		contains []string
+		args     int
+	}{
+		{
+			name:     "defaults",
+			fq:       PaginatedFeedQuery{Limit: 20, Sort: SortDesc},
+			contains: []string{"p.status = 'published'", "ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC", "LIMIT $1"},
+			args:     1,
+		},
+		{
+			name:     "viewer sees own unpublished posts",
+			fq:       PaginatedFeedQuery{Limit: 20, Sort: SortDesc, ViewerID: &viewer, Status: PostStatusDraft},
+			contains: []string{"(p.status = 'published' OR p.user_id = $1)", "p.status = $2"},
+			args:     3,
+		},
+		{
+			name:     "admin sees everything",
+			fq:       PaginatedFeedQuery{Limit: 20, Sort: SortDesc, ViewerID: &viewer, IncludeUnpublished: true},
+			contains: []string{"LIMIT $1"},
+			args:     1,
+		},
+		{
+			name: "all filters ascending",
+			fq: PaginatedFeedQuery{
+				Limit:    10,
+				Sort:     SortAsc,
+				Tags:     []string{"go", "sql"},
+				TagMatch: TagMatchAll,
+				Author:   "gopher",
+				Since:    &since,
+				Cursor:   &FeedCursor{PublishedAt: since, ID: 3, Sort: SortAsc},
+			},
+			contains: []string{
+				"NOT EXISTS (SELECT 1 FROM unnest($1::text[]) f(slug)",
+				"(p.user_id::text = $2 OR u.username = $2)",
+				"COALESCE(p.published_at, p.created_at) >= $3",
+				"(COALESCE(p.published_at, p.created_at), p.id) > ($4, $5)",
+				"ORDER BY COALESCE(p.published_at, p.created_at) ASC, p.id ASC",
+				"LIMIT $6",
+			},
+			args: 6,
+		},
+		{
+			name:     "timeline",
+			fq:       PaginatedFeedQuery{Limit: 20, Sort: SortDesc, ViewerID: &viewer, FollowedBy: &viewer},
+			contains: []string{"SELECT followee_id FROM follows WHERE follower_id = $2", "tf.user_id = $2"},
+			args:     3,
+		},
+		{
+			name:     "any tag",
+			fq:       PaginatedFeedQuery{Limit: 20, Sort: SortDesc, Tags: []string{"go"}, TagMatch: TagMatchAny},
+			contains: []string{"t.slug = ANY($1)"},
+			args:     2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := buildFeedQuery(tt.fq)
+
+			for _, want := range tt.contains {
+				if !strings.Contains(query, want) {
+					t.Errorf("query should contain %q:\n%s", want, query)
+				}
+			}
+
+			if len(args) != tt.args {
+				t.Errorf("expected %d arguments, got %d", tt.args, len(args))
+			}
+
+			if limit := args[len(args)-1]; limit != tt.fq.Limit+1 {
+				t.Errorf("expected limit argument %d, got %v", tt.fq.Limit+1, limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/ITine-Tech/blog/internal/slug"
+)
+
+// postSlugLock is the advisory lock that serializes picking new post slugs.
+const postSlugLock = 7231
+
+// slugBase is the slug of a post title without collision suffix.
+// Titles without letters or digits fall back to "post".
+func slugBase(title string) string {
+	if s := slug.Make(title); s != "" {
+		return s
+	}
+	return "post"
+}
+
+// hasSlugBase reports whether s is base, or base with a collision suffix like "-2".
+func hasSlugBase(s, base string) bool {
+	if s == base {
+		return true
+	}
+
+	suffix, ok := strings.CutPrefix(s, base+"-")
+	if !ok {
+		return false
+	}
+	n, err := strconv.Atoi(suffix)
+	return err == nil && n > 1 && strconv.Itoa(n) == suffix
+}
+
+// nextSlug returns base if it is not taken, and otherwise base with the lowest free suffix, starting at "-2".
+func nextSlug(base string, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, s := range taken {
+		used[s] = true
+	}
+
+	s := base
+	for n := 2; used[s]; n++ {
+		s = base + "-" + strconv.Itoa(n)
+	}
+	return s
+}
+
+// lockPostSlugs keeps concurrent transactions from picking the same slug until tx ends.
+func lockPostSlugs(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, postSlugLock)
+	return err
+}
+
+// uniquePostSlug returns a slug for title that no other post uses or has used.
+// postID is the post the slug is for, whose own former slugs may be reused; it is 0 for new posts.
+func uniquePostSlug(ctx context.Context, tx *sql.Tx, title string, postID int64) (string, error) {
+	base := slugBase(title)
+
+	query := `
+		SELECT slug FROM posts WHERE (slug = $1 OR slug LIKE $2) AND id <> $3
+		UNION
+		SELECT slug FROM post_slugs WHERE (slug = $1 OR slug LIKE $2) AND post_id <> $3
+		`
+
+	rows, err := tx.QueryContext(ctx, query, base, base+"-%", postID)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	taken := []string{}
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return "", err
+		}
+		taken = append(taken, s)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	return nextSlug(base, taken), nil
+}
+
+// retirePostSlug keeps the old slug of a post so that it keeps leading to the post,
+// and drops the new slug from the history in case the post had it before.
+func retirePostSlug(ctx context.Context, tx *sql.Tx, postID int64, oldSlug, newSlug string) error {
+	query := `
+		INSERT INTO post_slugs (slug, post_id)
+		VALUES ($1, $2)
+		ON CONFLICT (slug) DO NOTHING
+		`
+	if _, err := tx.ExecContext(ctx, query, oldSlug, postID); err != nil {
+		return err
+	}
+
+	query = `
+		DELETE FROM post_slugs WHERE post_id = $1 AND slug = $2
+		`
+	_, err := tx.ExecContext(ctx, query, postID, newSlug)
+	return err
+}
@@ -0,0 +1,54 @@
+package store
+
+import "testing"
+
+func TestSlugBase(t *testing.T) {
+	if got := slugBase("Ünïcode Títle: Part 2"); got != "unicode-title-part-2" {
+		t.Errorf("expected unicode-title-part-2, got %q", got)
+	}
+	if got := slugBase("？？？"); got != "post" {
+		t.Errorf("expected the fallback slug post, got %q", got)
+	}
+}
+
+func TestHasSlugBase(t *testing.T) {
+	tests := []struct {
+		slug string
+		want bool
+	}{
+		{slug: "hello-world", want: true},
+		{slug: "hello-world-2", want: true},
+		{slug: "hello-world-15", want: true},
+		{slug: "hello-world-1", want: false},
+		{slug: "hello-world-02", want: false},
+		{slug: "hello-world-again", want: false},
+		{slug: "hello", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := hasSlugBase(tt.slug, "hello-world"); got != tt.want {
+			t.Errorf("hasSlugBase(%q) = %v, want %v", tt.slug, got, tt.want)
+		}
+	}
+}
+
+func TestNextSlug(t *testing.T) {
+	tests := []struct {
+		name  string
+		taken []string
+		want  string
+	}{
+		{name: "free", taken: nil, want: "hello"},
+		{name: "taken", taken: []string{"hello"}, want: "hello-2"},
+		{name: "gap", taken: []string{"hello", "hello-3"}, want: "hello-2"},
+		{name: "unrelated suffix", taken: []string{"hello", "hello-2", "hello-world"}, want: "hello-3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextSlug("hello", tt.taken); got != tt.want {
+				t.Errorf("nextSlug() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
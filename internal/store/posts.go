@@ -4,109 +4,344 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/ITine-Tech/blog/internal/markdown"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
+const (
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled"
+	PostStatusPublished = "published"
+	PostStatusArchived  = "archived"
+)
+
+var (
+	ErrInvalidStatus      = errors.New("status must be one of draft, scheduled, published or archived")
+	ErrInvalidPublishTime = errors.New("scheduled posts need a publish time in the future")
+)
+
 type Post struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Text      string    `json:"text"`
-	UserID    uuid.UUID `json:"user_id"`
-	Tags      []string  `json:"tags"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Version   int       `json:"version"`
-	Comments  []Comment `json:"comments"`
+	ID          int64      `json:"id"`
+	Title       string     `json:"title"`
+	Slug        string     `json:"slug"`
+	Text        string     `json:"text"`
+	HTML        string     `json:"html"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Tags        []string   `json:"tags"`
+	Status      string     `json:"status"`
+	PublishedAt *time.Time `json:"published_at"`
+	// Moderation overrides the global comment moderation policy for this post.
+	Moderation *string   `json:"moderation"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Version    int       `json:"version"`
+	// Reactions counts the reactions to the post by kind.
+	Reactions map[string]int `json:"reactions"`
+	// MyReactions are the kinds the authenticated user reacted with. It is left out for anonymous requests.
+	MyReactions []string `json:"my_reactions,omitzero"`
+	// Bookmarked tells whether the authenticated user bookmarked the post. It is left out for anonymous requests.
+	Bookmarked *bool     `json:"bookmarked,omitzero"`
+	Comments   []Comment `json:"comments"`
+}
+
+// SetStatus moves the post to status.
+// Publishing sets the publish time to now, scheduling requires publishAt to be in the future,
+// and turning a post back into a draft clears the publish time.
+func (p *Post) SetStatus(status string, publishAt *time.Time, now time.Time) error {
+	switch status {
+	case PostStatusDraft:
+		p.PublishedAt = nil
+	case PostStatusScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return ErrInvalidPublishTime
+		}
+		t := *publishAt
+		p.PublishedAt = &t
+	case PostStatusPublished:
+		if p.Status != PostStatusPublished || p.PublishedAt == nil {
+			t := now
+			p.PublishedAt = &t
+		}
+	case PostStatusArchived:
+	default:
+		return ErrInvalidStatus
+	}
+
+	p.Status = status
+	return nil
+}
+
+// IsPublished reports whether the post is visible to everyone.
+func (p *Post) IsPublished() bool {
+	return p.Status == PostStatusPublished
 }
 
 type PostsPostgreStore struct {
 	db *sql.DB
 }
 
+// CreatePost saves the post and its tags. Tags that do not exist yet are created,
+// existing ones are matched by slug, so post.Tags ends up with their stored names.
+// The post gets a slug derived from its title that no other post uses or has used.
 func (s *PostsPostgreStore) CreatePost(ctx context.Context, post *Post) error {
-	query := `
-	INSERT INTO posts (title, text, user_id, tags)
-	VALUES ($1, $2, $3, $4)
-	RETURNING id, created_at, updated_at
-	`
-
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-	err := s.db.QueryRowContext(
-		ctx,
-		query,
-		post.Title,
-		post.Text,
-		post.UserID,
-		pq.Array(post.Tags),
-	).Scan(
-		&post.ID,
-		&post.CreatedAt,
-		&post.UpdatedAt,
-	)
+	tags, err := NormalizeTags(post.Tags)
 	if err != nil {
 		return err
 	}
-	return nil
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		if err := lockPostSlugs(ctx, tx); err != nil {
+			return err
+		}
+
+		postSlug, err := uniquePostSlug(ctx, tx, post.Title, 0)
+		if err != nil {
+			return err
+		}
+
+		query := `
+	INSERT INTO posts (title, slug, text, html, html_version, user_id, status, published_at, moderation)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING id, created_at, updated_at, version
+	`
+
+		post.HTML = markdown.Render(post.Text, markdown.Post)
+
+		err = tx.QueryRowContext(
+			ctx,
+			query,
+			post.Title,
+			postSlug,
+			post.Text,
+			post.HTML,
+			markdown.Version,
+			post.UserID,
+			post.Status,
+			post.PublishedAt,
+			post.Moderation,
+		).Scan(
+			&post.ID,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&post.Version,
+		)
+		if err != nil {
+			return err
+		}
+
+		post.Slug = postSlug
+		post.Tags, err = setPostTags(ctx, tx, post.ID, tags)
+		return err
+	})
 }
 
-func (s *PostsPostgreStore) GetAllPosts(ctx context.Context) ([]*Post, error) {
-	rows, err := s.db.Query(`SELECT * FROM posts`)
+// GetAllPosts returns one page of the feed described by fq.
+// The second return value is the cursor of the next page, or an empty string if this is the last page.
+func (s *PostsPostgreStore) GetAllPosts(ctx context.Context, fq PaginatedFeedQuery) ([]*Post, string, error) {
+	query, args := buildFeedQuery(fq)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	defer rows.Close()
 
-	var result []*Post
+	result := []*Post{}
 
 	for rows.Next() {
 		post := &Post{}
+		var htmlVersion int
 		err := rows.Scan(
 			&post.ID,
 			&post.Title,
+			&post.Slug,
 			&post.Text,
 			&post.UserID,
 			pq.Array(&post.Tags),
+			&post.Status,
+			&post.PublishedAt,
+			&post.Moderation,
 			&post.CreatedAt,
 			&post.UpdatedAt,
 			&post.Version,
+			&post.HTML,
+			&htmlVersion,
 		)
 
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
+		post.HTML = renderedHTML(post.Text, post.HTML, htmlVersion, markdown.Post)
 
 		result = append(result, post)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(result) > fq.Limit {
+		result = result[:fq.Limit]
+		last := result[len(result)-1]
+		nextCursor = FeedCursor{PublishedAt: last.feedTime(), ID: last.ID, Sort: fq.Sort}.Encode()
+	}
+
+	if err := loadReactions(ctx, s.db, result, fq.ViewerID); err != nil {
+		return nil, "", err
+	}
+	if err := loadBookmarked(ctx, s.db, result, fq.ViewerID); err != nil {
+		return nil, "", err
+	}
 
-	return result, nil
+	return result, nextCursor, nil
+}
+
+// feedTimeColumn orders the feed by publish time. Drafts have none and use their creation time instead.
+const feedTimeColumn = "COALESCE(p.published_at, p.created_at)"
+
+// feedTime is the value of feedTimeColumn for the post.
+func (p *Post) feedTime() time.Time {
+	if p.PublishedAt != nil {
+		return *p.PublishedAt
+	}
+	return p.CreatedAt
+}
+
+// buildFeedQuery turns fq into a SQL query and its arguments.
+// It selects one row more than the limit, so the caller can tell whether there is a next page.
+func buildFeedQuery(fq PaginatedFeedQuery) (string, []any) {
+	var (
+		conditions []string
+		args       []any
+	)
+
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if !fq.IncludeUnpublished {
+		if fq.ViewerID != nil {
+			conditions = append(conditions, fmt.Sprintf("(p.status = '%s' OR p.user_id = %s)", PostStatusPublished, arg(*fq.ViewerID)))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("p.status = '%s'", PostStatusPublished))
+		}
+	}
+
+	if fq.Status != "" {
+		conditions = append(conditions, "p.status = "+arg(fq.Status))
+	}
+
+	if len(fq.Tags) > 0 {
+		tagged := "SELECT 1 FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = p.id AND t.slug = "
+		if fq.TagMatch == TagMatchAll {
+			// no slug of the filter is missing on the post
+			conditions = append(conditions, fmt.Sprintf("NOT EXISTS (SELECT 1 FROM unnest(%s::text[]) f(slug) WHERE NOT EXISTS (%sf.slug))", arg(pq.Array(fq.Tags)), tagged))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("EXISTS (%sANY(%s))", tagged, arg(pq.Array(fq.Tags))))
+		}
+	}
+
+	if fq.Author != "" {
+		n := arg(fq.Author)
+		conditions = append(conditions, fmt.Sprintf("(p.user_id::text = %s OR u.username = %s)", n, n))
+	}
+
+	if fq.FollowedBy != nil {
+		n := arg(*fq.FollowedBy)
+		conditions = append(conditions, fmt.Sprintf(
+			"(p.user_id IN (SELECT followee_id FROM follows WHERE follower_id = %s) OR EXISTS (SELECT 1 FROM post_tags pt JOIN tag_follows tf ON tf.tag_id = pt.tag_id WHERE pt.post_id = p.id AND tf.user_id = %s))",
+			n, n))
+	}
+
+	if fq.Since != nil {
+		conditions = append(conditions, feedTimeColumn+" >= "+arg(*fq.Since))
+	}
+
+	if fq.Until != nil {
+		conditions = append(conditions, feedTimeColumn+" < "+arg(*fq.Until))
+	}
+
+	order := "DESC"
+	comparator := "<"
+	if fq.Sort == SortAsc {
+		order = "ASC"
+		comparator = ">"
+	}
+
+	if fq.Cursor != nil {
+		conditions = append(conditions, fmt.Sprintf("(%s, p.id) %s (%s, %s)", feedTimeColumn, comparator, arg(fq.Cursor.PublishedAt), arg(fq.Cursor.ID)))
+	}
+
+	query := `
+	SELECT p.id, p.title, p.slug, p.text, p.user_id, ` + postTagsColumn + `, p.status, p.published_at, p.moderation, p.created_at, p.updated_at, p.version, p.html, p.html_version
+	FROM posts p
+	JOIN users u ON u.id = p.user_id
+	`
+
+	if len(conditions) > 0 {
+		query += "WHERE " + strings.Join(conditions, " AND ") + "\n"
+	}
+
+	query += fmt.Sprintf("ORDER BY %s %s, p.id %s\nLIMIT %s", feedTimeColumn, order, order, arg(fq.Limit+1))
+
+	return query, args
 }
 
 func (s *PostsPostgreStore) GetPostByID(ctx context.Context, id int64) (*Post, error) {
+	return s.getPost(ctx, "p.id = $1", id)
+}
+
+// GetPostBySlug returns the post with the slug, or the post that had the slug before its title changed.
+// Callers can tell the two apart by comparing the slug of the returned post.
+func (s *PostsPostgreStore) GetPostBySlug(ctx context.Context, slug string) (*Post, error) {
+	return s.getPost(ctx, "p.slug = $1 OR p.id = (SELECT post_id FROM post_slugs WHERE slug = $1)", slug)
+}
+
+// getPost returns the first post matching condition, which refers to the post as p and to arg as $1.
+func (s *PostsPostgreStore) getPost(ctx context.Context, condition string, arg any) (*Post, error) {
 	query := `
-	SELECT id, title, text, user_id, tags, created_at, updated_at, version 
-	FROM posts
-	WHERE id = $1
+	SELECT p.id, p.title, p.slug, p.text, p.user_id, ` + postTagsColumn + `, p.status, p.published_at, p.moderation, p.created_at, p.updated_at, p.version, p.html, p.html_version
+	FROM posts p
+	WHERE ` + condition + `
+	LIMIT 1
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	var post Post
+	var (
+		post        Post
+		htmlVersion int
+	)
 
-	err := s.db.QueryRowContext(ctx, query, id).Scan(
+	err := s.db.QueryRowContext(ctx, query, arg).Scan(
 		&post.ID,
 		&post.Title,
+		&post.Slug,
 		&post.Text,
 		&post.UserID,
 		pq.Array(&post.Tags),
+		&post.Status,
+		&post.PublishedAt,
+		&post.Moderation,
 		&post.CreatedAt,
 		&post.UpdatedAt,
 		&post.Version,
+		&post.HTML,
+		&htmlVersion,
 	)
 	if err != nil {
 		switch {
@@ -116,39 +351,82 @@ func (s *PostsPostgreStore) GetPostByID(ctx context.Context, id int64) (*Post, e
 			return nil, err
 		}
 	}
+
+	post.HTML = renderedHTML(post.Text, post.HTML, htmlVersion, markdown.Post)
 	return &post, nil
 }
 
+// UpdatePost saves the post if it is still at post.Version and increments the version.
+// The previous title and text are kept as a revision in the same transaction.
+// If the title changed so that post.Slug no longer fits it, the post gets a new slug
+// and the old one is kept so that it still leads to the post.
+//
+// Returns ErrConflict if the post has been changed since post.Version was read.
 func (s *PostsPostgreStore) UpdatePost(ctx context.Context, post *Post) error {
-	query := `
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.createRevision(ctx, tx, post.ID, post.Version); err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		postSlug := post.Slug
+		if !hasSlugBase(post.Slug, slugBase(post.Title)) {
+			if err := lockPostSlugs(ctx, tx); err != nil {
+				return err
+			}
+
+			var err error
+			postSlug, err = uniquePostSlug(ctx, tx, post.Title, post.ID)
+			if err != nil {
+				return err
+			}
+
+			if err := retirePostSlug(ctx, tx, post.ID, post.Slug, postSlug); err != nil {
+				return err
+			}
+		}
+
+		query := `
     UPDATE posts
-    SET title = $1, text = $2, updated_at = $3, version = version + 1
+    SET title = $1, text = $2, updated_at = $3, status = $6, published_at = $7, moderation = $8, slug = $9,
+        html = $10, html_version = $11, version = version + 1
     WHERE id = $4 AND version = $5
-    RETURNING version
+    RETURNING version, updated_at
 `
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
+		html := markdown.Render(post.Text, markdown.Post)
 
-	now := time.Now()
-	err := s.db.QueryRowContext(
-		ctx,
-		query,
-		post.Title,
-		post.Text,
-		now,
-		post.ID,
-		post.Version,
-	).Scan(&post.Version)
-	if err != nil {
-		switch {
-		case errors.Is(err, sql.ErrNoRows):
-			return ErrNotFound
-		default:
-			return err
+		now := time.Now()
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			post.Title,
+			post.Text,
+			now,
+			post.ID,
+			post.Version,
+			post.Status,
+			post.PublishedAt,
+			post.Moderation,
+			postSlug,
+			html,
+			markdown.Version,
+		).Scan(&post.Version, &post.UpdatedAt)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return postVersionError(ctx, tx, post.ID)
+			default:
+				return err
+			}
 		}
-	}
-	return nil
+
+		post.Slug = postSlug
+		post.HTML = html
+		return nil
+	})
 }
 
 func (s *PostsPostgreStore) DeletePost(ctx context.Context, PostId int64) error {
@@ -171,3 +449,81 @@ DELETE FROM posts WHERE id = $1
 	}
 	return nil
 }
+
+// PublishScheduledPosts publishes all scheduled posts whose publish time is not after now and increments their version.
+// Like UpdatePost, each post keeps its previous version as a revision in the same transaction.
+// Posts that are changed or deleted while being published are skipped; they are picked up on the next run.
+// It returns the number of posts that were published.
+func (s *PostsPostgreStore) PublishScheduledPosts(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+	SELECT id, version
+	FROM posts
+	WHERE status = 'scheduled' AND published_at <= $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	type scheduledPost struct {
+		id      int64
+		version int
+	}
+
+	var due []scheduledPost
+	for rows.Next() {
+		var post scheduledPost
+		if err := rows.Scan(&post.id, &post.version); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, post)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var published int64
+	for _, post := range due {
+		err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+			if err := s.createRevision(ctx, tx, post.id, post.version); err != nil {
+				return err
+			}
+
+			query := `
+	UPDATE posts
+	SET status = 'published', updated_at = $1, version = version + 1
+	WHERE id = $2 AND version = $3 AND status = 'scheduled'
+	`
+
+			res, err := tx.ExecContext(ctx, query, now, post.id, post.version)
+			if err != nil {
+				return err
+			}
+
+			rows, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if rows == 0 {
+				return postVersionError(ctx, tx, post.id)
+			}
+			return nil
+		})
+		switch {
+		case err == nil:
+			published++
+		case errors.Is(err, ErrConflict), errors.Is(err, ErrNotFound):
+			// Edited or deleted in the meantime; the next run sees the new version.
+		default:
+			return published, err
+		}
+	}
+
+	return published, nil
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPost_SetStatus(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name            string
+		post            Post
+		status          string
+		publishAt       *time.Time
+		wantErr         error
+		wantPublishedAt *time.Time
+	}{
+		{name: "publish draft", post: Post{Status: PostStatusDraft}, status: PostStatusPublished, wantPublishedAt: &now},
+		{name: "republish keeps publish time", post: Post{Status: PostStatusPublished, PublishedAt: &past}, status: PostStatusPublished, wantPublishedAt: &past},
+		{name: "schedule", post: Post{Status: PostStatusDraft}, status: PostStatusScheduled, publishAt: &future, wantPublishedAt: &future},
+		{name: "schedule without time", post: Post{Status: PostStatusDraft}, status: PostStatusScheduled, wantErr: ErrInvalidPublishTime},
+		{name: "schedule in the past", post: Post{Status: PostStatusDraft}, status: PostStatusScheduled, publishAt: &past, wantErr: ErrInvalidPublishTime},
+		{name: "unpublish", post: Post{Status: PostStatusPublished, PublishedAt: &past}, status: PostStatusDraft},
+		{name: "archive keeps publish time", post: Post{Status: PostStatusPublished, PublishedAt: &past}, status: PostStatusArchived, wantPublishedAt: &past},
+		{name: "unknown status", post: Post{Status: PostStatusDraft}, status: "deleted", wantErr: ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := tt.post
+			original := post.Status
+
+			err := post.SetStatus(tt.status, tt.publishAt, now)
+			if err != tt.wantErr {
+				t.Fatalf("Post.SetStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if post.Status != original {
+					t.Errorf("status should not change on error, got %q", post.Status)
+				}
+				return
+			}
+
+			if post.Status != tt.status {
+				t.Errorf("Post.Status = %q, want %q", post.Status, tt.status)
+			}
+
+			switch {
+			case tt.wantPublishedAt == nil && post.PublishedAt != nil:
+				t.Errorf("Post.PublishedAt = %v, want nil", post.PublishedAt)
+			case tt.wantPublishedAt != nil && (post.PublishedAt == nil || !post.PublishedAt.Equal(*tt.wantPublishedAt)):
+				t.Errorf("Post.PublishedAt = %v, want %v", post.PublishedAt, tt.wantPublishedAt)
+			}
+		})
+	}
+}
+
+func TestPostsPostgreStore_PublishScheduledPosts(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &PostsPostgreStore{db: db}
+	now := time.Now()
+
+	for _, post := range []struct {
+		status      string
+		publishedAt time.Time
+	}{
+		{PostStatusScheduled, now.Add(-time.Minute)},
+		{PostStatusScheduled, now.Add(time.Hour)},
+		{PostStatusDraft, now.Add(-time.Minute)},
+	} {
+		_, err := db.Exec(`INSERT INTO posts (title, text, user_id, status, published_at) VALUES (?, ?, ?, ?, ?)`,
+			"Scheduled", "The scheduled text", uuid.NewString(), post.status, post.publishedAt)
+		if err != nil {
+			t.Fatalf("failed to insert test post: %v", err)
+		}
+	}
+
+	published, err := store.PublishScheduledPosts(context.Background(), now)
+	if err != nil {
+		t.Fatalf("PostsPostgreStore.PublishScheduledPosts() error = %v", err)
+	}
+	if published != 1 {
+		t.Errorf("expected 1 published post, got %d", published)
+	}
+
+	rows, err := db.Query(`SELECT status, version FROM posts ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var got []string
+	for rows.Next() {
+		var (
+			status  string
+			version int
+		)
+		if err := rows.Scan(&status, &version); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, fmt.Sprintf("%s %d", status, version))
+	}
+
+	want := []string{"published 2", "scheduled 1", "draft 1"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	revision, err := store.GetRevision(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatalf("PostsPostgreStore.GetRevision() error = %v", err)
+	}
+	if revision.Title != "Scheduled" || revision.Text != "The scheduled text" {
+		t.Errorf("expected the scheduled version as revision, got %+v", revision)
+	}
+
+	revisions, err := store.GetRevisions(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("PostsPostgreStore.GetRevisions() error = %v", err)
+	}
+	if len(revisions) != 0 {
+		t.Errorf("expected no revisions for the post still scheduled, got %d", len(revisions))
+	}
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type ReactionsPostgreStore struct {
+	db *sql.DB
+}
+
+// Add reacts to the post on behalf of the user. Adding a reaction twice has no effect.
+func (s *ReactionsPostgreStore) Add(ctx context.Context, postID int64, userID uuid.UUID, kind string) error {
+	query := `
+		INSERT INTO post_reactions (post_id, user_id, kind)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, postID, userID, kind)
+	return err
+}
+
+// Remove takes back a reaction of the user. Removing a reaction that does not exist has no effect.
+func (s *ReactionsPostgreStore) Remove(ctx context.Context, postID int64, userID uuid.UUID, kind string) error {
+	query := `
+		DELETE FROM post_reactions WHERE post_id = $1 AND user_id = $2 AND kind = $3
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, postID, userID, kind)
+	return err
+}
+
+// Load sets the reaction counts of the posts, and the reactions of the viewer if there is one.
+func (s *ReactionsPostgreStore) Load(ctx context.Context, posts []*Post, viewerID *uuid.UUID) error {
+	return loadReactions(ctx, s.db, posts, viewerID)
+}
+
+// loadReactions counts the reactions of all posts in a single query.
+func loadReactions(ctx context.Context, db *sql.DB, posts []*Post, viewerID *uuid.UUID) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(posts))
+	byID := make(map[int64]*Post, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+		byID[post.ID] = post
+
+		post.Reactions = map[string]int{}
+		post.MyReactions = nil
+		if viewerID != nil {
+			post.MyReactions = []string{}
+		}
+	}
+
+	query := `
+		SELECT post_id, kind, COUNT(*), COALESCE(BOOL_OR(user_id = $2), false)
+		FROM post_reactions
+		WHERE post_id = ANY($1)
+		GROUP BY post_id, kind
+		ORDER BY post_id, kind
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, pq.Array(ids), viewerID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			postID int64
+			kind   string
+			count  int
+			mine   bool
+		)
+		if err := rows.Scan(&postID, &kind, &count, &mine); err != nil {
+			return err
+		}
+
+		post := byID[postID]
+		post.Reactions[kind] = count
+		if mine && viewerID != nil {
+			post.MyReactions = append(post.MyReactions, kind)
+		}
+	}
+	return rows.Err()
+}
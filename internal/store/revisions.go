@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PostRevision is the title and text of a post at a given version.
+type PostRevision struct {
+	PostID    int64     `json:"post_id"`
+	Version   int       `json:"version"`
+	Title     string    `json:"title"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GetRevisions returns all previous versions of a post, newest first.
+// The current version is not included; it is the post itself.
+func (s *PostsPostgreStore) GetRevisions(ctx context.Context, postID int64) ([]PostRevision, error) {
+	query := `
+	SELECT post_id, version, title, text, created_at
+	FROM post_revisions
+	WHERE post_id = $1
+	ORDER BY version DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []PostRevision{}
+
+	for rows.Next() {
+		var revision PostRevision
+		err := rows.Scan(
+			&revision.PostID,
+			&revision.Version,
+			&revision.Title,
+			&revision.Text,
+			&revision.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+func (s *PostsPostgreStore) GetRevision(ctx context.Context, postID int64, version int) (*PostRevision, error) {
+	query := `
+	SELECT post_id, version, title, text, created_at
+	FROM post_revisions
+	WHERE post_id = $1 AND version = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	revision := &PostRevision{}
+	err := s.db.QueryRowContext(ctx, query, postID, version).Scan(
+		&revision.PostID,
+		&revision.Version,
+		&revision.Title,
+		&revision.Text,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return revision, nil
+}
+
+// createRevision copies the post at the given version into post_revisions.
+// It returns ErrConflict if the post is no longer at that version and ErrNotFound if it is gone.
+func (s *PostsPostgreStore) createRevision(ctx context.Context, tx *sql.Tx, postID int64, version int) error {
+	query := `
+	INSERT INTO post_revisions (post_id, version, title, text, created_at)
+	SELECT id, version, title, text, updated_at
+	FROM posts
+	WHERE id = $1 AND version = $2
+	ON CONFLICT (post_id, version) DO NOTHING
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := tx.ExecContext(ctx, query, postID, version)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return postVersionError(ctx, tx, postID)
+	}
+	return nil
+}
+
+// postVersionError tells apart why a versioned write to a post did not affect any row.
+func postVersionError(ctx context.Context, tx *sql.Tx, postID int64) error {
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM posts WHERE id = $1)`, postID).Scan(&exists); err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrConflict
+	}
+	return ErrNotFound
+}
@@ -3,35 +3,303 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Permissions that can be assigned to roles.
+const (
+	PermPostsReadAny      = "posts:read:any"
+	PermPostsUpdateAny    = "posts:update:any"
+	PermPostsDeleteAny    = "posts:delete:any"
+	PermCommentsUpdateAny = "comments:update:any"
+	PermCommentsDeleteAny = "comments:delete:any"
+	PermCommentsModerate  = "comments:moderate"
+	PermTagsManage        = "tags:manage"
+	PermUsersManage       = "users:manage"
+	PermRolesManage       = "roles:manage"
 )
 
+var ErrUnknownPermission = errors.New("unknown permission")
+
 type Role struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
 	Level       int    `json:"level"`
 	Description string `json:"description"`
+	// RequiresTwoFactor makes the users of the role enable two-factor authentication before they can use the API.
+	RequiresTwoFactor bool `json:"requires_two_factor"`
+	// Permissions is only set when roles are managed, not on the role of a user.
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// Permission is something a role may be allowed to do, e.g. posts:delete:any.
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
 }
 
 type RolePostgreStore struct {
 	db *sql.DB
 }
 
+// rolePermissionsColumn selects the names of the permissions of the role r as an array.
+const rolePermissionsColumn = `ARRAY(
+		SELECT p.name FROM role_permissions rp JOIN permissions p ON p.id = rp.permission_id
+		WHERE rp.role_id = r.id ORDER BY p.name
+	)`
+
 func (s *RolePostgreStore) GetByName(ctx context.Context, slug string) (*Role, error) {
 	query := `
-		SELECT id, name, level, description
+		SELECT id, name, level, description, requires_two_factor
 		FROM roles
 		WHERE name = $1
 		`
 
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
 	role := new(Role)
 	err := s.db.QueryRowContext(ctx, query, slug).Scan(
 		&role.ID,
 		&role.Name,
 		&role.Level,
 		&role.Description,
+		&role.RequiresTwoFactor,
 	)
 	if err != nil {
-		return nil, err
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
 	}
 	return role, err
 }
+
+// GetByID returns the role with its permissions.
+func (s *RolePostgreStore) GetByID(ctx context.Context, id int) (*Role, error) {
+	query := `
+		SELECT r.id, r.name, r.level, COALESCE(r.description, ''), r.requires_two_factor, ` + rolePermissionsColumn + `
+		FROM roles r
+		WHERE r.id = $1
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	role := new(Role)
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&role.ID, &role.Name, &role.Level, &role.Description, &role.RequiresTwoFactor, pq.Array(&role.Permissions))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return role, nil
+}
+
+// GetAll returns all roles with their permissions, ordered by level.
+func (s *RolePostgreStore) GetAll(ctx context.Context) ([]Role, error) {
+	query := `
+		SELECT r.id, r.name, r.level, COALESCE(r.description, ''), r.requires_two_factor, ` + rolePermissionsColumn + `
+		FROM roles r
+		ORDER BY r.level, r.name
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []Role{}
+	for rows.Next() {
+		var role Role
+		if err := rows.Scan(&role.ID, &role.Name, &role.Level, &role.Description, &role.RequiresTwoFactor, pq.Array(&role.Permissions)); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// Create inserts the role with its permissions.
+// Returns ErrConflict if a role with the name exists and ErrUnknownPermission for permissions that do not exist.
+func (s *RolePostgreStore) Create(ctx context.Context, role *Role) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO roles (name, level, description, requires_two_factor)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+			`
+
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		if err := tx.QueryRowContext(ctx, query, role.Name, role.Level, role.Description, role.RequiresTwoFactor).Scan(&role.ID); err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				return ErrConflict
+			}
+			return err
+		}
+
+		return setRolePermissions(ctx, tx, role.ID, role.Permissions)
+	})
+}
+
+// Update saves the name, level, description and two-factor requirement of the role.
+// Returns ErrConflict if another role has the name.
+func (s *RolePostgreStore) Update(ctx context.Context, role *Role) error {
+	query := `
+		UPDATE roles SET name = $1, level = $2, description = $3, requires_two_factor = $4 WHERE id = $5
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, role.Name, role.Level, role.Description, role.RequiresTwoFactor, role.ID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrConflict
+		}
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetPermissions replaces the permissions of the role.
+func (s *RolePostgreStore) SetPermissions(ctx context.Context, roleID int, permissions []string) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		var id int
+		if err := tx.QueryRowContext(ctx, `SELECT id FROM roles WHERE id = $1 FOR UPDATE`, roleID).Scan(&id); err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrNotFound
+			default:
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, roleID); err != nil {
+			return err
+		}
+
+		return setRolePermissions(ctx, tx, roleID, permissions)
+	})
+}
+
+// setRolePermissions adds the permissions to the role. Every permission has to exist.
+func setRolePermissions(ctx context.Context, tx *sql.Tx, roleID int, permissions []string) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	unique := slices.Clone(permissions)
+	slices.Sort(unique)
+	unique = slices.Compact(unique)
+
+	query := `
+		INSERT INTO role_permissions (role_id, permission_id)
+		SELECT $1, id FROM permissions WHERE name = ANY($2)
+		`
+
+	res, err := tx.ExecContext(ctx, query, roleID, pq.Array(unique))
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != int64(len(unique)) {
+		return fmt.Errorf("%w in %v", ErrUnknownPermission, unique)
+	}
+	return nil
+}
+
+// GetPermissions returns the names of the permissions of the role.
+func (s *RolePostgreStore) GetPermissions(ctx context.Context, roleID int) ([]string, error) {
+	query := `
+		SELECT p.name
+		FROM role_permissions rp
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE rp.role_id = $1
+		ORDER BY p.name
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, name)
+	}
+	return permissions, rows.Err()
+}
+
+// GetAllPermissions returns all permissions that can be assigned to roles, ordered by name.
+func (s *RolePostgreStore) GetAllPermissions(ctx context.Context) ([]Permission, error) {
+	query := `
+		SELECT name, description FROM permissions ORDER BY name
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []Permission{}
+	for rows.Next() {
+		var p Permission
+		if err := rows.Scan(&p.Name, &p.Description); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
@@ -0,0 +1,132 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SearchTypePost    = "post"
+	SearchTypeComment = "comment"
+
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+
+	// highlight markers used by ts_headline. They are replaced after the snippet has been HTML escaped,
+	// so the stored text cannot inject markup into the snippet.
+	highlightStart = "\x01"
+	highlightStop  = "\x02"
+)
+
+type SearchQuery struct {
+	// Query uses the websearch_to_tsquery syntax: "quoted phrases", OR and -excluded words.
+	Query string
+	// Type restricts the results to posts or comments. Empty searches both.
+	Type string
+	Tag  string
+	// Author is either a user ID or a username.
+	Author string
+	Limit  int
+	Offset int
+}
+
+type SearchResult struct {
+	Type      string    `json:"type"`
+	PostID    int64     `json:"post_id"`
+	CommentID *int64    `json:"comment_id,omitempty"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet"`
+	Rank      float64   `json:"rank"`
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SearchPostgreStore struct {
+	db *sql.DB
+}
+
+// Search returns posts and comments matching sq, best matches first.
+// Snippets are HTML escaped with the matching words wrapped in <mark> tags.
+func (s *SearchPostgreStore) Search(ctx context.Context, sq SearchQuery) ([]SearchResult, error) {
+	// ts_headline is expensive, so the matches are ranked and paged first and only the page gets snippets
+	query := `
+		WITH q AS (SELECT websearch_to_tsquery('english', $1) AS query),
+		page AS (
+			SELECT 'post' AS type, p.id AS post_id, NULL::bigint AS comment_id, p.title, p.text AS body,
+				ts_rank(p.search_vector, q.query) AS rank, p.user_id, p.created_at
+			FROM q, posts p
+			JOIN users u ON u.id = p.user_id
+			WHERE $3 IN ('', 'post')
+				AND p.status = 'published'
+				AND p.search_vector @@ q.query
+				AND ($4 = '' OR EXISTS (SELECT 1 FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = p.id AND t.slug = $4))
+				AND ($5 = '' OR p.user_id::text = $5 OR u.username = $5)
+			UNION ALL
+			SELECT 'comment', p.id, c.id, p.title, c.content,
+				ts_rank(c.search_vector, q.query) AS rank, c.user_id, c.created_at
+			FROM q, comments c
+			JOIN posts p ON p.id = c.post_id
+			JOIN users u ON u.id = c.user_id
+			WHERE $3 IN ('', 'comment')
+				AND p.status = 'published'
+				AND c.status = 'approved'
+				AND c.search_vector @@ q.query
+				AND ($4 = '' OR EXISTS (SELECT 1 FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = p.id AND t.slug = $4))
+				AND ($5 = '' OR c.user_id::text = $5 OR u.username = $5)
+			ORDER BY rank DESC, created_at DESC
+			LIMIT $6 OFFSET $7
+		)
+		SELECT page.type, page.post_id, page.comment_id, page.title,
+			ts_headline('english', page.body, q.query, $2),
+			page.rank, page.user_id, page.created_at
+		FROM q, page
+		ORDER BY page.rank DESC, page.created_at DESC
+		`
+
+	options := "StartSel=" + highlightStart + ", StopSel=" + highlightStop + ", MaxWords=35, MinWords=15, MaxFragments=2"
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, sq.Query, options, sq.Type, sq.Tag, sq.Author, sq.Limit, sq.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+
+	for rows.Next() {
+		var result SearchResult
+		err := rows.Scan(
+			&result.Type,
+			&result.PostID,
+			&result.CommentID,
+			&result.Title,
+			&result.Snippet,
+			&result.Rank,
+			&result.UserID,
+			&result.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Snippet = highlight(result.Snippet)
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// highlight escapes a ts_headline snippet and turns the highlight markers into <mark> tags.
+func highlight(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, highlightStart, "<mark>")
+	return strings.ReplaceAll(snippet, highlightStop, "</mark>")
+}
@@ -0,0 +1,12 @@
+package store
+
+import "testing"
+
+func Test_highlight(t *testing.T) {
+	snippet := "a \x01gopher\x02 wrote <script>alert(1)</script> about \x01Go\x02"
+	want := "a <mark>gopher</mark> wrote &lt;script&gt;alert(1)&lt;/script&gt; about <mark>Go</mark>"
+
+	if got := highlight(snippet); got != want {
+		t.Errorf("highlight() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,206 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	SpamKindComment      = "comment"
+	SpamKindRegistration = "registration"
+)
+
+// SpamReport records a submission the spam filter held or rejected, so admins can review false positives.
+type SpamReport struct {
+	ID   int64  `json:"id"`
+	Kind string `json:"kind"`
+	// CommentID is set for comments, UserID for comments and held registrations.
+	CommentID  *int       `json:"comment_id"`
+	UserID     *uuid.UUID `json:"user_id"`
+	Content    string     `json:"content"`
+	Score      float64    `json:"score"`
+	Reasons    []string   `json:"reasons"`
+	Verdict    string     `json:"verdict"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// SpamReportQuery describes a page of spam reports.
+type SpamReportQuery struct {
+	Kind    string
+	Verdict string
+	// Reviewed includes reports that have already been reviewed.
+	Reviewed bool
+	Limit    int
+	Offset   int
+}
+
+type SpamPostgreStore struct {
+	db *sql.DB
+}
+
+func (s *SpamPostgreStore) CreateReport(ctx context.Context, report *SpamReport) error {
+	query := `
+		INSERT INTO spam_reports (kind, comment_id, user_id, content, score, reasons, verdict)
+		VALUES ($1, $2::bigint, $3::uuid, $4, $5, $6, $7)
+		RETURNING id, created_at
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		report.Kind,
+		report.CommentID,
+		report.UserID,
+		report.Content,
+		report.Score,
+		pq.Array(report.Reasons),
+		report.Verdict,
+	).Scan(
+		&report.ID,
+		&report.CreatedAt,
+	)
+}
+
+// GetReports returns spam reports, newest first.
+func (s *SpamPostgreStore) GetReports(ctx context.Context, sq SpamReportQuery) ([]SpamReport, error) {
+	query := `
+		SELECT id, kind, comment_id, user_id, content, score, reasons, verdict, reviewed_at, created_at
+		FROM spam_reports
+		WHERE ($1 = '' OR kind = $1)
+			AND ($2 = '' OR verdict = $2)
+			AND ($3 OR reviewed_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4 OFFSET $5
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, sq.Kind, sq.Verdict, sq.Reviewed, sq.Limit, sq.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []SpamReport{}
+	for rows.Next() {
+		report, err := scanSpamReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+func (s *SpamPostgreStore) GetReport(ctx context.Context, id int64) (*SpamReport, error) {
+	query := `
+		SELECT id, kind, comment_id, user_id, content, score, reasons, verdict, reviewed_at, created_at
+		FROM spam_reports
+		WHERE id = $1
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	report, err := scanSpamReport(s.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// MarkReviewed records that an admin has looked at the report.
+func (s *SpamPostgreStore) MarkReviewed(ctx context.Context, id int64) error {
+	query := `
+		UPDATE spam_reports SET reviewed_at = NOW() WHERE id = $1 AND reviewed_at IS NULL
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CountRecentComments returns the number of comments the user wrote since the given time,
+// apart from the comment with the ID excludeID, e.g. the one being edited. New comments pass 0.
+func (s *SpamPostgreStore) CountRecentComments(ctx context.Context, userID uuid.UUID, since time.Time, excludeID int) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM comments WHERE user_id = $1 AND created_at >= $2 AND id <> $3
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var count int
+	err := s.db.QueryRowContext(ctx, query, userID, since, excludeID).Scan(&count)
+	return count, err
+}
+
+// CountFingerprint returns the number of comments with the same fingerprint since the given time,
+// apart from the comment with the ID excludeID, e.g. the one being edited. New comments pass 0.
+func (s *SpamPostgreStore) CountFingerprint(ctx context.Context, fingerprint string, since time.Time, excludeID int) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM comments WHERE fingerprint = $1 AND created_at >= $2 AND id <> $3
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var count int
+	err := s.db.QueryRowContext(ctx, query, fingerprint, since, excludeID).Scan(&count)
+	return count, err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSpamReport(row rowScanner) (*SpamReport, error) {
+	report := &SpamReport{}
+	err := row.Scan(
+		&report.ID,
+		&report.Kind,
+		&report.CommentID,
+		&report.UserID,
+		&report.Content,
+		&report.Score,
+		pq.Array(&report.Reasons),
+		&report.Verdict,
+		&report.ReviewedAt,
+		&report.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
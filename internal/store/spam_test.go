@@ -0,0 +1,56 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSpamPostgreStore_CountsExcludeComment(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &SpamPostgreStore{db: db}
+	ctx := context.Background()
+	userID := uuid.New()
+	since := time.Now().Add(-time.Hour)
+
+	for range 2 {
+		_, err := db.Exec(`INSERT INTO comments (post_id, user_id, fingerprint, created_at) VALUES (?, ?, ?, ?)`,
+			1, userID, "same", time.Now())
+		if err != nil {
+			t.Fatalf("failed to insert test comment: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		excludeID int
+		want      int
+	}{
+		{name: "new comment", excludeID: 0, want: 2},
+		{name: "edited comment", excludeID: 1, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recent, err := store.CountRecentComments(ctx, userID, since, tt.excludeID)
+			if err != nil {
+				t.Fatalf("SpamPostgreStore.CountRecentComments() error = %v", err)
+			}
+			if recent != tt.want {
+				t.Errorf("expected %d recent comments, got %d", tt.want, recent)
+			}
+
+			duplicates, err := store.CountFingerprint(ctx, "same", since, tt.excludeID)
+			if err != nil {
+				t.Fatalf("SpamPostgreStore.CountFingerprint() error = %v", err)
+			}
+			if duplicates != tt.want {
+				t.Errorf("expected %d duplicates, got %d", tt.want, duplicates)
+			}
+		})
+	}
+}
@@ -12,49 +12,169 @@ import (
 
 var (
 	ErrNotFound = errors.New("record not found")
+	ErrConflict = errors.New("edit conflict: the record has been changed in the meantime")
 )
 
 type Posts interface {
 	CreatePost(context.Context, *Post) error
-	GetAllPosts(context.Context) ([]*Post, error)
+	GetAllPosts(context.Context, PaginatedFeedQuery) ([]*Post, string, error)
 	GetPostByID(context.Context, int64) (*Post, error)
+	GetPostBySlug(context.Context, string) (*Post, error)
 	UpdatePost(context.Context, *Post) error
 	DeletePost(context.Context, int64) error
+	PublishScheduledPosts(context.Context, time.Time) (int64, error)
+	GetRevisions(context.Context, int64) ([]PostRevision, error)
+	GetRevision(context.Context, int64, int) (*PostRevision, error)
+	RenderStaleHTML(context.Context, int) (int, error)
 }
 
 type Users interface {
 	Create(context.Context, *sql.Tx, *User) error
-	GetAllUsers(context.Context) ([]*User, error)
+	GetAllUsers(context.Context, UserQuery) ([]*User, error)
 	CreateAndInvite(context.Context, *User, string, time.Duration) error
+	Reinvite(context.Context, uuid.UUID, string, time.Duration) error
 	Activate(context.Context, string) error
+	CreatePasswordReset(context.Context, string, string, time.Duration) (*User, error)
+	ResetPassword(context.Context, string, string) error
 	GetUserByID(context.Context, uuid.UUID) (*User, error)
 	GetUserByUsername(context.Context, string) (*User, error)
 	UpdateUser(context.Context, *User) error
+	SetRole(context.Context, uuid.UUID, int) error
+	SetActive(context.Context, uuid.UUID, bool) error
 	DeleteUser(context.Context, uuid.UUID) error
 }
 
 type Roles interface {
 	GetByName(context.Context, string) (*Role, error)
+	GetByID(context.Context, int) (*Role, error)
+	GetAll(context.Context) ([]Role, error)
+	Create(context.Context, *Role) error
+	Update(context.Context, *Role) error
+	SetPermissions(context.Context, int, []string) error
+	GetPermissions(context.Context, int) ([]string, error)
+	GetAllPermissions(context.Context) ([]Permission, error)
 }
 
 type Comments interface {
-	GetByPostID(context.Context, int64) ([]Comment, error)
+	GetByPostID(context.Context, int64, CommentTreeQuery) ([]Comment, error)
 	CreateComment(context.Context, *Comment) error
+	GetByID(context.Context, int) (*Comment, error)
+	UpdateComment(context.Context, *Comment) error
+	DeleteComment(context.Context, int) error
+	HasApprovedComment(context.Context, uuid.UUID) (bool, error)
+	GetByStatus(context.Context, ModerationQuery) ([]Comment, error)
+	SetStatus(context.Context, []int, string) (int64, error)
+	RenderStaleHTML(context.Context, int) (int, error)
+}
+
+type Tokens interface {
+	CreateRefreshToken(context.Context, *RefreshToken) error
+	GetRefreshToken(context.Context, string) (*RefreshToken, error)
+	RotateRefreshToken(context.Context, string, *RefreshToken) error
+	RevokeRefreshTokenFamily(context.Context, string, uuid.UUID) error
+	RevokeAccessToken(context.Context, uuid.UUID, time.Time) error
+	IsAccessTokenRevoked(context.Context, uuid.UUID) (bool, error)
+}
+
+type Search interface {
+	Search(context.Context, SearchQuery) ([]SearchResult, error)
+}
+
+type Tags interface {
+	GetAll(context.Context) ([]Tag, error)
+	GetBySlug(context.Context, string) (*Tag, error)
+	Rename(context.Context, *Tag) error
+	Merge(context.Context, int64, int64) error
+}
+
+type Reactions interface {
+	Add(context.Context, int64, uuid.UUID, string) error
+	Remove(context.Context, int64, uuid.UUID, string) error
+	Load(context.Context, []*Post, *uuid.UUID) error
+}
+
+type Bookmarks interface {
+	Save(context.Context, uuid.UUID, int64, *string) (*Bookmark, error)
+	Remove(context.Context, uuid.UUID, int64) error
+	GetByUser(context.Context, uuid.UUID, BookmarkQuery) ([]Bookmark, error)
+	Load(context.Context, []*Post, *uuid.UUID) error
+}
+
+type Suspensions interface {
+	Suspend(context.Context, *Suspension) error
+	Lift(context.Context, uuid.UUID, uuid.UUID) error
+	GetByUser(context.Context, uuid.UUID) ([]Suspension, error)
+}
+
+type TwoFactor interface {
+	Get(context.Context, uuid.UUID) (*TwoFactorEnrolment, error)
+	SetSecret(context.Context, uuid.UUID, string) error
+	Enable(context.Context, uuid.UUID, []string) error
+	Disable(context.Context, uuid.UUID) error
+	UseCode(context.Context, uuid.UUID, int64) error
+	UseRecoveryCode(context.Context, uuid.UUID, string) error
+	ReplaceRecoveryCodes(context.Context, uuid.UUID, []string) error
+}
+
+type Follows interface {
+	FollowUser(context.Context, uuid.UUID, uuid.UUID) error
+	UnfollowUser(context.Context, uuid.UUID, uuid.UUID) error
+	FollowTag(context.Context, uuid.UUID, int64) error
+	UnfollowTag(context.Context, uuid.UUID, int64) error
+	GetFollowers(context.Context, uuid.UUID, FollowQuery) ([]Follow, error)
+	GetFollowing(context.Context, uuid.UUID, FollowQuery) ([]Follow, error)
+	GetFollowedTags(context.Context, uuid.UUID) ([]Tag, error)
+	Counts(context.Context, uuid.UUID) (*FollowCounts, error)
+}
+
+type Media interface {
+	Create(context.Context, *MediaFile, int64) error
+	GetByID(context.Context, int64) (*MediaFile, error)
+	Delete(context.Context, int64) error
+}
+
+type Spam interface {
+	CreateReport(context.Context, *SpamReport) error
+	GetReports(context.Context, SpamReportQuery) ([]SpamReport, error)
+	GetReport(context.Context, int64) (*SpamReport, error)
+	MarkReviewed(context.Context, int64) error
+	CountRecentComments(context.Context, uuid.UUID, time.Time, int) (int, error)
+	CountFingerprint(context.Context, string, time.Time, int) (int, error)
 }
 
 type Storage struct {
-	Posts    Posts
-	Users    Users
-	Comments Comments
-	Roles    Roles
+	Posts       Posts
+	Users       Users
+	Comments    Comments
+	Roles       Roles
+	Tokens      Tokens
+	Search      Search
+	Spam        Spam
+	Tags        Tags
+	Media       Media
+	Reactions   Reactions
+	Follows     Follows
+	Bookmarks   Bookmarks
+	Suspensions Suspensions
+	TwoFactor   TwoFactor
 }
 
 func NewPostgresStorage(db *sql.DB) Storage {
 	return Storage{
-		Posts:    &PostsPostgreStore{db},
-		Users:    &UsersPostgresStore{db},
-		Comments: &CommentsPostgreStore{db},
-		Roles:    &RolePostgreStore{db},
+		Posts:       &PostsPostgreStore{db},
+		Users:       &UsersPostgresStore{db},
+		Comments:    &CommentsPostgreStore{db},
+		Roles:       &RolePostgreStore{db},
+		Tokens:      &TokensPostgreStore{db},
+		Search:      &SearchPostgreStore{db},
+		Spam:        &SpamPostgreStore{db},
+		Tags:        &TagsPostgreStore{db},
+		Media:       &MediaPostgreStore{db},
+		Reactions:   &ReactionsPostgreStore{db},
+		Follows:     &FollowsPostgreStore{db},
+		Bookmarks:   &BookmarksPostgreStore{db},
+		Suspensions: &SuspensionsPostgreStore{db},
+		TwoFactor:   &TwoFactorPostgreStore{db},
 	}
 }
 
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Suspension is a suspension or, without Until, a ban of a user.
+type Suspension struct {
+	ID     int64     `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	// SuspendedBy and LiftedBy are unset once the admin is deleted.
+	SuspendedBy *uuid.UUID `json:"suspended_by"`
+	Reason      string     `json:"reason"`
+	Until       *time.Time `json:"until"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LiftedBy    *uuid.UUID `json:"lifted_by,omitempty"`
+	LiftedAt    *time.Time `json:"lifted_at,omitempty"`
+}
+
+type SuspensionsPostgreStore struct {
+	db *sql.DB
+}
+
+// Suspend suspends the user of the suspension and records it. A suspension replaces the current one of the user.
+// All refresh tokens of the user are revoked.
+//
+// Returns ErrNotFound if the user does not exist.
+func (s *SuspensionsPostgreStore) Suspend(ctx context.Context, suspension *Suspension) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		now := time.Now()
+
+		query := `
+			UPDATE users SET suspended_until = $1, suspension_reason = $2, updated_at = $3, version = version + 1
+			WHERE id = $4
+			`
+		res, err := tx.ExecContext(ctx, query, suspension.Until, suspension.Reason, now, suspension.UserID)
+		if err != nil {
+			return err
+		}
+		if err := userAffected(res); err != nil {
+			return err
+		}
+
+		if err := liftSuspensions(ctx, tx, suspension.UserID, suspension.SuspendedBy, now); err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO user_suspensions (user_id, suspended_by, reason, suspended_until, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at
+			`
+		err = tx.QueryRowContext(ctx, query, suspension.UserID, suspension.SuspendedBy, suspension.Reason, suspension.Until, now).Scan(&suspension.ID, &suspension.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		query = `
+			UPDATE refresh_tokens SET revoked_at = $1
+			WHERE user_id = $2 AND revoked_at IS NULL
+			`
+		_, err = tx.ExecContext(ctx, query, now, suspension.UserID)
+		return err
+	})
+}
+
+// Lift ends the suspension or ban of the user and records who lifted it.
+//
+// Returns ErrNotFound if the user is not suspended.
+func (s *SuspensionsPostgreStore) Lift(ctx context.Context, userID, liftedBy uuid.UUID) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		now := time.Now()
+
+		query := `
+			UPDATE users SET suspended_until = NULL, suspension_reason = NULL, updated_at = $1, version = version + 1
+			WHERE id = $2 AND suspension_reason IS NOT NULL AND (suspended_until IS NULL OR suspended_until > $1)
+			`
+		res, err := tx.ExecContext(ctx, query, now, userID)
+		if err != nil {
+			return err
+		}
+		if err := userAffected(res); err != nil {
+			return err
+		}
+
+		return liftSuspensions(ctx, tx, userID, &liftedBy, now)
+	})
+}
+
+// liftSuspensions marks the suspensions of the user that are still running as lifted.
+func liftSuspensions(ctx context.Context, tx *sql.Tx, userID uuid.UUID, liftedBy *uuid.UUID, now time.Time) error {
+	query := `
+		UPDATE user_suspensions SET lifted_by = $1, lifted_at = $2
+		WHERE user_id = $3 AND lifted_at IS NULL AND (suspended_until IS NULL OR suspended_until > $2)
+		`
+
+	_, err := tx.ExecContext(ctx, query, liftedBy, now, userID)
+	return err
+}
+
+// GetByUser returns all suspensions of the user, most recent first.
+func (s *SuspensionsPostgreStore) GetByUser(ctx context.Context, userID uuid.UUID) ([]Suspension, error) {
+	query := `
+		SELECT id, user_id, suspended_by, reason, suspended_until, created_at, lifted_by, lifted_at
+		FROM user_suspensions
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suspensions := []Suspension{}
+	for rows.Next() {
+		var s Suspension
+		err := rows.Scan(&s.ID, &s.UserID, &s.SuspendedBy, &s.Reason, &s.Until, &s.CreatedAt, &s.LiftedBy, &s.LiftedAt)
+		if err != nil {
+			return nil, err
+		}
+		suspensions = append(suspensions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return suspensions, nil
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSuspensionsPostgreStore(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	users := &UsersPostgresStore{db: db}
+	store := &SuspensionsPostgreStore{db: db}
+	ctx := context.Background()
+
+	userID := uuid.New()
+	adminID := uuid.New()
+	_, err := db.Exec(`
+		INSERT INTO users (id, username, email, password, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		userID.String(), "testuser", "test@example.com", []byte("password"), true, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert test user: %v", err)
+	}
+
+	until := time.Now().Add(time.Hour)
+	if err := store.Suspend(ctx, &Suspension{UserID: userID, SuspendedBy: &adminID, Reason: "spam", Until: &until}); err != nil {
+		t.Fatalf("SuspensionsPostgreStore.Suspend() error = %v", err)
+	}
+	if err := store.Suspend(ctx, &Suspension{UserID: userID, SuspendedBy: &adminID, Reason: "abuse"}); err != nil {
+		t.Fatalf("SuspensionsPostgreStore.Suspend() error = %v", err)
+	}
+
+	user, err := users.GetUserByID(ctx, userID)
+	if err != nil {
+		t.Fatalf("UsersPostgresStore.GetUserByID() error = %v", err)
+	}
+	if !user.Suspended(time.Now()) || user.SuspendedUntil != nil || *user.SuspensionReason != "abuse" {
+		t.Fatalf("expected the user to be banned for abuse, got %v until %v", user.SuspensionReason, user.SuspendedUntil)
+	}
+
+	if err := store.Lift(ctx, userID, adminID); err != nil {
+		t.Fatalf("SuspensionsPostgreStore.Lift() error = %v", err)
+	}
+	if err := store.Lift(ctx, userID, adminID); err != ErrNotFound {
+		t.Errorf("SuspensionsPostgreStore.Lift() error = %v, want %v", err, ErrNotFound)
+	}
+
+	user, err = users.GetUserByID(ctx, userID)
+	if err != nil {
+		t.Fatalf("UsersPostgresStore.GetUserByID() error = %v", err)
+	}
+	if user.Suspended(time.Now()) || user.SuspensionReason != nil {
+		t.Errorf("expected the ban to be lifted, got %v", *user.SuspensionReason)
+	}
+
+	history, err := store.GetByUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("SuspensionsPostgreStore.GetByUser() error = %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 suspensions, got %d", len(history))
+	}
+	for _, s := range history {
+		if s.LiftedAt == nil || s.LiftedBy == nil || *s.LiftedBy != adminID || *s.SuspendedBy != adminID {
+			t.Errorf("expected a suspension by and lifted by the admin, got %+v", s)
+		}
+	}
+
+	if err := store.Suspend(ctx, &Suspension{UserID: uuid.New(), Reason: "spam"}); err != ErrNotFound {
+		t.Errorf("SuspensionsPostgreStore.Suspend() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestUser_Suspended(t *testing.T) {
+	now := time.Now()
+	reason := "spam"
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	tests := []struct {
+		name string
+		user User
+		want bool
+	}{
+		{name: "not suspended", user: User{}, want: false},
+		{name: "suspended", user: User{SuspensionReason: &reason, SuspendedUntil: &future}, want: true},
+		{name: "suspension ended", user: User{SuspensionReason: &reason, SuspendedUntil: &past}, want: false},
+		{name: "banned", user: User{SuspensionReason: &reason}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.user.Suspended(now); got != tt.want {
+				t.Errorf("User.Suspended() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
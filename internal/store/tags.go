@@ -0,0 +1,233 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ITine-Tech/blog/internal/slug"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrInvalidTag = errors.New("tags need at least one letter or digit and at most 100 characters")
+)
+
+// postTagsColumn selects the tag names of the post p, ordered by name.
+const postTagsColumn = `ARRAY(
+		SELECT t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+		WHERE pt.post_id = p.id ORDER BY t.name
+	)`
+
+// Tag groups posts. Tags are identified by their slug, so "Go" and "go" are the same tag.
+type Tag struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	// PostCount is the number of published posts with the tag.
+	PostCount int       `json:"post_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NormalizeTags turns tag names into tags with slugs. Names with the same slug
+// are collapsed into one tag with the first spelling.
+func NormalizeTags(names []string) ([]Tag, error) {
+	tags := make([]Tag, 0, len(names))
+	seen := map[string]bool{}
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		s := slug.Make(name)
+		if s == "" || len(name) > slug.MaxLength {
+			return nil, ErrInvalidTag
+		}
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		tags = append(tags, Tag{Name: name, Slug: s})
+	}
+	return tags, nil
+}
+
+type TagsPostgreStore struct {
+	db *sql.DB
+}
+
+// GetAll returns the tags of published posts, most used first.
+func (s *TagsPostgreStore) GetAll(ctx context.Context) ([]Tag, error) {
+	query := `
+		SELECT t.id, t.name, t.slug, t.created_at, COUNT(p.id)
+		FROM tags t
+		JOIN post_tags pt ON pt.tag_id = t.id
+		JOIN posts p ON p.id = pt.post_id AND p.status = 'published'
+		GROUP BY t.id
+		ORDER BY COUNT(p.id) DESC, t.slug
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.CreatedAt, &tag.PostCount); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (s *TagsPostgreStore) GetBySlug(ctx context.Context, slug string) (*Tag, error) {
+	query := `
+		SELECT t.id, t.name, t.slug, t.created_at,
+			(SELECT COUNT(*) FROM post_tags pt JOIN posts p ON p.id = pt.post_id WHERE pt.tag_id = t.id AND p.status = 'published')
+		FROM tags t
+		WHERE t.slug = $1
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tag := &Tag{}
+	err := s.db.QueryRowContext(ctx, query, slug).Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.CreatedAt, &tag.PostCount)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return tag, nil
+}
+
+// Rename saves the name and slug of the tag.
+// Returns ErrConflict if another tag already has the slug; such tags have to be merged.
+func (s *TagsPostgreStore) Rename(ctx context.Context, tag *Tag) error {
+	query := `
+		UPDATE tags SET name = $1, slug = $2 WHERE id = $3
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, tag.Name, tag.Slug, tag.ID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrConflict
+		}
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Merge moves all posts and followers of the tag sourceID to the tag targetID and deletes the source tag.
+func (s *TagsPostgreStore) Merge(ctx context.Context, sourceID, targetID int64) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		query := `
+			INSERT INTO post_tags (post_id, tag_id)
+			SELECT post_id, $2 FROM post_tags WHERE tag_id = $1
+			ON CONFLICT DO NOTHING
+			`
+		if _, err := tx.ExecContext(ctx, query, sourceID, targetID); err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO tag_follows (user_id, tag_id, created_at)
+			SELECT user_id, $2, created_at FROM tag_follows WHERE tag_id = $1
+			ON CONFLICT DO NOTHING
+			`
+		if _, err := tx.ExecContext(ctx, query, sourceID, targetID); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id = $1`, sourceID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// setPostTags replaces the tags of a post, creating tags that do not exist yet.
+// It returns the names of the tags as stored, which may be spelled differently than the given ones.
+func setPostTags(ctx context.Context, tx *sql.Tx, postID int64, tags []Tag) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	names := make([]string, len(tags))
+	slugs := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+		slugs[i] = tag.Slug
+	}
+
+	query := `
+		INSERT INTO tags (name, slug)
+		SELECT * FROM unnest($1::text[], $2::text[])
+		ON CONFLICT (slug) DO NOTHING
+		`
+	if _, err := tx.ExecContext(ctx, query, pq.Array(names), pq.Array(slugs)); err != nil {
+		return nil, err
+	}
+
+	query = `
+		DELETE FROM post_tags
+		WHERE post_id = $1 AND tag_id NOT IN (SELECT id FROM tags WHERE slug = ANY($2))
+		`
+	if _, err := tx.ExecContext(ctx, query, postID, pq.Array(slugs)); err != nil {
+		return nil, err
+	}
+
+	query = `
+		INSERT INTO post_tags (post_id, tag_id)
+		SELECT $1, id FROM tags WHERE slug = ANY($2)
+		ON CONFLICT DO NOTHING
+		`
+	if _, err := tx.ExecContext(ctx, query, postID, pq.Array(slugs)); err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT ARRAY(SELECT name FROM tags WHERE slug = ANY($1) ORDER BY name)
+		`
+	stored := []string{}
+	if err := tx.QueryRowContext(ctx, query, pq.Array(slugs)).Scan(pq.Array(&stored)); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
@@ -0,0 +1,30 @@
+package store
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{" Go ", "go", "Web Development", "GO"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tags) != 2 {
+		t.Fatalf("expected 2 tags, got %+v", tags)
+	}
+	if tags[0].Name != "Go" || tags[0].Slug != "go" {
+		t.Errorf("expected the first spelling to win, got %+v", tags[0])
+	}
+	if tags[1].Slug != "web-development" {
+		t.Errorf("expected slug web-development, got %q", tags[1].Slug)
+	}
+
+	for _, invalid := range []string{"", "!!!", strings.Repeat("a", 101)} {
+		if _, err := NormalizeTags([]string{"go", invalid}); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("expected ErrInvalidTag for %q, got %v", invalid, err)
+		}
+	}
+}
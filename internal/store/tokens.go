@@ -0,0 +1,242 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTokenReused = errors.New("refresh token has already been used")
+)
+
+type RefreshToken struct {
+	ID        int64      `json:"id"`
+	TokenHash string     `json:"-"`
+	UserID    uuid.UUID  `json:"user_id"`
+	FamilyID  uuid.UUID  `json:"family_id"`
+	Expiry    time.Time  `json:"expiry"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type TokensPostgreStore struct {
+	db *sql.DB
+}
+
+func (s *TokensPostgreStore) CreateRefreshToken(ctx context.Context, token *RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (token_hash, user_id, family_id, expiry)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		token.TokenHash,
+		token.UserID,
+		token.FamilyID,
+		token.Expiry,
+	).Scan(
+		&token.ID,
+		&token.CreatedAt,
+	)
+}
+
+// RotateRefreshToken exchanges the refresh token identified by oldHash for next.
+// The old token is marked as revoked and next inherits its user and family.
+//
+// If the old token has already been revoked, it is being reused (most likely by
+// someone who stole it), so the whole family is revoked and ErrTokenReused is returned.
+// Unknown or expired tokens return ErrNotFound.
+func (s *TokensPostgreStore) RotateRefreshToken(ctx context.Context, oldHash string, next *RefreshToken) error {
+	reused := false
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		current, err := s.getRefreshTokenForUpdate(ctx, tx, oldHash)
+		if err != nil {
+			return err
+		}
+
+		if current.RevokedAt != nil {
+			reused = true
+			return s.revokeFamily(ctx, tx, current.FamilyID)
+		}
+
+		if time.Now().After(current.Expiry) {
+			return ErrNotFound
+		}
+
+		if err := s.revokeRefreshToken(ctx, tx, current.ID); err != nil {
+			return err
+		}
+
+		next.UserID = current.UserID
+		next.FamilyID = current.FamilyID
+
+		query := `
+			INSERT INTO refresh_tokens (token_hash, user_id, family_id, expiry)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at
+			`
+
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		return tx.QueryRowContext(
+			ctx,
+			query,
+			next.TokenHash,
+			next.UserID,
+			next.FamilyID,
+			next.Expiry,
+		).Scan(
+			&next.ID,
+			&next.CreatedAt,
+		)
+	})
+	if err != nil {
+		return err
+	}
+
+	if reused {
+		return ErrTokenReused
+	}
+	return nil
+}
+
+// GetRefreshToken returns the refresh token identified by tokenHash, whether it is still valid or not.
+func (s *TokensPostgreStore) GetRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	query := `
+		SELECT id, token_hash, user_id, family_id, expiry, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return scanRefreshToken(s.db.QueryRowContext(ctx, query, tokenHash))
+}
+
+// RevokeRefreshTokenFamily revokes every refresh token that belongs to the same family
+// as the token identified by tokenHash. The token has to belong to userID.
+// Revoking a family that has already been revoked succeeds, so logging out twice is not an error.
+func (s *TokensPostgreStore) RevokeRefreshTokenFamily(ctx context.Context, tokenHash string, userID uuid.UUID) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		token, err := s.getRefreshTokenForUpdate(ctx, tx, tokenHash)
+		if err != nil {
+			return err
+		}
+		if token.UserID != userID {
+			return ErrNotFound
+		}
+
+		return s.revokeFamily(ctx, tx, token.FamilyID)
+	})
+}
+
+// RevokeAccessToken puts the access token with the given jti on the denylist until it expires.
+// Entries that have expired in the meantime are cleaned up on the way.
+func (s *TokensPostgreStore) RevokeAccessToken(ctx context.Context, jti uuid.UUID, expiry time.Time) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expiry < NOW()`); err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO revoked_tokens (jti, expiry)
+			VALUES ($1, $2)
+			ON CONFLICT (jti) DO NOTHING
+			`
+
+		_, err := tx.ExecContext(ctx, query, jti, expiry)
+		return err
+	})
+}
+
+func (s *TokensPostgreStore) IsAccessTokenRevoked(ctx context.Context, jti uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var revoked bool
+	if err := s.db.QueryRowContext(ctx, query, jti).Scan(&revoked); err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
+
+func (s *TokensPostgreStore) getRefreshTokenForUpdate(ctx context.Context, tx *sql.Tx, tokenHash string) (*RefreshToken, error) {
+	query := `
+		SELECT id, token_hash, user_id, family_id, expiry, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return scanRefreshToken(tx.QueryRowContext(ctx, query, tokenHash))
+}
+
+func scanRefreshToken(row *sql.Row) (*RefreshToken, error) {
+	token := &RefreshToken{}
+	err := row.Scan(
+		&token.ID,
+		&token.TokenHash,
+		&token.UserID,
+		&token.FamilyID,
+		&token.Expiry,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return token, nil
+}
+
+func (s *TokensPostgreStore) revokeRefreshToken(ctx context.Context, tx *sql.Tx, id int64) error {
+	query := `
+		UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, id)
+	return err
+}
+
+func (s *TokensPostgreStore) revokeFamily(ctx context.Context, tx *sql.Tx, familyID uuid.UUID) error {
+	query := `
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE family_id = $1 AND revoked_at IS NULL
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, familyID)
+	return err
+}
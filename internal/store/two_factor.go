@@ -0,0 +1,217 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrCodeReused = errors.New("the code has already been used")
+
+// TwoFactorEnrolment is the TOTP enrolment of a user.
+type TwoFactorEnrolment struct {
+	// Secret is encrypted by the API, the store never sees it in plain text.
+	Secret  string
+	Enabled bool
+	// LastCounter is the period of the last code that was accepted.
+	LastCounter int64
+}
+
+type TwoFactorPostgreStore struct {
+	db *sql.DB
+}
+
+// Get returns the enrolment of the user, confirmed or not.
+//
+// Returns ErrNotFound if the user has not started to enrol.
+func (s *TwoFactorPostgreStore) Get(ctx context.Context, userID uuid.UUID) (*TwoFactorEnrolment, error) {
+	query := `
+		SELECT totp_secret, totp_enabled, totp_last_counter
+		FROM users
+		WHERE id = $1
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var secret sql.NullString
+	tf := &TwoFactorEnrolment{}
+	err := s.db.QueryRowContext(ctx, query, userID).Scan(&secret, &tf.Enabled, &tf.LastCounter)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	if !secret.Valid {
+		return nil, ErrNotFound
+	}
+
+	tf.Secret = secret.String
+	return tf, nil
+}
+
+// SetSecret starts an enrolment with the encrypted secret. It replaces the secret of an enrolment
+// that has not been confirmed.
+//
+// Returns ErrConflict if two-factor authentication is already enabled.
+func (s *TwoFactorPostgreStore) SetSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	query := `
+		UPDATE users SET totp_secret = $1, totp_last_counter = 0
+		WHERE id = $2 AND totp_enabled = FALSE
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, secret, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// Enable confirms the enrolment of the user and replaces their recovery codes with the hashed ones.
+//
+// Returns ErrConflict if there is no enrolment to confirm.
+func (s *TwoFactorPostgreStore) Enable(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		query := `
+			UPDATE users SET totp_enabled = TRUE, updated_at = $1, version = version + 1
+			WHERE id = $2 AND totp_secret IS NOT NULL AND totp_enabled = FALSE
+			`
+		res, err := tx.ExecContext(ctx, query, time.Now(), userID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrConflict
+		}
+
+		return replaceRecoveryCodes(ctx, tx, userID, codeHashes)
+	})
+}
+
+// Disable turns two-factor authentication off for the user and removes the secret and recovery codes.
+//
+// Returns ErrNotFound if it is not enabled.
+func (s *TwoFactorPostgreStore) Disable(ctx context.Context, userID uuid.UUID) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		query := `
+			UPDATE users SET totp_secret = NULL, totp_enabled = FALSE, totp_last_counter = 0, updated_at = $1, version = version + 1
+			WHERE id = $2 AND totp_enabled = TRUE
+			`
+		res, err := tx.ExecContext(ctx, query, time.Now(), userID)
+		if err != nil {
+			return err
+		}
+		if err := userAffected(res); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID)
+		return err
+	})
+}
+
+// UseCode records that the code of the period counter has been accepted, so that neither it nor
+// the codes of earlier periods are accepted again.
+//
+// Returns ErrCodeReused if a code of the period or a later one has been accepted before.
+func (s *TwoFactorPostgreStore) UseCode(ctx context.Context, userID uuid.UUID, counter int64) error {
+	query := `
+		UPDATE users SET totp_last_counter = $1
+		WHERE id = $2 AND totp_last_counter < $1
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, counter, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrCodeReused
+	}
+	return nil
+}
+
+// UseRecoveryCode marks the recovery code with the hash as used.
+//
+// Returns ErrNotFound if the user has no such code or it has been used.
+func (s *TwoFactorPostgreStore) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	query := `
+		UPDATE recovery_codes SET used_at = $1
+		WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, time.Now(), userID, codeHash)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes replaces all recovery codes of the user, used or not, with the hashed ones.
+func (s *TwoFactorPostgreStore) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		return replaceRecoveryCodes(ctx, tx, userID, codeHashes)
+	})
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID uuid.UUID, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
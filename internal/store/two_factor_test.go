@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestTwoFactorPostgreStore(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	users := &UsersPostgresStore{db: db}
+	store := &TwoFactorPostgreStore{db: db}
+	ctx := context.Background()
+
+	userID := uuid.New()
+	_, err := db.Exec(`
+		INSERT INTO users (id, username, email, password, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		userID.String(), "testuser", "test@example.com", []byte("password"), true, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert test user: %v", err)
+	}
+
+	if _, err := store.Get(ctx, userID); err != ErrNotFound {
+		t.Errorf("TwoFactorPostgreStore.Get() error = %v, want %v", err, ErrNotFound)
+	}
+	if err := store.Enable(ctx, userID, nil); err != ErrConflict {
+		t.Errorf("TwoFactorPostgreStore.Enable() error = %v, want %v", err, ErrConflict)
+	}
+
+	if err := store.SetSecret(ctx, userID, "encrypted"); err != nil {
+		t.Fatalf("TwoFactorPostgreStore.SetSecret() error = %v", err)
+	}
+	if err := store.UseCode(ctx, userID, 5); err != nil {
+		t.Fatalf("TwoFactorPostgreStore.UseCode() error = %v", err)
+	}
+	if err := store.Enable(ctx, userID, []string{"a", "b"}); err != nil {
+		t.Fatalf("TwoFactorPostgreStore.Enable() error = %v", err)
+	}
+	if err := store.SetSecret(ctx, userID, "other"); err != ErrConflict {
+		t.Errorf("TwoFactorPostgreStore.SetSecret() error = %v, want %v", err, ErrConflict)
+	}
+
+	enrolment, err := store.Get(ctx, userID)
+	if err != nil {
+		t.Fatalf("TwoFactorPostgreStore.Get() error = %v", err)
+	}
+	if enrolment.Secret != "encrypted" || !enrolment.Enabled || enrolment.LastCounter != 5 {
+		t.Errorf("unexpected enrolment %+v", enrolment)
+	}
+
+	user, err := users.GetUserByID(ctx, userID)
+	if err != nil {
+		t.Fatalf("UsersPostgresStore.GetUserByID() error = %v", err)
+	}
+	if !user.TwoFactorEnabled {
+		t.Error("expected two-factor authentication to be enabled on the user")
+	}
+
+	for _, counter := range []int64{5, 4} {
+		if err := store.UseCode(ctx, userID, counter); err != ErrCodeReused {
+			t.Errorf("TwoFactorPostgreStore.UseCode(%d) error = %v, want %v", counter, err, ErrCodeReused)
+		}
+	}
+
+	if err := store.UseRecoveryCode(ctx, userID, "a"); err != nil {
+		t.Fatalf("TwoFactorPostgreStore.UseRecoveryCode() error = %v", err)
+	}
+	if err := store.UseRecoveryCode(ctx, userID, "a"); err != ErrNotFound {
+		t.Errorf("TwoFactorPostgreStore.UseRecoveryCode() error = %v, want %v", err, ErrNotFound)
+	}
+
+	if err := store.ReplaceRecoveryCodes(ctx, userID, []string{"a", "c"}); err != nil {
+		t.Fatalf("TwoFactorPostgreStore.ReplaceRecoveryCodes() error = %v", err)
+	}
+	if err := store.UseRecoveryCode(ctx, userID, "b"); err != ErrNotFound {
+		t.Errorf("TwoFactorPostgreStore.UseRecoveryCode() error = %v, want %v", err, ErrNotFound)
+	}
+	if err := store.UseRecoveryCode(ctx, userID, "a"); err != nil {
+		t.Errorf("TwoFactorPostgreStore.UseRecoveryCode() error = %v", err)
+	}
+
+	if err := store.Disable(ctx, userID); err != nil {
+		t.Fatalf("TwoFactorPostgreStore.Disable() error = %v", err)
+	}
+	if err := store.Disable(ctx, userID); err != ErrNotFound {
+		t.Errorf("TwoFactorPostgreStore.Disable() error = %v, want %v", err, ErrNotFound)
+	}
+	if _, err := store.Get(ctx, userID); err != ErrNotFound {
+		t.Errorf("TwoFactorPostgreStore.Get() error = %v, want %v", err, ErrNotFound)
+	}
+	if err := store.UseRecoveryCode(ctx, userID, "c"); err != ErrNotFound {
+		t.Errorf("TwoFactorPostgreStore.UseRecoveryCode() error = %v, want %v", err, ErrNotFound)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -18,6 +20,11 @@ var (
 	ErrDuplicateUsername = errors.New("a user with this username already exists")
 )
 
+const (
+	DefaultUserLimit = 20
+	MaxUserLimit     = 100
+)
+
 type User struct {
 	ID        uuid.UUID `json:"id"`
 	Username  string    `json:"username"`
@@ -28,6 +35,39 @@ type User struct {
 	IsActive  bool      `json:"is_active"`
 	RoleID    int64     `json:"role_id"`
 	Role      Role      `json:"role"`
+	Version   int       `json:"version"`
+	// PasswordChangedAt is set when the password is reset. Tokens issued before are no longer valid.
+	PasswordChangedAt *time.Time `json:"-"`
+	// Follows is only set on profiles.
+	Follows *FollowCounts `json:"follows,omitempty"`
+	// SuspensionReason is set while the user is suspended, until SuspendedUntil. A suspension without end is a ban.
+	// Only the user and those managing users see it.
+	SuspendedUntil   *time.Time `json:"-"`
+	SuspensionReason *string    `json:"-"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+}
+
+// Suspended reports whether the user is suspended or banned at now.
+func (u *User) Suspended(now time.Time) bool {
+	return u.SuspensionReason != nil && (u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil))
+}
+
+// clearExpiredSuspension unsets a suspension that has ended, so that it does not show up on the user anymore.
+func (u *User) clearExpiredSuspension(now time.Time) {
+	if !u.Suspended(now) {
+		u.SuspendedUntil = nil
+		u.SuspensionReason = nil
+	}
+}
+
+// UserQuery describes a page of users, newest first. Filters that are not set match every user.
+type UserQuery struct {
+	Limit        int
+	Offset       int
+	Role         string
+	Active       *bool
+	CreatedSince *time.Time
+	CreatedUntil *time.Time
 }
 
 type password struct {
@@ -119,6 +159,17 @@ func (s *UsersPostgresStore) CreateAndInvite(ctx context.Context, user *User, to
 	})
 }
 
+// Reinvite replaces the pending invitations of a user with a new one.
+func (s *UsersPostgresStore) Reinvite(ctx context.Context, userID uuid.UUID, token string, invitationExp time.Duration) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.deleteUserInvitation(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		return s.createUserInvitation(ctx, tx, token, invitationExp, userID)
+	})
+}
+
 // Activate activates a user account using an invitation token.
 // It retrieves the user from the invitation token, sets the user's status to active,
 // and deletes the corresponding invitation record.
@@ -147,41 +198,212 @@ func (s *UsersPostgresStore) Activate(ctx context.Context, token string) error {
 	})
 }
 
-func (s *UsersPostgresStore) GetAllUsers(ctx context.Context) ([]*User, error) {
-	rows, err := s.db.Query(`SELECT * FROM users`)
+// CreatePasswordReset stores a password reset token for the active user with the given email.
+// Outstanding reset tokens of that user are replaced, so only the most recent one can be used.
+//
+// ctx: The context for the operation.
+// email: The email address the user asked to reset the password for.
+// token: The hashed reset token.
+// exp: The expiration duration of the reset token.
+//
+// Returns the user the token belongs to, or ErrNotFound if there is no active user with this email.
+func (s *UsersPostgresStore) CreatePasswordReset(ctx context.Context, email, token string, exp time.Duration) (*User, error) {
+	user := &User{}
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			SELECT id, username, email, created_at, is_active
+			FROM users
+			WHERE email = $1 AND is_active = true
+			`
+
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		err := tx.QueryRowContext(ctx, query, email).Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.CreatedAt,
+			&user.IsActive,
+		)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrNotFound
+			default:
+				return err
+			}
+		}
+
+		if err := s.deletePasswordResets(ctx, tx, user.ID); err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO password_resets (token, user_id, expiry)
+			VALUES ($1, $2, $3)
+			`
+
+		_, err = tx.ExecContext(ctx, query, token, user.ID, time.Now().Add(exp))
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return user, nil
+}
+
+// ResetPassword sets a new password for the user the reset token belongs to.
+// The token can only be used once. All outstanding reset tokens and refresh tokens of
+// the user are invalidated, and access tokens issued before the reset are rejected.
+//
+// ctx: The context for the operation.
+// token: The hashed reset token.
+// newPassword: The new plain text password.
+//
+// Returns ErrNotFound if the token is unknown, already used or expired.
+func (s *UsersPostgresStore) ResetPassword(ctx context.Context, token, newPassword string) error {
+	var pw password
+	if err := pw.Set(newPassword); err != nil {
+		return err
+	}
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		now := time.Now()
+
+		query := `
+			DELETE FROM password_resets
+			WHERE token = $1 AND expiry > $2
+			RETURNING user_id
+			`
+
+		var userID uuid.UUID
+		err := tx.QueryRowContext(ctx, query, token, now).Scan(&userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrNotFound
+			default:
+				return err
+			}
+		}
+
+		query = `
+			UPDATE users SET password = $1, password_changed_at = $2, updated_at = $2
+			WHERE id = $3
+			`
+		if _, err := tx.ExecContext(ctx, query, pw.hash, now, userID); err != nil {
+			return err
+		}
+
+		if err := s.deletePasswordResets(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		query = `
+			UPDATE refresh_tokens SET revoked_at = $1
+			WHERE user_id = $2 AND revoked_at IS NULL
+			`
+		_, err = tx.ExecContext(ctx, query, now, userID)
+		return err
+	})
+}
 
-	var result []*User
+// GetAllUsers returns a page of the users matching uq, newest first.
+func (s *UsersPostgresStore) GetAllUsers(ctx context.Context, uq UserQuery) ([]*User, error) {
+	query, args := buildUserQuery(uq)
 
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*User{}
 	for rows.Next() {
 		user := &User{}
 		err := rows.Scan(
 			&user.ID,
 			&user.Username,
 			&user.Email,
-			&user.Password.hash,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 			&user.IsActive,
-			&user.RoleID,
+			&user.Version,
+			&user.Role.ID,
+			&user.Role.Name,
+			&user.Role.Level,
+			&user.Role.Description,
+			&user.SuspendedUntil,
+			&user.SuspensionReason,
 		)
-
 		if err != nil {
 			return nil, err
 		}
+		user.RoleID = int64(user.Role.ID)
+		user.clearExpiredSuspension(time.Now())
+
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// buildUserQuery turns uq into a SQL query and its arguments.
+func buildUserQuery(uq UserQuery) (string, []any) {
+	var (
+		conditions []string
+		args       []any
+	)
+
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if uq.Role != "" {
+		conditions = append(conditions, "r.name = "+arg(uq.Role))
+	}
+
+	if uq.Active != nil {
+		conditions = append(conditions, "u.is_active = "+arg(*uq.Active))
+	}
+
+	if uq.CreatedSince != nil {
+		conditions = append(conditions, "u.created_at >= "+arg(*uq.CreatedSince))
+	}
+
+	if uq.CreatedUntil != nil {
+		conditions = append(conditions, "u.created_at < "+arg(*uq.CreatedUntil))
+	}
+
+	query := `
+	SELECT u.id, u.username, u.email, u.created_at, u.updated_at, u.is_active, u.version, r.id, r.name, r.level, r.description, u.suspended_until, u.suspension_reason
+	FROM users u
+	JOIN roles r ON r.id = u.role_id
+	`
 
-		result = append(result, user)
+	if len(conditions) > 0 {
+		query += "WHERE " + strings.Join(conditions, " AND ") + "\n"
 	}
 
-	return result, nil
+	query += fmt.Sprintf("ORDER BY u.created_at DESC, u.id\nLIMIT %s OFFSET %s", arg(uq.Limit), arg(uq.Offset))
+
+	return query, args
 }
 
 func (s *UsersPostgresStore) GetUserByID(ctx context.Context, id uuid.UUID) (*User, error) {
 	query := `
-		SELECT users.id, username, email, password, created_at, updated_at, is_active, roles.id, roles.name, roles.level, roles.description
+		SELECT users.id, username, email, password, created_at, updated_at, is_active, version, password_changed_at, roles.id, roles.name, roles.level, roles.description, roles.requires_two_factor, suspended_until, suspension_reason, totp_enabled
 		FROM users
 		JOIN roles ON (users.role_id = roles.id)
 		WHERE users.id = $1
@@ -203,10 +425,16 @@ func (s *UsersPostgresStore) GetUserByID(ctx context.Context, id uuid.UUID) (*Us
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&user.IsActive,
+		&user.Version,
+		&user.PasswordChangedAt,
 		&user.Role.ID,
 		&user.Role.Name,
 		&user.Role.Level,
 		&user.Role.Description,
+		&user.Role.RequiresTwoFactor,
+		&user.SuspendedUntil,
+		&user.SuspensionReason,
+		&user.TwoFactorEnabled,
 	)
 	if err != nil {
 		switch {
@@ -216,12 +444,13 @@ func (s *UsersPostgresStore) GetUserByID(ctx context.Context, id uuid.UUID) (*Us
 			return nil, err
 		}
 	}
+	user.clearExpiredSuspension(time.Now())
 	return &user, nil
 }
 
 func (s *UsersPostgresStore) GetUserByUsername(ctx context.Context, username string) (*User, error) {
 	query := `
-		SELECT id, username, email, password, created_at, updated_at 
+		SELECT id, username, email, password, created_at, updated_at, suspended_until, suspension_reason, totp_enabled
 		FROM users
 		WHERE username = $1 AND is_active = true
 		`
@@ -236,6 +465,9 @@ func (s *UsersPostgresStore) GetUserByUsername(ctx context.Context, username str
 		&user.Password.hash,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.SuspendedUntil,
+		&user.SuspensionReason,
+		&user.TwoFactorEnabled,
 	)
 	if err != nil {
 		switch err {
@@ -245,13 +477,19 @@ func (s *UsersPostgresStore) GetUserByUsername(ctx context.Context, username str
 			return nil, err
 		}
 	}
+	user.clearExpiredSuspension(time.Now())
 	return user, nil
 }
+// UpdateUser saves the username and email if the user is still at user.Version and increments the version.
+// Role and activation are changed with SetRole and SetActive.
+//
+// Returns ErrConflict if the user has been changed since user.Version was read.
 func (s *UsersPostgresStore) UpdateUser(ctx context.Context, user *User) error {
 	query := `
 		UPDATE users
-		SET username = $1, email = $2, updated_at = $4
-		WHERE id = $3
+		SET username = $1, email = $2, updated_at = $3, version = version + 1
+		WHERE id = $4 AND version = $5
+		RETURNING version, updated_at
 		`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -263,13 +501,18 @@ func (s *UsersPostgresStore) UpdateUser(ctx context.Context, user *User) error {
 		query,
 		user.Username,
 		user.Email,
-		user.ID,
 		now,
-	).Scan()
+		user.ID,
+		user.Version,
+	).Scan(&user.Version, &user.UpdatedAt)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			return ErrNotFound
+			return s.userVersionError(ctx, user.ID)
+		case strings.Contains(err.Error(), "users_email_key"):
+			return ErrDuplicateEmail
+		case strings.Contains(err.Error(), "users_username_key"):
+			return ErrDuplicateUsername
 		default:
 			return err
 		}
@@ -277,6 +520,83 @@ func (s *UsersPostgresStore) UpdateUser(ctx context.Context, user *User) error {
 	return nil
 }
 
+// userVersionError tells apart why a versioned write to a user did not affect any row.
+func (s *UsersPostgresStore) userVersionError(ctx context.Context, id uuid.UUID) error {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrConflict
+	}
+	return ErrNotFound
+}
+
+// SetRole gives the user the role and increments the version of the user.
+func (s *UsersPostgresStore) SetRole(ctx context.Context, id uuid.UUID, roleID int) error {
+	query := `
+		UPDATE users SET role_id = $1, updated_at = $2, version = version + 1
+		WHERE id = $3
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, roleID, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	return userAffected(res)
+}
+
+// SetActive activates or deactivates the account of the user and increments the version of the user.
+// Deactivating an account also revokes its refresh tokens, so its sessions end when their access tokens expire.
+func (s *UsersPostgresStore) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		now := time.Now()
+
+		query := `
+			UPDATE users SET is_active = $1, updated_at = $2, version = version + 1
+			WHERE id = $3
+			`
+
+		res, err := tx.ExecContext(ctx, query, active, now, id)
+		if err != nil {
+			return err
+		}
+		if err := userAffected(res); err != nil {
+			return err
+		}
+		if active {
+			return nil
+		}
+
+		query = `
+			UPDATE refresh_tokens SET revoked_at = $1
+			WHERE user_id = $2 AND revoked_at IS NULL
+			`
+		_, err = tx.ExecContext(ctx, query, now, id)
+		return err
+	})
+}
+
+// userAffected returns ErrNotFound if a write to a user did not affect any row.
+func userAffected(res sql.Result) error {
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
 func (s *UsersPostgresStore) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	query := `
 		DELETE FROM users WHERE id = $1
@@ -285,6 +605,9 @@ func (s *UsersPostgresStore) DeleteUser(ctx context.Context, id uuid.UUID) error
 	defer cancel()
 
 	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
 
 	rows, err := res.RowsAffected()
 	if err != nil {
@@ -347,7 +670,7 @@ func (s *UsersPostgresStore) getUserFromInvitation(ctx context.Context, tx *sql.
 // Updates the user to being active after e-mail
 func (s *UsersPostgresStore) update(ctx context.Context, tx *sql.Tx, user *User) error {
 	query := `
-		UPDATE users SET username = $1, email = $2, is_active = $3
+		UPDATE users SET username = $1, email = $2, is_active = $3, version = version + 1
 		WHERE id = $4
 		`
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -375,3 +698,15 @@ func (s *UsersPostgresStore) deleteUserInvitation(ctx context.Context, tx *sql.T
 	}
 	return nil
 }
+
+func (s *UsersPostgresStore) deletePasswordResets(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error {
+	query := `
+		DELETE FROM password_resets WHERE user_id = $1
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, userID)
+	return err
+}
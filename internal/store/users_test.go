@@ -29,7 +29,41 @@ func setupTestDB(t *testing.T) *sql.DB {
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			is_active BOOLEAN NOT NULL DEFAULT FALSE,
-			role_id INTEGER DEFAULT 1
+			role_id INTEGER DEFAULT 1,
+			version INTEGER NOT NULL DEFAULT 1,
+			password_changed_at TIMESTAMP,
+			suspended_until TIMESTAMP,
+			suspension_reason TEXT,
+			totp_secret TEXT,
+			totp_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+			totp_last_counter INTEGER NOT NULL DEFAULT 0
+		)`,
+		`CREATE TABLE roles (
+			id INTEGER PRIMARY KEY,
+			name TEXT UNIQUE NOT NULL,
+			level INTEGER NOT NULL DEFAULT 0,
+			description TEXT,
+			requires_two_factor BOOLEAN NOT NULL DEFAULT FALSE
+		)`,
+		`INSERT INTO roles (id, name, level, description) VALUES
+			(1, 'user', 1, 'A user'), (2, 'moderator', 2, 'A moderator'), (3, 'admin', 3, 'An admin')`,
+		`CREATE TABLE user_suspensions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			suspended_by TEXT,
+			reason TEXT NOT NULL,
+			suspended_until TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			lifted_by TEXT,
+			lifted_at TIMESTAMP
+		)`,
+		`CREATE TABLE recovery_codes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			code_hash TEXT NOT NULL,
+			used_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (user_id, code_hash)
 		)`,
 		`CREATE TABLE user_invitations (
 			token TEXT PRIMARY KEY,
@@ -37,6 +71,50 @@ func setupTestDB(t *testing.T) *sql.DB {
 			expiry TIMESTAMP NOT NULL,
 			FOREIGN KEY (id) REFERENCES users(id)
 		)`,
+		`CREATE TABLE password_resets (
+			token TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			expiry TIMESTAMP NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id)
+		)`,
+		`CREATE TABLE posts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			title TEXT NOT NULL DEFAULT '',
+			text TEXT NOT NULL DEFAULT '',
+			user_id TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'draft',
+			published_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			version INTEGER NOT NULL DEFAULT 1
+		)`,
+		`CREATE TABLE comments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			post_id INTEGER NOT NULL,
+			user_id TEXT NOT NULL,
+			content TEXT NOT NULL DEFAULT '',
+			fingerprint TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE post_revisions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			post_id INTEGER NOT NULL,
+			version INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			text TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			UNIQUE (post_id, version)
+		)`,
+		`CREATE TABLE refresh_tokens (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			token_hash TEXT NOT NULL UNIQUE,
+			user_id TEXT NOT NULL,
+			family_id TEXT NOT NULL,
+			expiry TIMESTAMP NOT NULL,
+			revoked_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
 	}
 
 	for _, query := range queries {
@@ -134,3 +212,197 @@ func TestUsersPostgresStore_Activate(t *testing.T) {
 		})
 	}
 }
+
+func TestUsersPostgresStore_ResetPassword(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &UsersPostgresStore{db: db}
+	ctx := context.Background()
+
+	userID := uuid.New()
+	_, err := db.Exec(`
+		INSERT INTO users (id, username, email, password, is_active, created_at, updated_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		userID.String(), "testuser", "test@example.com", []byte("password"), true, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert test user: %v", err)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO refresh_tokens (token_hash, user_id, family_id, expiry) 
+		VALUES (?, ?, ?, ?)`,
+		"refresh-hash", userID.String(), uuid.New().String(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to insert test refresh token: %v", err)
+	}
+
+	if _, err := store.CreatePasswordReset(ctx, "unknown@example.com", "unknown-token", time.Hour); err != ErrNotFound {
+		t.Errorf("UsersPostgresStore.CreatePasswordReset() error = %v, want %v", err, ErrNotFound)
+	}
+
+	if _, err := store.CreatePasswordReset(ctx, "test@example.com", "old-token", time.Hour); err != nil {
+		t.Fatalf("UsersPostgresStore.CreatePasswordReset() error = %v", err)
+	}
+
+	user, err := store.CreatePasswordReset(ctx, "test@example.com", "reset-token", time.Hour)
+	if err != nil {
+		t.Fatalf("UsersPostgresStore.CreatePasswordReset() error = %v", err)
+	}
+	if user.ID != userID {
+		t.Errorf("UsersPostgresStore.CreatePasswordReset() user = %v, want %v", user.ID, userID)
+	}
+
+	if err := store.ResetPassword(ctx, "old-token", "new-password"); err != ErrNotFound {
+		t.Errorf("replaced token should not be usable, error = %v", err)
+	}
+
+	if err := store.ResetPassword(ctx, "reset-token", "new-password"); err != nil {
+		t.Fatalf("UsersPostgresStore.ResetPassword() error = %v", err)
+	}
+
+	if err := store.ResetPassword(ctx, "reset-token", "other-password"); err != ErrNotFound {
+		t.Errorf("token should only be usable once, error = %v", err)
+	}
+
+	var hash []byte
+	var changedAt sql.NullTime
+	err = db.QueryRow("SELECT password, password_changed_at FROM users WHERE id = ?", userID.String()).Scan(&hash, &changedAt)
+	if err != nil {
+		t.Fatalf("failed to query user: %v", err)
+	}
+	pw := password{hash: hash}
+	if err := pw.Compare("new-password"); err != nil {
+		t.Error("password should be changed after reset")
+	}
+	if !changedAt.Valid {
+		t.Error("password_changed_at should be set after reset")
+	}
+
+	var active int
+	err = db.QueryRow("SELECT COUNT(*) FROM refresh_tokens WHERE user_id = ? AND revoked_at IS NULL", userID.String()).Scan(&active)
+	if err != nil {
+		t.Fatalf("failed to query refresh tokens: %v", err)
+	}
+	if active != 0 {
+		t.Error("refresh tokens should be revoked after reset")
+	}
+}
+
+func TestUsersPostgresStore_UpdateUser(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &UsersPostgresStore{db: db}
+	ctx := context.Background()
+
+	userID := uuid.New()
+	_, err := db.Exec(`
+		INSERT INTO users (id, username, email, password, is_active, created_at, updated_at) 
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		userID.String(), "testuser", "test@example.com", []byte("password"), true, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert test user: %v", err)
+	}
+
+	user := &User{ID: userID, Username: "renamed", Email: "test@example.com", Version: 1, Role: Role{ID: 3}, IsActive: false}
+	if err := store.UpdateUser(ctx, user); err != nil {
+		t.Fatalf("UsersPostgresStore.UpdateUser() error = %v", err)
+	}
+	if user.Version != 2 {
+		t.Errorf("expected version 2 after update, got %d", user.Version)
+	}
+
+	var (
+		roleID   int
+		isActive bool
+	)
+	if err := db.QueryRow(`SELECT role_id, is_active FROM users WHERE id = ?`, userID.String()).Scan(&roleID, &isActive); err != nil {
+		t.Fatalf("failed to query user: %v", err)
+	}
+	if roleID != 1 || !isActive {
+		t.Errorf("expected the role and activation to stay, got role %d and active %v", roleID, isActive)
+	}
+
+	stale := &User{ID: userID, Username: "stale", Email: "test@example.com", Version: 1}
+	if err := store.UpdateUser(ctx, stale); err != ErrConflict {
+		t.Errorf("UsersPostgresStore.UpdateUser() error = %v, want %v", err, ErrConflict)
+	}
+
+	missing := &User{ID: uuid.New(), Username: "missing", Email: "missing@example.com", Version: 1}
+	if err := store.UpdateUser(ctx, missing); err != ErrNotFound {
+		t.Errorf("UsersPostgresStore.UpdateUser() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestUsersPostgresStore_AdminUserManagement(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &UsersPostgresStore{db: db}
+	ctx := context.Background()
+
+	active := uuid.New()
+	pending := uuid.New()
+	for i, id := range []uuid.UUID{active, pending} {
+		_, err := db.Exec(`
+			INSERT INTO users (id, username, email, password, is_active, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			id.String(), id.String(), id.String()+"@example.com", []byte("password"), id == active, time.Now().Add(time.Duration(i)*time.Minute), time.Now())
+		if err != nil {
+			t.Fatalf("failed to insert test user: %v", err)
+		}
+	}
+	_, err := db.Exec(`INSERT INTO refresh_tokens (token_hash, user_id, family_id, expiry) VALUES (?, ?, ?, ?)`,
+		"hash", active.String(), uuid.NewString(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to insert refresh token: %v", err)
+	}
+
+	users, err := store.GetAllUsers(ctx, UserQuery{Limit: 10})
+	if err != nil {
+		t.Fatalf("UsersPostgresStore.GetAllUsers() error = %v", err)
+	}
+	if len(users) != 2 || users[0].ID != pending || users[0].Role.Name != "user" {
+		t.Fatalf("expected the newest user first with their role, got %+v", users)
+	}
+
+	if err := store.SetRole(ctx, active, 3); err != nil {
+		t.Fatalf("UsersPostgresStore.SetRole() error = %v", err)
+	}
+	if err := store.SetActive(ctx, active, false); err != nil {
+		t.Fatalf("UsersPostgresStore.SetActive() error = %v", err)
+	}
+
+	inactive := false
+	users, err = store.GetAllUsers(ctx, UserQuery{Limit: 10, Role: "admin", Active: &inactive})
+	if err != nil {
+		t.Fatalf("UsersPostgresStore.GetAllUsers() error = %v", err)
+	}
+	if len(users) != 1 || users[0].ID != active || users[0].Version != 3 {
+		t.Fatalf("expected the deactivated admin at version 3, got %+v", users)
+	}
+
+	var revoked int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM refresh_tokens WHERE revoked_at IS NOT NULL`).Scan(&revoked); err != nil {
+		t.Fatalf("failed to query refresh tokens: %v", err)
+	}
+	if revoked != 1 {
+		t.Errorf("expected the refresh token to be revoked, got %d revoked", revoked)
+	}
+
+	users, err = store.GetAllUsers(ctx, UserQuery{Limit: 10, Offset: 1})
+	if err != nil {
+		t.Fatalf("UsersPostgresStore.GetAllUsers() error = %v", err)
+	}
+	if len(users) != 1 || users[0].ID != active {
+		t.Errorf("expected the second page to hold the oldest user, got %+v", users)
+	}
+
+	if err := store.SetRole(ctx, uuid.New(), 1); err != ErrNotFound {
+		t.Errorf("UsersPostgresStore.SetRole() error = %v, want %v", err, ErrNotFound)
+	}
+	if err := store.SetActive(ctx, uuid.New(), true); err != ErrNotFound {
+		t.Errorf("UsersPostgresStore.SetActive() error = %v, want %v", err, ErrNotFound)
+	}
+}
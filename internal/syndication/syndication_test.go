@@ -0,0 +1,152 @@
+package syndication
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() Feed {
+	published := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	return Feed{
+		Title:       "Blog",
+		Description: "Posts about Go",
+		Link:        "https://blog.example",
+		FeedURL:     "https://api.blog.example/feed.rss",
+		Author:      "Blog",
+		Updated:     published.Add(time.Hour),
+		Items: []Item{
+			{
+				ID:        "https://api.blog.example/feed/1",
+				Title:     "Generics <in> practice",
+				Link:      "https://blog.example/posts/generics-in-practice",
+				Content:   "Type parameters & constraints",
+				Author:    "gopher",
+				Tags:      []string{"go", "generics"},
+				Published: published,
+				Updated:   published.Add(time.Hour),
+			},
+		},
+	}
+}
+
+func TestWriteRSS(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteRSS(&buf, testFeed()); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Channel struct {
+			Title         string `xml:"title"`
+			LastBuildDate string `xml:"lastBuildDate"`
+			Items         []struct {
+				Title      string   `xml:"title"`
+				GUID       string   `xml:"guid"`
+				PubDate    string   `xml:"pubDate"`
+				Categories []string `xml:"category"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+
+	if len(doc.Channel.Items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(doc.Channel.Items))
+	}
+	item := doc.Channel.Items[0]
+	if item.Title != "Generics <in> practice" || item.PubDate != "Wed, 01 May 2024 12:00:00 +0000" || len(item.Categories) != 2 {
+		t.Errorf("unexpected item %+v", item)
+	}
+	if doc.Channel.LastBuildDate != "Wed, 01 May 2024 13:00:00 +0000" {
+		t.Errorf("unexpected lastBuildDate %q", doc.Channel.LastBuildDate)
+	}
+}
+
+func TestWriteAtom(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteAtom(&buf, testFeed()); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Updated string `xml:"updated"`
+			Author  string `xml:"author>name"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid Atom: %v\n%s", err, buf.String())
+	}
+
+	if doc.Updated != "2024-05-01T13:00:00Z" {
+		t.Errorf("unexpected feed updated %q", doc.Updated)
+	}
+	if len(doc.Entries) != 1 || doc.Entries[0].Updated != "2024-05-01T13:00:00Z" || doc.Entries[0].Author != "gopher" {
+		t.Errorf("unexpected entries %+v", doc.Entries)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, testFeed()); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc["version"] != "https://jsonfeed.org/version/1.1" {
+		t.Errorf("unexpected version %v", doc["version"])
+	}
+	items, _ := doc["items"].([]any)
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %v", doc["items"])
+	}
+	item := items[0].(map[string]any)
+	if item["date_modified"] != "2024-05-01T13:00:00Z" || item["content_text"] != "Type parameters & constraints" {
+		t.Errorf("unexpected item %v", item)
+	}
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	if err := Write(&strings.Builder{}, "csv", testFeed()); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestWrite_HTMLContent(t *testing.T) {
+	feed := testFeed()
+	feed.Items[0].HTML = "<p>Type parameters &amp; constraints</p>"
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: FormatRSS, want: "<description>&lt;p&gt;Type parameters &amp;amp; constraints&lt;/p&gt;</description>"},
+		{format: FormatAtom, want: `<content type="html">&lt;p&gt;Type parameters &amp;amp; constraints&lt;/p&gt;</content>`},
+		{format: FormatJSON, want: `"content_html": "<p>Type parameters &amp; constraints</p>"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.format, feed); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("expected %s in\n%s", tt.want, buf.String())
+			}
+		})
+	}
+}
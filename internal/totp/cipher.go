@@ -0,0 +1,67 @@
+package totp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the size of the key secrets are encrypted with, in bytes.
+const KeySize = 32
+
+// Cipher encrypts secrets for storage with AES-256-GCM.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("the key must be %d bytes long, not %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt returns the secret encrypted with a random nonce, base64 encoded. The associated data, e.g. the
+// ID of the user the secret belongs to, is authenticated but not stored, so the encrypted secret cannot
+// be decrypted with different associated data, e.g. after being copied to another user.
+func (c *Cipher) Encrypt(secret string, associatedData []byte) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(secret), associatedData)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the secret encrypted by Encrypt with the same associated data.
+func (c *Cipher) Decrypt(encrypted string, associatedData []byte) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	size := c.aead.NonceSize()
+	if len(sealed) < size {
+		return "", errors.New("the encrypted secret is too short")
+	}
+
+	secret, err := c.aead.Open(nil, sealed[:size], sealed[size:], associatedData)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
@@ -0,0 +1,55 @@
+package totp
+
+import (
+	"crypto/rand"
+	"strings"
+)
+
+// RecoveryCodes is the number of recovery codes a user gets.
+const RecoveryCodes = 10
+
+// recoveryAlphabet leaves out letters that are easily mistaken for others, like l and 1.
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// GenerateRecoveryCodes returns RecoveryCodes random codes of the form xxxxx-xxxxx.
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodes)
+	for i := range codes {
+		code, err := randomString(10)
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+	return codes, nil
+}
+
+// randomString returns n random letters of recoveryAlphabet.
+func randomString(n int) (string, error) {
+	// bytes from limit on would favour the first letters of the alphabet
+	limit := 256 - 256%len(recoveryAlphabet)
+
+	s := make([]byte, 0, n)
+	b := make([]byte, 1)
+	for len(s) < n {
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		if int(b[0]) < limit {
+			s = append(s, recoveryAlphabet[int(b[0])%len(recoveryAlphabet)])
+		}
+	}
+	return string(s), nil
+}
+
+// NormalizeRecoveryCode removes the separator, spaces and capitalization users may have typed,
+// so that codes can be compared and hashed in one form.
+func NormalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', ' ':
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+}
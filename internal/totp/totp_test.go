@@ -0,0 +1,164 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 secret of the test vectors in appendix B of RFC 6238.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// the last 6 digits of the 8 digit codes of RFC 6238
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Counter(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Code() at %d = %q, want %q", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	counter := Counter(now)
+
+	tests := []struct {
+		name   string
+		code   string
+		wantOK bool
+		want   int64
+	}{
+		{name: "current code", code: "050471", wantOK: true, want: counter},
+		{name: "previous code", code: "081804", wantOK: true, want: counter - 1},
+		{name: "wrong code", code: "123456", wantOK: false},
+		{name: "too short", code: "05047", wantOK: false},
+		{name: "empty", code: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Validate(rfcSecret, tt.code, now)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("Validate() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	if _, ok := Validate(rfcSecret, "081804", now.Add(2*Period)); ok {
+		t.Error("expected a code older than the skew to be refused")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret == other {
+		t.Error("expected random secrets")
+	}
+
+	code, err := Code(secret, Counter(time.Now()))
+	if err != nil || len(code) != Digits {
+		t.Errorf("expected a %d digit code, got %q, %v", Digits, code, err)
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(URI("My Blog", "gopher", "JBSWY3DPEHPK3PXP"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/My Blog:gopher" {
+		t.Errorf("unexpected URI %s", uri)
+	}
+	if q := uri.Query(); q.Get("secret") != "JBSWY3DPEHPK3PXP" || q.Get("issuer") != "My Blog" || q.Get("digits") != "6" {
+		t.Errorf("unexpected parameters %s", uri.RawQuery)
+	}
+}
+
+func TestCipher(t *testing.T) {
+	c, err := NewCipher([]byte(strings.Repeat("k", KeySize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := c.Encrypt("JBSWY3DPEHPK3PXP", []byte("user"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(encrypted, "JBSWY3DPEHPK3PXP") {
+		t.Error("expected the secret to be encrypted")
+	}
+
+	secret, err := c.Decrypt(encrypted, []byte("user"))
+	if err != nil || secret != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("Decrypt() = %q, %v", secret, err)
+	}
+
+	if _, err := c.Decrypt(encrypted, []byte("other user")); err == nil {
+		t.Error("expected decryption with other associated data to fail")
+	}
+
+	other, err := NewCipher([]byte(strings.Repeat("o", KeySize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Decrypt(encrypted, []byte("user")); err == nil {
+		t.Error("expected decryption with another key to fail")
+	}
+
+	if _, err := NewCipher([]byte("short")); err == nil {
+		t.Error("expected a short key to be refused")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(codes) != RecoveryCodes {
+		t.Fatalf("expected %d codes, got %d", RecoveryCodes, len(codes))
+	}
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("unexpected code %q", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+
+	if got := NormalizeRecoveryCode(" ABCDE-fghjk "); got != "abcdefghjk" {
+		t.Errorf("NormalizeRecoveryCode() = %q", got)
+	}
+}